@@ -1,16 +1,18 @@
 package dbtcloud
 
 import (
+	"cmp"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"sort"
 	"strings"
-	"time"
 
 	"github.com/samber/lo"
 	"github.com/sirupsen/logrus"
-	"golang.org/x/time/rate"
 )
 
 var versionString = "dev"
@@ -53,42 +55,27 @@ type DbtCloudHTTPClient struct {
 	HostURL   string
 	APIToken  string
 	AccountID string
-}
-
-type RateLimitedTransport struct {
-	*http.Transport
-	limiter *rate.Limiter
+	// Concurrency is the number of requests sent at the same time by the
+	// methods looping through projects, jobs or connections
+	Concurrency int
+	// Cache keeps the responses of the API for the lifetime of the client,
+	// nil disables caching
+	Cache *ResponseCache
 }
 
 var log = logrus.New()
 
-// RoundTrip overrides the http.RoundTrip to implement rate limiting.
-func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	// Wait for permission from the rate limiter
-	t.limiter.Wait(req.Context())
-
-	// Proceed with the request
-	return t.Transport.RoundTrip(req)
-}
-
 func NewDbtCloudHTTPClient(hostURL, apiToken, accountID string, transport http.RoundTripper) *DbtCloudHTTPClient {
 	if transport == nil {
-
-		limiter := rate.NewLimiter(rate.Every(time.Minute), 3000)
-
-		// Create a custom transport which is a modified clone of DefaultTransport
-		// DefaultTransport handles https_proxy env var that we need to capture HTTP calls
-		defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
-		transport = &RateLimitedTransport{
-			Transport: defaultTransport,
-			limiter:   limiter,
-		}
+		transport = NewRateLimitedTransport(DefaultRequestsPerMinute, DefaultMaxRetries)
 	}
 	return &DbtCloudHTTPClient{
-		Client:    &http.Client{Transport: transport},
-		HostURL:   hostURL,
-		APIToken:  apiToken,
-		AccountID: accountID,
+		Client:      &http.Client{Transport: transport},
+		HostURL:     hostURL,
+		APIToken:    apiToken,
+		AccountID:   accountID,
+		Concurrency: 1,
+		Cache:       NewResponseCache(),
 	}
 }
 
@@ -106,15 +93,26 @@ func (c *DbtCloudHTTPClient) Do(req *http.Request) (*http.Response, error) {
 	return c.Client.Do(req)
 }
 
-func (c *DbtCloudHTTPClient) GetEndpoint(url string) ([]byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
+// GetEndpoint returns the body of the response of url, from the cache if the
+// same URL has already been requested.
+func (c *DbtCloudHTTPClient) GetEndpoint(ctx context.Context, url string) ([]byte, error) {
+	if c.Cache == nil {
+		return c.fetchEndpoint(ctx, url)
+	}
+	return c.Cache.get(url, func() ([]byte, error) {
+		return c.fetchEndpoint(ctx, url)
+	})
+}
+
+func (c *DbtCloudHTTPClient) fetchEndpoint(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating a new request: %v", err)
 	}
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error fetching URL %v: %v", url, err)
+		return nil, fmt.Errorf("error fetching URL %v: %w", url, err)
 	}
 	// Ensure the response body is closed at the end.
 	defer resp.Body.Close()
@@ -133,9 +131,9 @@ func (c *DbtCloudHTTPClient) GetEndpoint(url string) ([]byte, error) {
 	return jsonPayload, nil
 }
 
-func (c *DbtCloudHTTPClient) GetSingleData(url string) (any, error) {
+func (c *DbtCloudHTTPClient) GetSingleData(ctx context.Context, url string) (any, error) {
 
-	jsonPayload, err := c.GetEndpoint(url)
+	jsonPayload, err := c.GetEndpoint(ctx, url)
 	if err != nil {
 		return nil, err
 	}
@@ -143,25 +141,25 @@ func (c *DbtCloudHTTPClient) GetSingleData(url string) (any, error) {
 
 	err = json.Unmarshal(jsonPayload, &response)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error parsing the response of %v: %v", url, err)
 	}
 
 	return response.Data, nil
 }
 
-func (c *DbtCloudHTTPClient) GetData(url string) []any {
+func (c *DbtCloudHTTPClient) GetData(ctx context.Context, url string) ([]any, error) {
 
 	// get the first page
-	jsonPayload, err := c.GetEndpoint(url)
+	jsonPayload, err := c.GetEndpoint(ctx, url)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	var response Response
 
 	err = json.Unmarshal(jsonPayload, &response)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error parsing the response of %v: %v", url, err)
 	}
 
 	allResponses := response.Data
@@ -178,15 +176,15 @@ func (c *DbtCloudHTTPClient) GetData(url string) []any {
 			newURL = fmt.Sprintf("%s?offset=%d", url, count)
 		}
 
-		jsonPayload, err := c.GetEndpoint(newURL)
+		jsonPayload, err := c.GetEndpoint(ctx, newURL)
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		var response Response
 
 		err = json.Unmarshal(jsonPayload, &response)
 		if err != nil {
-			log.Fatal(err)
+			return nil, fmt.Errorf("error parsing the response of %v: %v", newURL, err)
 		}
 
 		if response.Extra.Pagination.Count == 0 {
@@ -199,341 +197,483 @@ func (c *DbtCloudHTTPClient) GetData(url string) []any {
 		allResponses = append(allResponses, response.Data...)
 	}
 
-	return allResponses
+	return allResponses, nil
 }
 
-func (c *DbtCloudHTTPClient) GetDataEnvVars(url string) map[string]any {
+func (c *DbtCloudHTTPClient) GetDataEnvVars(ctx context.Context, url string) (map[string]any, error) {
 
-	jsonPayload, err := c.GetEndpoint(url)
+	jsonPayload, err := c.GetEndpoint(ctx, url)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	var response EnvVarResponse
 
 	err = json.Unmarshal(jsonPayload, &response)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error parsing the response of %v: %v", url, err)
 	}
 
-	return response.Data.Variables
+	return response.Data.Variables, nil
 }
 
-func (c *DbtCloudHTTPClient) GetProjects(listProjects []int) []any {
+func (c *DbtCloudHTTPClient) GetProjects(ctx context.Context, listProjects []int) ([]Project, error) {
 	url := fmt.Sprintf("%s/v2/accounts/%s/projects/", c.HostURL, c.AccountID)
-	allProjects := c.GetData(url)
-
-	if len(listProjects) == 0 {
-		return allProjects
-	}
-
-	filteredProjects := []any{}
-
-	for _, data := range allProjects {
-		dataTyped := data.(map[string]any)
-		projectID := dataTyped["id"].(float64)
-
-		if len(listProjects) > 0 && !lo.Contains(listProjects, int(projectID)) {
-			continue
-		}
-		filteredProjects = append(filteredProjects, data)
+	allProjects, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
 	}
 
-	return filteredProjects
+	projects := decodeList[Project]("project", allProjects)
+	return filterByProject(projects, func(project Project) int { return project.ID }, listProjects), nil
 }
 
-func (c *DbtCloudHTTPClient) GetJobs(listProjects []int) []any {
+func (c *DbtCloudHTTPClient) GetJobs(ctx context.Context, listProjects []int) ([]Job, error) {
 	url := fmt.Sprintf("%s/v2/accounts/%s/jobs/", c.HostURL, c.AccountID)
-	allJobs := c.GetData(url)
-	filteredJobs := filterByProject(allJobs, listProjects)
+	allJobs, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return filteredJobs
+	jobs := decodeList[Job]("job", allJobs)
+	return filterByProject(jobs, func(job Job) int { return job.ProjectID }, listProjects), nil
 }
 
-func filterByProject(allData []any, listProjects []int) []any {
+// filterByProject returns the items whose project, as returned by projectID,
+// is in listProjects.
+func filterByProject[T any](allData []T, projectID func(T) int, listProjects []int) []T {
 
 	// if there is no filter provided we return the data as is
 	if len(listProjects) == 0 {
 		return allData
 	}
 
-	filteredData := []any{}
-	for _, data := range allData {
-		dataTyped := data.(map[string]any)
-		projectID := dataTyped["project_id"].(float64)
-
-		if !lo.Contains(listProjects, int(projectID)) {
-			continue
-		}
-		filteredData = append(filteredData, data)
-	}
-	return filteredData
+	return lo.Filter(allData, func(data T, _ int) bool {
+		return lo.Contains(listProjects, projectID(data))
+	})
 }
 
-func (c *DbtCloudHTTPClient) GetEnvironments(listProjects []int) []any {
+func (c *DbtCloudHTTPClient) GetEnvironments(ctx context.Context, listProjects []int) ([]Environment, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/environments/", c.HostURL, c.AccountID)
-	allEnvironments := c.GetData(url)
-	filteredEnvironments := filterByProject(allEnvironments, listProjects)
+	allEnvironments, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return filteredEnvironments
+	environments := decodeList[Environment]("environment", allEnvironments)
+	return filterByProject(environments, func(environment Environment) int { return environment.ProjectID }, listProjects), nil
 }
 
-func (c *DbtCloudHTTPClient) GetRepositories(listProjects []int) []any {
+func (c *DbtCloudHTTPClient) GetRepositories(ctx context.Context, listProjects []int) ([]Repository, error) {
 	url := fmt.Sprintf("%s/v2/accounts/%s/repositories/", c.HostURL, c.AccountID)
-	allRepos := c.GetData(url)
-	filteredRepos := filterByProject(allRepos, listProjects)
+	allRepos, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return filteredRepos
+	repositories := decodeList[Repository]("repository", allRepos)
+	return filterByProject(repositories, func(repository Repository) int { return repository.ProjectID }, listProjects), nil
 }
 
-func (c *DbtCloudHTTPClient) GetGroups() []any {
+func (c *DbtCloudHTTPClient) GetGroups(ctx context.Context) ([]Group, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/groups/", c.HostURL, c.AccountID)
+	allGroups, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.GetData(url)
+	return decodeList[Group]("group", allGroups), nil
 }
 
-func (c *DbtCloudHTTPClient) GetEnvironmentVariables(listProjects []int) map[int]any {
-
-	allEnvVars := map[int]any{}
+// GetEnvironmentVariables returns the environment variables of each project,
+// keyed by project ID.
+//
+// Like every other method looping through projects, an error for a single
+// project doesn't stop the others: the variables of the projects that could be
+// fetched are returned along with the errors of the ones that couldn't.
+func (c *DbtCloudHTTPClient) GetEnvironmentVariables(ctx context.Context, listProjects []int) (map[int][]EnvironmentVariable, error) {
 
-	projects := c.GetProjects(listProjects)
-	for _, project := range projects {
-		projectTyped := project.(map[string]any)
-		projectID := int(projectTyped["id"].(float64))
+	projects, err := c.GetProjects(ctx, listProjects)
+	if err != nil {
+		return nil, err
+	}
 
-		if len(listProjects) > 0 && !lo.Contains(listProjects, projectID) {
-			continue
+	filteredProjects := sortedProjects(projects)
+	projectsEnvVars := make([][]EnvironmentVariable, len(filteredProjects))
+	err = c.forEach(len(filteredProjects), func(i int) error {
+		projectID := filteredProjects[i].ID
+		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/environment-variables/environment/", c.HostURL, c.AccountID, projectID)
+		projectEnvVars, err := c.GetDataEnvVars(ctx, url)
+		if err != nil {
+			return fmt.Errorf("project %d: %w", projectID, err)
 		}
+		projectsEnvVars[i] = decodeEnvironmentVariables(projectEnvVars)
+		return nil
+	})
 
-		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/environment-variables/environment/", c.HostURL, c.AccountID, projectID)
-		allEnvVars[projectID] = c.GetDataEnvVars(url)
+	allEnvVars := map[int][]EnvironmentVariable{}
+	for i, project := range filteredProjects {
+		if projectsEnvVars[i] != nil {
+			allEnvVars[project.ID] = projectsEnvVars[i]
+		}
 	}
-	return allEnvVars
+	return allEnvVars, err
 }
 
-func (c *DbtCloudHTTPClient) GetConnections(listProjects []int, warehouses []string) []any {
-
-	projects := c.GetProjects(listProjects)
-	connections := []any{}
-
-	// we loop through all the projects to only get the active connections
-	// there are dangling connections in dbt Cloud with state=1 that we don't want to import
-	for _, project := range projects {
-		projectTyped := project.(map[string]any)
-		projectID := int(projectTyped["id"].(float64))
+// decodeEnvironmentVariables converts the variables returned by the
+// environment variables endpoint, sorted by name. Like for the other models,
+// the variables that can't be decoded are skipped with a warning.
+func decodeEnvironmentVariables(variables map[string]any) []EnvironmentVariable {
+	envVarNames := lo.Keys(variables)
+	sort.Strings(envVarNames)
 
-		if len(listProjects) > 0 && !lo.Contains(listProjects, projectID) {
+	envVars := make([]EnvironmentVariable, 0, len(envVarNames))
+	for _, envVarName := range envVarNames {
+		values := map[string]*EnvironmentVariableValue{}
+		data, err := json.Marshal(variables[envVarName])
+		if err == nil {
+			err = json.Unmarshal(data, &values)
+		}
+		if err == nil && values == nil {
+			err = fmt.Errorf("expected an object, got null")
+		}
+		if err != nil {
+			log.Warnf("skipping the environment variable %s, its payload doesn't have the expected format: %v", envVarName, err)
 			continue
 		}
+		envVars = append(envVars, EnvironmentVariable{Name: envVarName, Values: values})
+	}
+	return envVars
+}
 
+func (c *DbtCloudHTTPClient) GetConnections(ctx context.Context, listProjects []int, warehouses []string) ([]Connection, error) {
+
+	projects, err := c.GetProjects(ctx, listProjects)
+	if err != nil {
+		return nil, err
+	}
+	// we loop through all the projects to only get the active connections
+	// there are dangling connections in dbt Cloud with state=1 that we don't want to import
+	connectionURLs := []string{}
+	connectionProjectIDs := []int{}
+	for _, project := range sortedProjects(projects) {
 		// we might have a project partially configured that we want to avoid
-		if projectTyped["connection"] == nil {
+		if project.Connection == nil {
 			continue
 		}
 
-		projectConnectionTyped := projectTyped["connection"].(map[string]any)
-		connectionType := projectConnectionTyped["type"].(string)
-		if connectionType == "adapter" {
-			// this is very ugly but we need to traverse down...
-			detailsTyped := projectConnectionTyped["details"].(map[string]any)
-			connectionDetailsTyped := detailsTyped["connection_details"].(map[string]any)
-			fieldsTyped := connectionDetailsTyped["fields"].(map[string]any)
-			typeTyped := fieldsTyped["type"].(map[string]any)
-			connectionType = fmt.Sprintf("adapter/%s", typeTyped["value"].(string))
-		}
-		if !lo.Contains(warehouses, connectionType) {
+		if !lo.Contains(warehouses, project.Connection.WarehouseType()) {
 			continue
 		}
 
-		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/connections/%0.f/", c.HostURL, c.AccountID, projectID, projectConnectionTyped["id"].(float64))
-		connection, err := c.GetSingleData(url)
+		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/connections/%d/", c.HostURL, c.AccountID, project.ID, project.Connection.ID)
+		connectionURLs = append(connectionURLs, url)
+		connectionProjectIDs = append(connectionProjectIDs, project.ID)
+	}
+
+	projectConnections := make([]*Connection, len(connectionURLs))
+	err = c.forEach(len(connectionURLs), func(i int) error {
+		data, err := c.GetSingleData(ctx, connectionURLs[i])
 		if err != nil {
-			log.Warn(err)
-			continue
+			return fmt.Errorf("project %d: %w", connectionProjectIDs[i], err)
 		}
+		connection, err := Decode[Connection](data)
+		if err != nil {
+			return fmt.Errorf("project %d: error parsing the connection: %w", connectionProjectIDs[i], err)
+		}
+		projectConnections[i] = &connection
+		return nil
+	})
 
-		connections = append(connections, connection)
-	}
+	return derefNonNil(projectConnections), err
+}
+
+func (c *DbtCloudHTTPClient) GetGenericConnections(ctx context.Context, listProjects []int) ([]Connection, error) {
+	return c.GetConnections(ctx, listProjects, []string{"snowflake", "postgres", "redshift", "adapter/spark", "adapter/databricks"})
+}
 
-	return connections
+func (c *DbtCloudHTTPClient) GetBigQueryConnections(ctx context.Context, listProjects []int) ([]Connection, error) {
+	return c.GetConnections(ctx, listProjects, []string{"bigquery"})
 }
 
-func (c *DbtCloudHTTPClient) GetGenericConnections(listProjects []int) []any {
-	return c.GetConnections(listProjects, []string{"snowflake", "postgres", "redshift", "adapter/spark", "adapter/databricks"})
+func (c *DbtCloudHTTPClient) GetFabricConnections(ctx context.Context, listProjects []int) ([]Connection, error) {
+	return c.GetConnections(ctx, listProjects, []string{"adapter/fabric"})
 }
 
-func (c *DbtCloudHTTPClient) GetBigQueryConnections(listProjects []int) []any {
-	return c.GetConnections(listProjects, []string{"bigquery"})
+func (c *DbtCloudHTTPClient) GetSnowflakeCredentials(ctx context.Context, listProjects []int) ([]Credential, error) {
+	return c.GetWarehouseCredentials(ctx, listProjects, "snowflake")
 }
 
-func (c *DbtCloudHTTPClient) GetFabricConnections(listProjects []int) []any {
-	return c.GetConnections(listProjects, []string{"adapter/fabric"})
+func (c *DbtCloudHTTPClient) GetDatabricksCredentials(ctx context.Context, listProjects []int) ([]Credential, error) {
+	return c.GetWarehouseCredentials(ctx, listProjects, "databricks")
 }
 
-func (c *DbtCloudHTTPClient) GetSnowflakeCredentials(listProjects []int) []any {
-	return c.GetWarehouseCredentials(listProjects, "snowflake")
+func (c *DbtCloudHTTPClient) GetBigQueryCredentials(ctx context.Context, listProjects []int) ([]Credential, error) {
+	return c.GetWarehouseCredentials(ctx, listProjects, "bigquery")
 }
 
-func (c *DbtCloudHTTPClient) GetDatabricksCredentials(listProjects []int) []any {
-	return c.GetWarehouseCredentials(listProjects, "databricks")
+func (c *DbtCloudHTTPClient) GetWarehouseCredentials(ctx context.Context, listProjects []int, warehouse string) ([]Credential, error) {
+	// the errors are for single projects, we still filter what we got
+	listCredentials, err := c.GetCredentials(ctx, listProjects)
+	return filterWarehouseCredentials(listCredentials, warehouse), err
 }
 
-func (c *DbtCloudHTTPClient) GetBigQueryCredentials(listProjects []int) []any {
-	return c.GetWarehouseCredentials(listProjects, "bigquery")
+// GetAllWarehouseCredentials returns all the credentials of the projects for
+// warehouse, including the ones no environment uses.
+func (c *DbtCloudHTTPClient) GetAllWarehouseCredentials(ctx context.Context, listProjects []int, warehouse string) ([]Credential, error) {
+	listCredentials, err := c.GetAllCredentials(ctx, listProjects)
+	return filterWarehouseCredentials(listCredentials, warehouse), err
 }
 
-func (c *DbtCloudHTTPClient) GetWarehouseCredentials(listProjects []int, warehouse string) []any {
-	listCredentials := c.GetCredentials(listProjects)
-	warehouseCredentials := []any{}
+// filterWarehouseCredentials returns the credentials for warehouse, without
+// the duplicates.
+func filterWarehouseCredentials(listCredentials []Credential, warehouse string) []Credential {
+	warehouseCredentials := []Credential{}
 
 	listCredentialIDs := []int{}
 
 	for _, credential := range listCredentials {
-		credentialTyped := credential.(map[string]any)
-
 		// we only import the relevant ones
-		if credentialTyped["type"] != "adapter" && credentialTyped["type"] != warehouse {
+		if credential.Type != "adapter" && credential.Type != warehouse {
 			continue
 		}
 
-		if credentialTyped["type"] == "adapter" && credentialTyped["adapter_version"] != fmt.Sprintf("%s_v0", warehouse) {
+		if credential.Type == "adapter" && credential.AdapterVersion != fmt.Sprintf("%s_v0", warehouse) {
 			continue
 		}
 
 		// the API has some issues with offsets and we can get duplicates
-		if lo.Contains(listCredentialIDs, int(credentialTyped["id"].(float64))) {
+		if lo.Contains(listCredentialIDs, credential.ID) {
 			continue
 		}
 
 		warehouseCredentials = append(warehouseCredentials, credential)
-		listCredentialIDs = append(listCredentialIDs, int(credentialTyped["id"].(float64)))
+		listCredentialIDs = append(listCredentialIDs, credential.ID)
 	}
 
 	return warehouseCredentials
 }
 
-func (c *DbtCloudHTTPClient) GetCredentials(listProjects []int) []any {
-	allCredentials := []any{}
-	for _, projectID := range listProjects {
-		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/credentials/", c.HostURL, c.AccountID, projectID)
-		projectCredentials := c.GetData(url)
-		allCredentials = append(allCredentials, projectCredentials...)
+// GetCredentials returns the credentials used by the environments of the
+// projects, with the ID of the environment using them.
+func (c *DbtCloudHTTPClient) GetCredentials(ctx context.Context, listProjects []int) ([]Credential, error) {
+	// the errors are for single projects, we still filter what we got
+	allCredentials, credentialsErr := c.GetAllCredentials(ctx, listProjects)
+	if allCredentials == nil {
+		return nil, credentialsErr
 	}
 
 	// we need to keep only the credentials for active environments
-	allEnvironments := c.GetEnvironments(listProjects)
-
-	credentialToEnvironmentID := map[float64]float64{}
-	lo.ForEach(allEnvironments, func(env any, index int) {
-		envTyped := env.(map[string]any)
-		credentialID, ok := envTyped["credentials_id"].(float64)
-		if ok {
-			environmentID := envTyped["id"].(float64)
-			credentialToEnvironmentID[credentialID] = environmentID
+	allEnvironments, err := c.GetEnvironments(ctx, listProjects)
+	if err != nil {
+		return nil, err
+	}
+
+	credentialToEnvironmentID := map[int]int{}
+	for _, environment := range allEnvironments {
+		if environment.CredentialsID != nil {
+			credentialToEnvironmentID[*environment.CredentialsID] = environment.ID
 		}
-	})
+	}
 
-	filteredCredentials := []any{}
+	filteredCredentials := []Credential{}
 	for _, credential := range allCredentials {
-		dataTyped := credential.(map[string]any)
-		credentialsID := dataTyped["id"].(float64)
-		if _, ok := credentialToEnvironmentID[credentialsID]; !ok {
+		environmentID, ok := credentialToEnvironmentID[credential.ID]
+		if !ok {
 			continue
 		}
-		credential.(map[string]any)["environment_id"] = credentialToEnvironmentID[credentialsID]
+		credential.EnvironmentID = environmentID
+		credential.Raw["environment_id"] = environmentID
 		filteredCredentials = append(filteredCredentials, credential)
 	}
-	return filteredCredentials
+	return filteredCredentials, credentialsErr
 }
 
-func (c *DbtCloudHTTPClient) GetExtendedAttributes(listProjects []int) []any {
+// GetAllCredentials returns all the credentials of the projects, including
+// the ones no environment uses. The credentials of the projects whose call
+// fails are missing and the error lists those projects.
+func (c *DbtCloudHTTPClient) GetAllCredentials(ctx context.Context, listProjects []int) ([]Credential, error) {
+	projects, err := c.GetProjects(ctx, listProjects)
+	if err != nil {
+		return nil, err
+	}
 
-	allExtendedAttributes := []any{}
-	envs := c.GetEnvironments(listProjects)
-	for _, env := range envs {
-		envTyped := env.(map[string]any)
-		extendedAttributesID, err := envTyped["extended_attributes_id"].(float64)
-		if !err {
-			continue
+	filteredProjects := sortedProjects(projects)
+	projectsCredentials := make([][]Credential, len(filteredProjects))
+	err = c.forEach(len(filteredProjects), func(i int) error {
+		projectID := filteredProjects[i].ID
+		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/credentials/", c.HostURL, c.AccountID, projectID)
+		projectCredentials, err := c.GetData(ctx, url)
+		if err != nil {
+			return fmt.Errorf("project %d: %w", projectID, err)
 		}
-		projectID := envTyped["project_id"].(float64)
-		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%0.f/extended-attributes/%0.f/", c.HostURL, c.AccountID, projectID, extendedAttributesID)
-		extendedAttributes, _ := c.GetSingleData(url)
-		allExtendedAttributes = append(allExtendedAttributes, extendedAttributes)
+		projectsCredentials[i] = decodeList[Credential]("credential", projectCredentials)
+		return nil
+	})
+	return lo.Flatten(projectsCredentials), err
+}
+
+func (c *DbtCloudHTTPClient) GetExtendedAttributes(ctx context.Context, listProjects []int) ([]ExtendedAttributes, error) {
+
+	envs, err := c.GetEnvironments(ctx, listProjects)
+	if err != nil {
+		return nil, err
 	}
-	return allExtendedAttributes
+
+	envsWithExtendedAttributes := lo.Filter(envs, func(env Environment, _ int) bool {
+		return env.ExtendedAttributesID != nil
+	})
+
+	allExtendedAttributes := make([]*ExtendedAttributes, len(envsWithExtendedAttributes))
+	err = c.forEach(len(envsWithExtendedAttributes), func(i int) error {
+		env := envsWithExtendedAttributes[i]
+		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/extended-attributes/%d/", c.HostURL, c.AccountID, env.ProjectID, *env.ExtendedAttributesID)
+		data, err := c.GetSingleData(ctx, url)
+		if err != nil {
+			return fmt.Errorf("project %d: %w", env.ProjectID, err)
+		}
+		extendedAttributes, err := Decode[ExtendedAttributes](data)
+		if err != nil {
+			return fmt.Errorf("project %d: error parsing the extended attributes %d: %w", env.ProjectID, *env.ExtendedAttributesID, err)
+		}
+		allExtendedAttributes[i] = &extendedAttributes
+		return nil
+	})
+
+	return derefNonNil(allExtendedAttributes), err
 }
 
-func (c *DbtCloudHTTPClient) GetUsers() []any {
+func (c *DbtCloudHTTPClient) GetUsers(ctx context.Context) ([]User, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/users/", c.HostURL, c.AccountID)
+	allUsers, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.GetData(url)
+	return decodeList[User]("user", allUsers), nil
 }
 
-func (c *DbtCloudHTTPClient) GetWebhooks() []any {
+func (c *DbtCloudHTTPClient) GetWebhooks(ctx context.Context) ([]Webhook, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/webhooks/subscriptions", c.HostURL, c.AccountID)
+	allWebhooks, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.GetData(url)
+	return decodeList[Webhook]("webhook", allWebhooks), nil
 }
 
-func (c *DbtCloudHTTPClient) GetNotifications() []any {
+func (c *DbtCloudHTTPClient) GetNotifications(ctx context.Context) ([]Notification, error) {
 	url := fmt.Sprintf("%s/v2/accounts/%s/notifications/", c.HostURL, c.AccountID)
+	allNotifications, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.GetData(url)
+	return decodeList[Notification]("notification", allNotifications), nil
 }
 
-func (c *DbtCloudHTTPClient) GetServiceTokens() []any {
+func (c *DbtCloudHTTPClient) GetServiceTokens(ctx context.Context) ([]ServiceToken, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/service-tokens/", c.HostURL, c.AccountID)
 
 	// the API returns the deactivated ones as well :-(
-	allServiceTokens := c.GetData(url)
+	allServiceTokens, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	activeServiceTokens := lo.Filter(allServiceTokens, func(serviceToken any, idx int) bool {
-		serviceTokenTyped := serviceToken.(map[string]any)
-		return serviceTokenTyped["state"].(float64) == 1
+	activeServiceTokens := lo.Filter(decodeList[ServiceToken]("service token", allServiceTokens), func(serviceToken ServiceToken, idx int) bool {
+		return serviceToken.State == 1
 	})
-	return activeServiceTokens
+	return activeServiceTokens, nil
 }
 
-func (c *DbtCloudHTTPClient) GetServiceTokenPermissions(serviceTokenID int) []any {
+func (c *DbtCloudHTTPClient) GetServiceTokenPermissions(ctx context.Context, serviceTokenID int) ([]Permission, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/service-tokens/%d/permissions/", c.HostURL, c.AccountID, serviceTokenID)
+	allPermissions, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.GetData(url)
+	return decodeList[Permission]("service token permission", allPermissions), nil
 }
 
-func (c *DbtCloudHTTPClient) GetGlobalConnection(id int64) (any, error) {
+// GetServiceTokensPermissions returns the permissions of each of the service
+// tokens, in the same order as serviceTokenIDs. The permissions of the tokens
+// that couldn't be fetched are nil.
+func (c *DbtCloudHTTPClient) GetServiceTokensPermissions(ctx context.Context, serviceTokenIDs []int) ([][]Permission, error) {
+	allPermissions := make([][]Permission, len(serviceTokenIDs))
+	err := c.forEach(len(serviceTokenIDs), func(i int) error {
+		permissions, err := c.GetServiceTokenPermissions(ctx, serviceTokenIDs[i])
+		if err != nil {
+			return fmt.Errorf("service token %d: %w", serviceTokenIDs[i], err)
+		}
+		allPermissions[i] = permissions
+		return nil
+	})
+
+	return allPermissions, err
+}
+
+func (c *DbtCloudHTTPClient) GetGlobalConnection(ctx context.Context, id int) (GlobalConnection, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/connections/%d/", c.HostURL, c.AccountID, id)
 
-	return c.GetSingleData(url)
+	data, err := c.GetSingleData(ctx, url)
+	if err != nil {
+		return GlobalConnection{}, err
+	}
+	connection, err := Decode[GlobalConnection](data)
+	if err != nil {
+		return GlobalConnection{}, fmt.Errorf("error parsing the response of %v: %w", url, err)
+	}
+	return connection, nil
 }
 
-func (c *DbtCloudHTTPClient) GetCredential(projectId, id int64) (any, error) {
+func (c *DbtCloudHTTPClient) GetCredential(ctx context.Context, projectId, id int) (Credential, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/credentials/%d/", c.HostURL, c.AccountID, projectId, id)
 
-	return c.GetSingleData(url)
+	data, err := c.GetSingleData(ctx, url)
+	if err != nil {
+		return Credential{}, err
+	}
+	credential, err := Decode[Credential](data)
+	if err != nil {
+		return Credential{}, fmt.Errorf("error parsing the response of %v: %w", url, err)
+	}
+	return credential, nil
 }
 
-func (c *DbtCloudHTTPClient) GetGlobalConnectionsSummary() []any {
+func (c *DbtCloudHTTPClient) GetGlobalConnectionsSummary(ctx context.Context) ([]GlobalConnection, error) {
 	url := fmt.Sprintf("%s/v3/accounts/%s/connections/", c.HostURL, c.AccountID)
+	allConnections, err := c.GetData(ctx, url)
+	if err != nil {
+		return nil, err
+	}
 
-	return c.GetData(url)
+	return decodeList[GlobalConnection]("global connection", allConnections), nil
 }
 
-func (c *DbtCloudHTTPClient) GetGlobalConnections() []any {
+func (c *DbtCloudHTTPClient) GetGlobalConnections(ctx context.Context) ([]GlobalConnection, error) {
 
 	// this return just a summary though...
 	// so we need to loop through the results to get the details
-	allConnectionsSummary := c.GetGlobalConnectionsSummary()
-	allConnectionDetails := []any{}
-
-	for _, connectionSummary := range allConnectionsSummary {
-		connectionSummaryTyped := connectionSummary.(map[string]any)
-		connectionID := int(connectionSummaryTyped["id"].(float64))
-		connectionDetails, _ := c.GetGlobalConnection(int64(connectionID))
-		allConnectionDetails = append(allConnectionDetails, connectionDetails)
+	allConnectionsSummary, err := c.GetGlobalConnectionsSummary(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return allConnectionDetails
+	allConnectionDetails := make([]*GlobalConnection, len(allConnectionsSummary))
+	err = c.forEach(len(allConnectionsSummary), func(i int) error {
+		connectionID := allConnectionsSummary[i].ID
+		connectionDetails, err := c.GetGlobalConnection(ctx, connectionID)
+		if err != nil {
+			return fmt.Errorf("connection %d: %w", connectionID, err)
+		}
+		allConnectionDetails[i] = &connectionDetails
+		return nil
+	})
+
+	return derefNonNil(allConnectionDetails), err
 }
 
 // EnvVarJobOverrideResponse mirrors the response envelope returned by the
@@ -547,21 +687,21 @@ type EnvVarJobOverrideResponse struct {
 	Data map[string]any `json:"data"`
 }
 
-func (c *DbtCloudHTTPClient) GetDataEnvVarJobOverrides(url string) map[string]any {
+func (c *DbtCloudHTTPClient) GetDataEnvVarJobOverrides(ctx context.Context, url string) (map[string]any, error) {
 
-	jsonPayload, err := c.GetEndpoint(url)
+	jsonPayload, err := c.GetEndpoint(ctx, url)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	var response EnvVarJobOverrideResponse
 
 	err = json.Unmarshal(jsonPayload, &response)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error parsing the response of %v: %v", url, err)
 	}
 
-	return response.Data
+	return response.Data, nil
 }
 
 // GetEnvironmentVariableJobOverrides fetches per-job environment-variable
@@ -576,69 +716,66 @@ func (c *DbtCloudHTTPClient) GetDataEnvVarJobOverrides(url string) map[string]an
 // each project and query per job, mirroring the per-project-then-per-item
 // fetch pattern GetProfiles already uses above.
 //
-// Each returned item is a flattened map with "name" (the env var name),
+// Each returned override keeps, in Raw, "name" (the env var name),
 // "project_id", "job_definition_id", "environment_variable_job_override_id"
 // (the override's own numeric id - present so callers can fold a
 // project/job/override composite into a unique resource id, exactly as
 // GetProfiles's callers do for profile_id), and "raw_value" (the override's
 // value, matching the dbtcloud_environment_variable_job_override resource's
 // own attribute name).
-func (c *DbtCloudHTTPClient) GetEnvironmentVariableJobOverrides(listProjects []int, jobs []any) []any {
-	allOverrides := []any{}
+func (c *DbtCloudHTTPClient) GetEnvironmentVariableJobOverrides(ctx context.Context, listProjects []int, jobs []Job) ([]EnvironmentVariableJobOverride, error) {
 
-	jobIDsByProject := map[int][]int{}
-	for _, job := range jobs {
-		jobTyped := job.(map[string]any)
-		jobID := int(jobTyped["id"].(float64))
-		projectID := int(jobTyped["project_id"].(float64))
-		jobIDsByProject[projectID] = append(jobIDsByProject[projectID], jobID)
-	}
+	// the jobs are sorted by project and then by ID so that the overrides are
+	// always returned in the same order
+	projectJobs := filterByProject(slices.Clone(jobs), func(job Job) int { return job.ProjectID }, listProjects)
+	slices.SortFunc(projectJobs, func(a, b Job) int {
+		return cmp.Or(cmp.Compare(a.ProjectID, b.ProjectID), cmp.Compare(a.ID, b.ID))
+	})
 
-	for projectID, jobIDs := range jobIDsByProject {
-		if len(listProjects) > 0 && !lo.Contains(listProjects, projectID) {
-			continue
+	jobsOverrides := make([][]EnvironmentVariableJobOverride, len(projectJobs))
+	err := c.forEach(len(projectJobs), func(i int) error {
+		projectID, jobID := projectJobs[i].ProjectID, projectJobs[i].ID
+		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/environment-variables/job/?job_definition_id=%d", c.HostURL, c.AccountID, projectID, jobID)
+		jobOverrides, err := c.GetDataEnvVarJobOverrides(ctx, url)
+		if err != nil {
+			return fmt.Errorf("job %d: %w", jobID, err)
 		}
 
-		for _, jobID := range jobIDs {
-			url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/environment-variables/job/?job_definition_id=%d", c.HostURL, c.AccountID, projectID, jobID)
-			jobOverrides := c.GetDataEnvVarJobOverrides(url)
-
-			for envVarName, value := range jobOverrides {
-				// "project" is a pseudo-key returned alongside the per-variable
-				// entries on the environment-scoped endpoint (see
-				// GetEnvironmentVariables); we haven't observed it here but skip
-				// it defensively for the same reason.
-				if envVarName == "project" {
-					continue
-				}
-
-				valueTyped, ok := value.(map[string]any)
-				if !ok {
-					continue
-				}
-
-				jobOverrideTyped, ok := valueTyped["job"].(map[string]any)
-				if !ok || jobOverrideTyped == nil {
-					// this env var has no job-level override for this job - only
-					// account/environment-level values, which aren't this
-					// resource's concern.
-					continue
-				}
-
-				overrideValue, _ := jobOverrideTyped["value"].(string)
+		// "project" is a pseudo-key returned alongside the per-variable
+		// entries on the environment-scoped endpoint (see
+		// GetEnvironmentVariables); we haven't observed it here but skip it
+		// defensively for the same reason.
+		delete(jobOverrides, "project")
+
+		for _, envVar := range decodeEnvironmentVariables(jobOverrides) {
+			jobOverride := envVar.Values["job"]
+			if jobOverride == nil {
+				// this env var has no job-level override for this job - only
+				// account/environment-level values, which aren't this
+				// resource's concern.
+				continue
+			}
 
-				allOverrides = append(allOverrides, map[string]any{
-					"name":                                 envVarName,
-					"project_id":                           float64(projectID),
-					"job_definition_id":                    float64(jobID),
-					"environment_variable_job_override_id": jobOverrideTyped["id"],
-					"raw_value":                            overrideValue,
-				})
+			override := EnvironmentVariableJobOverride{
+				ID:              jobOverride.ID,
+				Name:            envVar.Name,
+				ProjectID:       projectID,
+				JobDefinitionID: jobID,
+				RawValue:        jobOverride.Value,
+			}
+			override.Raw = map[string]any{
+				"name":                                 override.Name,
+				"project_id":                           override.ProjectID,
+				"job_definition_id":                    override.JobDefinitionID,
+				"environment_variable_job_override_id": override.ID,
+				"raw_value":                            override.RawValue,
 			}
+			jobsOverrides[i] = append(jobsOverrides[i], override)
 		}
-	}
+		return nil
+	})
 
-	return allOverrides
+	return lo.Flatten(jobsOverrides), err
 }
 
 // accountFeaturesData mirrors the payload returned by the account features
@@ -669,17 +806,17 @@ type accountFeaturesResponse struct {
 // and the object has no numeric `id` of its own. We return a single-element
 // slice with `id` set to the account id so it fits the same []any shape the
 // rest of the generator/importer code already works with.
-func (c *DbtCloudHTTPClient) GetAccountFeatures() []any {
+func (c *DbtCloudHTTPClient) GetAccountFeatures(ctx context.Context) ([]any, error) {
 	url := fmt.Sprintf("%s/private/accounts/%s/features/", c.HostURL, c.AccountID)
 
-	jsonPayload, err := c.GetEndpoint(url)
+	jsonPayload, err := c.GetEndpoint(ctx, url)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	var response accountFeaturesResponse
 	if err := json.Unmarshal(jsonPayload, &response); err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("error parsing the response of %v: %v", url, err)
 	}
 
 	features := map[string]any{
@@ -693,7 +830,7 @@ func (c *DbtCloudHTTPClient) GetAccountFeatures() []any {
 		"fusion_migration_permissions": response.Data.FusionMigrationPermissions,
 	}
 
-	return []any{features}
+	return []any{features}, nil
 }
 
 // GetProfiles fetches the profiles (project-scoped bindings of a connection,
@@ -713,43 +850,47 @@ func (c *DbtCloudHTTPClient) GetAccountFeatures() []any {
 // directly here and attach the matching credential (under the "credentials"
 // key) to each profile, mirroring how the environments endpoint already
 // embeds a nested "credentials" object for the same purpose.
-func (c *DbtCloudHTTPClient) GetProfiles(listProjects []int) []any {
-	projects := c.GetProjects(listProjects)
-	allProfiles := []any{}
-
-	for _, project := range projects {
-		projectTyped := project.(map[string]any)
-		projectID := int(projectTyped["id"].(float64))
+func (c *DbtCloudHTTPClient) GetProfiles(ctx context.Context, listProjects []int) ([]Profile, error) {
+	projects, err := c.GetProjects(ctx, listProjects)
+	if err != nil {
+		return nil, err
+	}
 
-		if len(listProjects) > 0 && !lo.Contains(listProjects, projectID) {
-			continue
-		}
+	filteredProjects := sortedProjects(projects)
+	projectsProfiles := make([][]Profile, len(filteredProjects))
+	err = c.forEach(len(filteredProjects), func(i int) error {
+		projectID := filteredProjects[i].ID
 
 		url := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/profiles/", c.HostURL, c.AccountID, projectID)
-		projectProfiles := c.GetData(url)
+		projectProfiles, err := c.GetData(ctx, url)
+		if err != nil {
+			return fmt.Errorf("project %d: %w", projectID, err)
+		}
 
 		if len(projectProfiles) == 0 {
-			continue
+			return nil
 		}
 
 		credentialsURL := fmt.Sprintf("%s/v3/accounts/%s/projects/%d/credentials/", c.HostURL, c.AccountID, projectID)
-		projectCredentials := c.GetData(credentialsURL)
-		credentialByID := map[float64]map[string]any{}
-		for _, credential := range projectCredentials {
-			credentialTyped := credential.(map[string]any)
-			credentialByID[credentialTyped["id"].(float64)] = credentialTyped
+		projectCredentials, err := c.GetData(ctx, credentialsURL)
+		if err != nil {
+			return fmt.Errorf("project %d: %w", projectID, err)
 		}
-
-		for _, profile := range projectProfiles {
-			profileTyped := profile.(map[string]any)
-			if credentialsID, ok := profileTyped["credentials_id"].(float64); ok {
-				if credentialDetails, found := credentialByID[credentialsID]; found {
-					profileTyped["credentials"] = credentialDetails
+		credentialByID := lo.KeyBy(decodeList[Credential]("credential", projectCredentials), func(credential Credential) int {
+			return credential.ID
+		})
+
+		for _, profile := range decodeList[Profile]("profile", projectProfiles) {
+			if profile.CredentialsID != nil {
+				if credential, found := credentialByID[*profile.CredentialsID]; found {
+					profile.Credentials = &credential
+					profile.Raw["credentials"] = credential.Raw
 				}
 			}
-			allProfiles = append(allProfiles, profileTyped)
+			projectsProfiles[i] = append(projectsProfiles[i], profile)
 		}
-	}
+		return nil
+	})
 
-	return allProfiles
+	return lo.Flatten(projectsProfiles), err
 }
@@ -0,0 +1,152 @@
+package dbtcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGetCredentials_SkipsFailingProject checks that a 403 on the credentials
+// of one project returns an error for that project only, along with the
+// credentials of the other projects.
+func TestGetCredentials_SkipsFailingProject(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/accounts/1/projects/":
+			fmt.Fprint(w, `{"data": [{"id": 10}, {"id": 20}], "extra": {"pagination": {"count": 2, "total_count": 2}}}`)
+		case "/v3/accounts/1/projects/10/credentials/":
+			fmt.Fprint(w, `{"data": [{"id": 100, "project_id": 10}], "extra": {"pagination": {"count": 1, "total_count": 1}}}`)
+		case "/v3/accounts/1/projects/20/credentials/":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"status": {"user_message": "forbidden"}}`)
+		case "/v3/accounts/1/environments/":
+			fmt.Fprint(w, `{"data": [{"id": 1000, "project_id": 10, "credentials_id": 100}], "extra": {"pagination": {"count": 1, "total_count": 1}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+	credentials, err := client.GetCredentials(context.Background(), []int{10, 20})
+
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "project 20")
+	assert.ErrorContains(t, err, "403 Forbidden")
+	require.Len(t, credentials, 1)
+	assert.Equal(t, 100, credentials[0].ID)
+	assert.Equal(t, 1000, credentials[0].EnvironmentID)
+
+	// without a project filter, the credentials of all the projects are fetched
+	credentials, err = client.GetCredentials(context.Background(), nil)
+	assert.ErrorContains(t, err, "project 20")
+	assert.Len(t, credentials, 1)
+}
+
+// TestGetAllCredentials_KeepsUnusedCredentials checks that the credentials
+// no environment uses are returned by GetAllCredentials but not by
+// GetCredentials.
+func TestGetAllCredentials_KeepsUnusedCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v2/accounts/1/projects/":
+			fmt.Fprint(w, `{"data": [{"id": 10}], "extra": {"pagination": {"count": 1, "total_count": 1}}}`)
+		case "/v3/accounts/1/projects/10/credentials/":
+			fmt.Fprint(w, `{"data": [{"id": 100, "project_id": 10}, {"id": 101, "project_id": 10}], "extra": {"pagination": {"count": 2, "total_count": 2}}}`)
+		case "/v3/accounts/1/environments/":
+			fmt.Fprint(w, `{"data": [{"id": 1000, "project_id": 10, "credentials_id": 100}], "extra": {"pagination": {"count": 1, "total_count": 1}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+
+	credentials, err := client.GetAllCredentials(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{100, 101}, []int{credentials[0].ID, credentials[1].ID})
+
+	credentials, err = client.GetCredentials(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, credentials, 1)
+	assert.Equal(t, 100, credentials[0].ID)
+}
+
+// TestGetEnvironmentVariableJobOverrides_Concurrency fetches the overrides of
+// many jobs with a few workers and checks that no more requests than the
+// concurrency are in flight and that the result is sorted by project, job and
+// variable name whatever the order the requests finish in.
+func TestGetEnvironmentVariableJobOverrides_Concurrency(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			previous := maxInFlight.Load()
+			if current <= previous || maxInFlight.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+
+		jobID, _ := strconv.Atoi(r.URL.Query().Get("job_definition_id"))
+		// make the first jobs the slowest to answer
+		time.Sleep(time.Duration(20-jobID%20) * time.Millisecond)
+		fmt.Fprintf(w, `{"data": {
+			"B_VAR": {"job": {"id": %[1]d2, "value": "b"}},
+			"A_VAR": {"job": {"id": %[1]d1, "value": "a"}},
+			"NO_OVERRIDE": {"environment": {"id": 1, "value": "env"}, "job": null}
+		}}`, jobID)
+	}))
+	defer server.Close()
+
+	jobs := []Job{}
+	for jobID := 40; jobID > 0; jobID-- {
+		jobs = append(jobs, Job{ID: jobID, ProjectID: jobID % 2})
+	}
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+	client.Concurrency = 4
+	overrides, err := client.GetEnvironmentVariableJobOverrides(context.Background(), nil, jobs)
+
+	require.NoError(t, err)
+	require.Len(t, overrides, 80)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(4))
+	assert.Greater(t, maxInFlight.Load(), int32(1), "the requests should run concurrently")
+
+	expected := []string{}
+	for _, projectID := range []int{0, 1} {
+		for jobID := 1; jobID <= 40; jobID++ {
+			if jobID%2 == projectID {
+				expected = append(expected, fmt.Sprintf("%d/%d/A_VAR", projectID, jobID), fmt.Sprintf("%d/%d/B_VAR", projectID, jobID))
+			}
+		}
+	}
+	got := []string{}
+	for _, override := range overrides {
+		got = append(got, fmt.Sprintf("%d/%d/%s", override.ProjectID, override.JobDefinitionID, override.Name))
+	}
+	assert.Equal(t, expected, got)
+}
+
+func TestGetData_ReturnsErrorOnInvalidJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `<html>maintenance</html>`)
+	}))
+	defer server.Close()
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+	_, err := client.GetData(context.Background(), server.URL+"/v3/accounts/1/groups/")
+
+	assert.ErrorContains(t, err, "error parsing the response")
+}
@@ -0,0 +1,66 @@
+package dbtcloud
+
+import "sync"
+
+// ResponseCache keeps the body of the API responses, keyed by URL, for the
+// lifetime of a client (i.e. one command run).
+//
+// The same endpoints are requested many times during a run: the projects are
+// read for the prefetching and again by each method looping through projects,
+// the environments are read for each credential type, etc. Caching the raw
+// bodies rather than the parsed data means that every caller still gets its
+// own copy of the maps, which generate is free to modify in place.
+//
+// Concurrent requests for the same URL wait for the first one instead of
+// sending the same request again. Errors are not cached.
+type ResponseCache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	hits    int
+	misses  int
+}
+
+type cacheEntry struct {
+	ready chan struct{}
+	body  []byte
+	err   error
+}
+
+func NewResponseCache() *ResponseCache {
+	return &ResponseCache{entries: map[string]*cacheEntry{}}
+}
+
+// get returns the cached body for url, calling fetch to get it the first time.
+func (c *ResponseCache) get(url string, fetch func() ([]byte, error)) ([]byte, error) {
+	c.mu.Lock()
+	if entry, ok := c.entries[url]; ok {
+		c.hits++
+		c.mu.Unlock()
+		<-entry.ready
+		return entry.body, entry.err
+	}
+	entry := &cacheEntry{ready: make(chan struct{})}
+	c.entries[url] = entry
+	c.misses++
+	c.mu.Unlock()
+
+	entry.body, entry.err = fetch()
+	if entry.err != nil {
+		// the requests waiting for this one get the error, the next ones will
+		// try again
+		c.mu.Lock()
+		delete(c.entries, url)
+		c.mu.Unlock()
+	}
+	close(entry.ready)
+
+	return entry.body, entry.err
+}
+
+// Stats returns the number of requests served from the cache and the number
+// of requests sent to the API.
+func (c *ResponseCache) Stats() (hits, misses int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits, c.misses
+}
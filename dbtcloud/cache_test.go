@@ -0,0 +1,109 @@
+package dbtcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCountingServer returns a server answering every request with a single
+// environment and counting the requests per path.
+func newCountingServer(t *testing.T) (*httptest.Server, *sync.Map) {
+	t.Helper()
+	var calls sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count, _ := calls.LoadOrStore(r.URL.Path, new(atomic.Int32))
+		count.(*atomic.Int32).Add(1)
+		// leave time for concurrent requests to pile up
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, `{"data": [{"id": 1, "project_id": 10, "name": "prod"}], "extra": {"pagination": {"count": 1, "total_count": 1}}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func callsFor(calls *sync.Map, path string) int32 {
+	count, ok := calls.Load(path)
+	if !ok {
+		return 0
+	}
+	return count.(*atomic.Int32).Load()
+}
+
+func TestResponseCache_SameURLIsFetchedOnce(t *testing.T) {
+	server, calls := newCountingServer(t)
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+
+	first, err := client.GetEnvironments(context.Background(), nil)
+	require.NoError(t, err)
+	// callers modify the data in place, this must not leak to the next callers
+	first[0].Raw["name"] = "modified"
+
+	second, err := client.GetEnvironments(context.Background(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "prod", second[0].Raw["name"])
+	assert.Equal(t, int32(1), callsFor(calls, "/v3/accounts/1/environments/"))
+	hits, misses := client.Cache.Stats()
+	assert.Equal(t, 1, hits)
+	assert.Equal(t, 1, misses)
+}
+
+func TestResponseCache_ConcurrentRequestsAreFetchedOnce(t *testing.T) {
+	server, calls := newCountingServer(t)
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.GetGroups(context.Background())
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), callsFor(calls, "/v3/accounts/1/groups/"))
+}
+
+func TestResponseCache_Disabled(t *testing.T) {
+	server, calls := newCountingServer(t)
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+	client.Cache = nil
+
+	for range 3 {
+		_, err := client.GetGroups(context.Background())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(3), callsFor(calls, "/v3/accounts/1/groups/"))
+}
+
+func TestResponseCache_ErrorsAreNotCached(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"data": [], "extra": {"pagination": {"count": 0, "total_count": 0}}}`)
+	}))
+	defer server.Close()
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+
+	_, err := client.GetWebhooks(context.Background())
+	assert.ErrorContains(t, err, "403 Forbidden")
+
+	_, err = client.GetWebhooks(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
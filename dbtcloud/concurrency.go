@@ -0,0 +1,65 @@
+package dbtcloud
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// forEach calls fn for every index from 0 to n-1, with up to c.Concurrency
+// calls running at the same time.
+//
+// This is used by the methods fanning out one request per project, job or
+// connection. fn is expected to store its result at index i of a slice
+// allocated by the caller, so that the results keep the order of the input
+// no matter which request finishes first and the generated config stays the
+// same from one run to the other. The errors are joined in the same order.
+//
+// All the workers share the client, so the rate limit of its transport still
+// applies to the total number of requests.
+func (c *DbtCloudHTTPClient) forEach(n int, fn func(i int) error) error {
+	errs := make([]error, n)
+
+	workers := min(max(c.Concurrency, 1), n)
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+
+	for i := range n {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// sortedProjects returns a copy of the projects sorted by ID.
+func sortedProjects(projects []Project) []Project {
+	sorted := slices.Clone(projects)
+	slices.SortStableFunc(sorted, func(a, b Project) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return sorted
+}
+
+// derefNonNil returns the values of the non nil pointers of a slice filled by
+// forEach, the nil ones being for the items whose request failed.
+func derefNonNil[T any](items []*T) []T {
+	values := []T{}
+	for _, item := range items {
+		if item != nil {
+			values = append(values, *item)
+		}
+	}
+	return values
+}
@@ -0,0 +1,419 @@
+package dbtcloud
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// The models below only type the fields the tool reads to make decisions
+// (IDs, links between objects, the type of a connection...). The generated
+// config is driven by the Terraform provider schema and needs every field of
+// the payload, so each model also keeps the object as returned by the API in
+// Raw, which generate is free to modify.
+//
+// Nullable fields are pointers, a field missing from the payload or set to
+// null leaves the zero value for the other ones.
+
+// payload is embedded in every model to keep the object as returned by the
+// API.
+type payload struct {
+	// Raw is the object as returned by the API
+	Raw map[string]any `json:"-"`
+}
+
+func (p *payload) setRaw(raw map[string]any) {
+	p.Raw = raw
+}
+
+// RawPayload returns the object as returned by the API.
+func (p payload) RawPayload() map[string]any {
+	return p.Raw
+}
+
+// Model is implemented by all the models, through the embedded payload.
+type Model interface {
+	RawPayload() map[string]any
+}
+
+// RawPayloads returns the raw payloads of models, in the same order.
+func RawPayloads[T Model](models []T) []any {
+	payloads := make([]any, 0, len(models))
+	for _, model := range models {
+		payloads = append(payloads, model.RawPayload())
+	}
+	return payloads
+}
+
+type Project struct {
+	payload
+	ID           int         `json:"id"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	RepositoryID *int        `json:"repository_id"`
+	Connection   *Connection `json:"connection"`
+}
+
+func (p *Project) setRaw(raw map[string]any) {
+	p.payload.setRaw(raw)
+	if p.Connection != nil {
+		p.Connection.setRaw(asMap(raw["connection"]))
+	}
+}
+
+// Connection is a connection attached to a project, the details depend on the
+// type of warehouse.
+type Connection struct {
+	payload
+	ID        int            `json:"id"`
+	ProjectID int            `json:"project_id"`
+	Name      string         `json:"name"`
+	Type      string         `json:"type"`
+	Details   map[string]any `json:"details"`
+}
+
+// WarehouseType returns the type of the connection, followed by the type of
+// the adapter for the connections of type "adapter", e.g. "adapter/databricks".
+func (c Connection) WarehouseType() string {
+	if c.Type != "adapter" {
+		return c.Type
+	}
+	return fmt.Sprintf("adapter/%s", c.AdapterField("type"))
+}
+
+// AdapterField returns the value of a field of the details of an adapter
+// connection, or an empty string if the connection doesn't have it.
+func (c Connection) AdapterField(name string) string {
+	connectionDetails := asMap(c.Details["connection_details"])
+	fields := asMap(connectionDetails["fields"])
+	field := asMap(fields[name])
+	value, _ := field["value"].(string)
+	return value
+}
+
+type Job struct {
+	payload
+	ID                            int                            `json:"id"`
+	ProjectID                     int                            `json:"project_id"`
+	EnvironmentID                 int                            `json:"environment_id"`
+	DeferringEnvironmentID        *int                           `json:"deferring_environment_id"`
+	Name                          string                         `json:"name"`
+	Description                   string                         `json:"description"`
+	JobType                       string                         `json:"job_type"`
+	DbtVersion                    *string                        `json:"dbt_version"`
+	ExecuteSteps                  []string                       `json:"execute_steps"`
+	Settings                      JobSettings                    `json:"settings"`
+	Execution                     JobExecution                   `json:"execution"`
+	Schedule                      JobSchedule                    `json:"schedule"`
+	Triggers                      JobTriggers                    `json:"triggers"`
+	JobCompletionTriggerCondition *JobCompletionTriggerCondition `json:"job_completion_trigger_condition"`
+}
+
+type JobSettings struct {
+	Threads    int    `json:"threads"`
+	TargetName string `json:"target_name"`
+}
+
+type JobExecution struct {
+	TimeoutSeconds int `json:"timeout_seconds"`
+}
+
+type JobSchedule struct {
+	// Cron is the cron expression of the schedule, whatever its type
+	Cron string          `json:"cron"`
+	Date JobScheduleDate `json:"date"`
+	Time JobScheduleTime `json:"time"`
+}
+
+type JobScheduleDate struct {
+	// Type is one of "every_day", "days_of_week", "custom_cron" or
+	// "interval_cron"
+	Type string `json:"type"`
+	Days []int  `json:"days"`
+	Cron string `json:"cron"`
+}
+
+type JobScheduleTime struct {
+	// Type is either "every_hour" or "at_exact_hours"
+	Type string `json:"type"`
+	// Interval is the number of hours between the runs with "every_hour"
+	Interval int   `json:"interval"`
+	Hours    []int `json:"hours"`
+}
+
+type JobTriggers struct {
+	GithubWebhook      bool `json:"github_webhook"`
+	GitProviderWebhook bool `json:"git_provider_webhook"`
+	Schedule           bool `json:"schedule"`
+	OnMerge            bool `json:"on_merge"`
+}
+
+// JobCompletionTriggerCondition is the "run after" configuration of a job,
+// the job runs when the job of the condition completes with one of the
+// statuses.
+type JobCompletionTriggerCondition struct {
+	Condition *JobCompletionCondition `json:"condition"`
+}
+
+type JobCompletionCondition struct {
+	JobID     int   `json:"job_id"`
+	ProjectID int   `json:"project_id"`
+	Statuses  []int `json:"statuses"`
+}
+
+// Environment is either bound to a profile (PrimaryProfileID) or to a
+// connection, credentials and extended attributes directly, never both.
+type Environment struct {
+	payload
+	ID                   int     `json:"id"`
+	ProjectID            int     `json:"project_id"`
+	Name                 string  `json:"name"`
+	Type                 string  `json:"type"`
+	DeploymentType       *string `json:"deployment_type"`
+	DbtVersion           string  `json:"dbt_version"`
+	ConnectionID         *int    `json:"connection_id"`
+	CredentialsID        *int    `json:"credentials_id"`
+	ExtendedAttributesID *int    `json:"extended_attributes_id"`
+	PrimaryProfileID     *int    `json:"primary_profile_id"`
+	// Credentials is embedded by the API in the environment payload
+	Credentials *Credential `json:"credentials"`
+}
+
+func (e *Environment) setRaw(raw map[string]any) {
+	e.payload.setRaw(raw)
+	if e.Credentials != nil {
+		e.Credentials.setRaw(asMap(raw["credentials"]))
+	}
+}
+
+type Credential struct {
+	payload
+	ID        int `json:"id"`
+	ProjectID int `json:"project_id"`
+	// EnvironmentID is not returned by the API, GetCredentials sets it to the
+	// environment using the credential
+	EnvironmentID  int    `json:"environment_id"`
+	Type           string `json:"type"`
+	AdapterVersion string `json:"adapter_version"`
+	AuthType       string `json:"auth_type"`
+	// UnencryptedCredentialDetails is only returned when getting a single
+	// credential
+	UnencryptedCredentialDetails map[string]any `json:"unencrypted_credential_details"`
+}
+
+// WarehouseType returns the type of warehouse of the credential, e.g.
+// "snowflake" or "databricks" for an adapter credential with the version
+// "databricks_v0".
+func (c Credential) WarehouseType() string {
+	if c.Type == "adapter" && len(c.AdapterVersion) > len("_v0") {
+		return c.AdapterVersion[:len(c.AdapterVersion)-len("_v0")]
+	}
+	return c.Type
+}
+
+type Repository struct {
+	payload
+	ID                   int    `json:"id"`
+	ProjectID            int    `json:"project_id"`
+	RemoteURL            string `json:"remote_url"`
+	GitCloneStrategy     string `json:"git_clone_strategy"`
+	GithubInstallationID *int   `json:"github_installation_id"`
+}
+
+type ExtendedAttributes struct {
+	payload
+	ID                 int            `json:"id"`
+	ProjectID          int            `json:"project_id"`
+	ExtendedAttributes map[string]any `json:"extended_attributes"`
+}
+
+// GlobalConnection is an account level connection. The summary returned when
+// listing the connections doesn't have the Config.
+type GlobalConnection struct {
+	payload
+	ID             int            `json:"id"`
+	Name           string         `json:"name"`
+	AdapterVersion string         `json:"adapter_version"`
+	Config         map[string]any `json:"config"`
+}
+
+// Profile binds a connection, credentials and optional extended attributes in
+// a project.
+type Profile struct {
+	payload
+	ID                   int    `json:"id"`
+	ProjectID            int    `json:"project_id"`
+	Key                  string `json:"key"`
+	ConnectionID         *int   `json:"connection_id"`
+	CredentialsID        *int   `json:"credentials_id"`
+	ExtendedAttributesID *int   `json:"extended_attributes_id"`
+	// Credentials is not returned by the API, GetProfiles sets it to the
+	// credentials of CredentialsID
+	Credentials *Credential `json:"credentials"`
+}
+
+func (p *Profile) setRaw(raw map[string]any) {
+	p.payload.setRaw(raw)
+	if p.Credentials != nil {
+		p.Credentials.setRaw(asMap(raw["credentials"]))
+	}
+}
+
+type Group struct {
+	payload
+	ID               int          `json:"id"`
+	Name             string       `json:"name"`
+	AssignByDefault  bool         `json:"assign_by_default"`
+	SSOMappingGroups []string     `json:"sso_mapping_groups"`
+	GroupPermissions []Permission `json:"group_permissions"`
+}
+
+func (g *Group) setRaw(raw map[string]any) {
+	g.payload.setRaw(raw)
+	setRawList(g.GroupPermissions, raw["group_permissions"])
+}
+
+// Permission is a permission set given to a group or a service token, for
+// all projects or for a single one.
+type Permission struct {
+	payload
+	PermissionSet string `json:"permission_set"`
+	ProjectID     *int   `json:"project_id"`
+	AllProjects   bool   `json:"all_projects"`
+}
+
+type User struct {
+	payload
+	ID          int              `json:"id"`
+	Email       string           `json:"email"`
+	FirstName   string           `json:"first_name"`
+	LastName    string           `json:"last_name"`
+	Permissions []UserPermission `json:"permissions"`
+}
+
+// UserPermission is the membership of a user in an account.
+type UserPermission struct {
+	Groups []Group `json:"groups"`
+}
+
+type Webhook struct {
+	payload
+	// the IDs of webhooks are strings, and so are the IDs of their jobs
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	ClientURL   string   `json:"client_url"`
+	EventTypes  []string `json:"event_types"`
+	JobIDs      []string `json:"job_ids"`
+	Active      bool     `json:"active"`
+}
+
+// NotificationTypeExternalEmail is the type of the notifications sent to an
+// email address instead of a user.
+const NotificationTypeExternalEmail = 4
+
+type Notification struct {
+	payload
+	ID            int     `json:"id"`
+	UserID        int     `json:"user_id"`
+	Type          int     `json:"type"`
+	State         int     `json:"state"`
+	ExternalEmail *string `json:"external_email"`
+	OnCancel      []int   `json:"on_cancel"`
+	OnFailure     []int   `json:"on_failure"`
+	OnSuccess     []int   `json:"on_success"`
+	OnWarning     []int   `json:"on_warning"`
+}
+
+type ServiceToken struct {
+	payload
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	State int    `json:"state"`
+}
+
+// EnvironmentVariable is a variable of a project with its value in each
+// environment. Values is keyed by environment name, "project" being the
+// default value of the project, and is nil for the environments where the
+// variable isn't set.
+type EnvironmentVariable struct {
+	Name   string
+	Values map[string]*EnvironmentVariableValue
+}
+
+type EnvironmentVariableValue struct {
+	ID    int    `json:"id"`
+	Value string `json:"value"`
+}
+
+// EnvironmentVariableJobOverride is the value of an environment variable
+// overridden at the job level. It is not returned as is by the API, see
+// GetEnvironmentVariableJobOverrides.
+type EnvironmentVariableJobOverride struct {
+	payload
+	ID              int    `json:"environment_variable_job_override_id"`
+	Name            string `json:"name"`
+	ProjectID       int    `json:"project_id"`
+	JobDefinitionID int    `json:"job_definition_id"`
+	RawValue        string `json:"raw_value"`
+}
+
+// rawSetter is implemented by the pointers to the models.
+type rawSetter[T any] interface {
+	*T
+	setRaw(raw map[string]any)
+}
+
+// Decode converts an object returned by the API to a model. It returns an
+// error instead of panicking when a field doesn't have the expected type.
+func Decode[T any, PT rawSetter[T]](item any) (T, error) {
+	var model T
+
+	raw, ok := item.(map[string]any)
+	if !ok {
+		return model, fmt.Errorf("expected an object, got %T", item)
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return model, err
+	}
+	if err := json.Unmarshal(data, &model); err != nil {
+		return model, err
+	}
+	PT(&model).setRaw(raw)
+
+	return model, nil
+}
+
+// decodeList converts the objects returned by the API to models. The objects
+// that can't be decoded are skipped with a warning, so that a single odd
+// payload doesn't stop the whole run.
+func decodeList[T any, PT rawSetter[T]](kind string, items []any) []T {
+	models := make([]T, 0, len(items))
+	for _, item := range items {
+		model, err := Decode[T, PT](item)
+		if err != nil {
+			log.Warnf("skipping the %s %v, its payload doesn't have the expected format: %v", kind, asMap(item)["id"], err)
+			continue
+		}
+		models = append(models, model)
+	}
+	return models
+}
+
+// setRawList sets the raw payloads of the models decoded from the list raw.
+func setRawList[T any, PT rawSetter[T]](models []T, raw any) {
+	items, _ := raw.([]any)
+	for i := range models {
+		if i < len(items) {
+			PT(&models[i]).setRaw(asMap(items[i]))
+		}
+	}
+}
+
+// asMap returns value if it is an object, nil otherwise. Reading from a nil
+// map is fine, so the result can be used to safely traverse nested objects.
+func asMap(value any) map[string]any {
+	valueTyped, _ := value.(map[string]any)
+	return valueTyped
+}
@@ -0,0 +1,71 @@
+package dbtcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDecode_NullableFields checks that the fields set to null or missing
+// from the payload are decoded as nil, and that the raw payload is kept for
+// the nested models as well.
+func TestDecode_NullableFields(t *testing.T) {
+	environment, err := Decode[Environment](map[string]any{
+		"id":                     float64(1),
+		"project_id":             float64(10),
+		"name":                   "prod",
+		"credentials_id":         float64(100),
+		"extended_attributes_id": nil,
+		"credentials":            map[string]any{"id": float64(100), "type": "snowflake"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, environment.ID)
+	require.NotNil(t, environment.CredentialsID)
+	assert.Equal(t, 100, *environment.CredentialsID)
+	assert.Nil(t, environment.ExtendedAttributesID)
+	assert.Nil(t, environment.PrimaryProfileID)
+	require.NotNil(t, environment.Credentials)
+	assert.Equal(t, "snowflake", environment.Credentials.Type)
+	assert.Equal(t, "snowflake", environment.Credentials.Raw["type"])
+	assert.Equal(t, "prod", environment.Raw["name"])
+}
+
+// TestDecode_UnexpectedPayload checks that a field with an unexpected type
+// returns an error instead of panicking.
+func TestDecode_UnexpectedPayload(t *testing.T) {
+	_, err := Decode[Job](map[string]any{"id": float64(1), "settings": "not an object"})
+	assert.Error(t, err)
+
+	_, err = Decode[Job]([]any{})
+	assert.ErrorContains(t, err, "expected an object")
+}
+
+// TestGetJobs_SkipsUnexpectedPayload checks that a job that can't be decoded
+// is skipped while the other jobs are still returned.
+func TestGetJobs_SkipsUnexpectedPayload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"data": [
+			{"id": 1, "project_id": 10, "settings": {"threads": 4, "target_name": "prod"}},
+			{"id": 2, "project_id": 10, "settings": {"threads": "four"}},
+			{"id": 3, "project_id": 10, "deferring_environment_id": null}
+		], "extra": {"pagination": {"count": 3, "total_count": 3}}}`)
+	}))
+	defer server.Close()
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", http.DefaultTransport)
+	jobs, err := client.GetJobs(context.Background(), nil)
+	require.NoError(t, err)
+
+	require.Len(t, jobs, 2)
+	assert.Equal(t, 1, jobs[0].ID)
+	assert.Equal(t, 4, jobs[0].Settings.Threads)
+	assert.Equal(t, 3, jobs[1].ID)
+	assert.Nil(t, jobs[1].DeferringEnvironmentID)
+}
@@ -0,0 +1,164 @@
+package dbtcloud
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot file format written by the
+// `fetch` command. It needs to be bumped whenever the layout of Snapshot
+// changes in a way older versions of the tool can't read.
+const SnapshotVersion = 1
+
+// Snapshot is a point-in-time copy of every API payload needed to generate
+// and import the resources of an account.
+//
+// Responses are keyed by request URI (path and query, e.g.
+// "/api/v2/accounts/123/projects/?offset=100") rather than by resource type.
+// This way the snapshot replays exactly what the client requested when it was
+// recorded, including pagination and the per-project/per-job fan-out calls,
+// and the generate/import code doesn't need to know it's reading from a file.
+type Snapshot struct {
+	Version   int                        `json:"version"`
+	FetchedAt time.Time                  `json:"fetched_at"`
+	HostURL   string                     `json:"host_url"`
+	AccountID string                     `json:"account_id"`
+	Projects  []int                      `json:"projects,omitempty"`
+	Responses map[string]json.RawMessage `json:"responses"`
+}
+
+// NewSnapshot returns an empty snapshot for the given account, ready to be
+// filled by a RecordingTransport.
+func NewSnapshot(hostURL, accountID string, projects []int) *Snapshot {
+	return &Snapshot{
+		Version:   SnapshotVersion,
+		FetchedAt: time.Now().UTC(),
+		HostURL:   hostURL,
+		AccountID: accountID,
+		Projects:  projects,
+		Responses: map[string]json.RawMessage{},
+	}
+}
+
+// LoadSnapshot reads a snapshot file written by the `fetch` command.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot %s: %v", path, err)
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("error parsing snapshot %s: %v", path, err)
+	}
+
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("snapshot %s has version %d, this version of dbtcloud-terraforming only supports version %d", path, snapshot.Version, SnapshotVersion)
+	}
+
+	if snapshot.Responses == nil {
+		snapshot.Responses = map[string]json.RawMessage{}
+	}
+
+	return &snapshot, nil
+}
+
+// Write saves the snapshot as indented JSON. encoding/json sorts map keys, so
+// two fetches of an unchanged account produce the same file (apart from
+// fetched_at), which keeps snapshots reviewable in a pull request.
+func (s *Snapshot) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	_, err = w.Write(data)
+	return err
+}
+
+// RecordingTransport wraps another transport and stores every successful JSON
+// response it sees in a Snapshot.
+type RecordingTransport struct {
+	Transport http.RoundTripper
+	snapshot  *Snapshot
+	mu        sync.Mutex
+}
+
+func NewRecordingTransport(transport http.RoundTripper, snapshot *Snapshot) *RecordingTransport {
+	return &RecordingTransport{
+		Transport: transport,
+		snapshot:  snapshot,
+	}
+}
+
+// RoundTrip overrides the http.RoundTrip to record the responses.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.Transport.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+
+	// errors are not recorded, replaying the snapshot will report the URL as
+	// missing which is what we want
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error reading body: %v", err)
+	}
+	// we need to put back the body so that the client can read it
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	if !json.Valid(body) {
+		return resp, nil
+	}
+
+	t.mu.Lock()
+	t.snapshot.Responses[req.URL.RequestURI()] = json.RawMessage(body)
+	t.mu.Unlock()
+
+	return resp, nil
+}
+
+// SnapshotTransport serves requests from a Snapshot instead of the network.
+// URLs missing from the snapshot get a 404 so that they surface the same way
+// as any other API error.
+type SnapshotTransport struct {
+	snapshot *Snapshot
+}
+
+func NewSnapshotTransport(snapshot *Snapshot) *SnapshotTransport {
+	return &SnapshotTransport{snapshot: snapshot}
+}
+
+// RoundTrip overrides the http.RoundTrip to read the responses from the snapshot.
+func (t *SnapshotTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	statusCode := http.StatusOK
+	body, ok := t.snapshot.Responses[req.URL.RequestURI()]
+	if !ok {
+		statusCode = http.StatusNotFound
+		body = []byte(fmt.Sprintf(`{"status": {"user_message": "%s is not in the snapshot, it might need to be fetched again"}}`, req.URL.RequestURI()))
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+		StatusCode:    statusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package dbtcloud
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSnapshot_RecordAndReplay records the paginated projects endpoint of a
+// fake API and checks that replaying the written snapshot returns the same
+// data without any network access, while URLs that were never fetched fail.
+func TestSnapshot_RecordAndReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("offset") == "1" {
+			fmt.Fprint(w, `{"data": [{"id": 2, "name": "second"}], "extra": {"pagination": {"count": 1, "total_count": 2}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": [{"id": 1, "name": "first"}], "extra": {"pagination": {"count": 1, "total_count": 2}}}`)
+	}))
+	defer server.Close()
+
+	snapshot := NewSnapshot(server.URL, "123", nil)
+	recordingClient := NewDbtCloudHTTPClient(server.URL, "token", "123", NewRecordingTransport(http.DefaultTransport, snapshot))
+	liveProjects, err := recordingClient.GetProjects(context.Background(), nil)
+	require.NoError(t, err)
+	require.Len(t, liveProjects, 2)
+	assert.Len(t, snapshot.Responses, 2, "both pages must be recorded")
+
+	var buf bytes.Buffer
+	require.NoError(t, snapshot.Write(&buf))
+	server.Close()
+
+	path := t.TempDir() + "/snapshot.json"
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	loaded, err := LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, "123", loaded.AccountID)
+
+	replayClient := NewDbtCloudHTTPClient(loaded.HostURL, "", loaded.AccountID, NewSnapshotTransport(loaded))
+	replayedProjects, err := replayClient.GetProjects(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, liveProjects, replayedProjects)
+
+	_, err = replayClient.GetEndpoint(context.Background(), fmt.Sprintf("%s/v2/accounts/123/jobs/", loaded.HostURL))
+	assert.ErrorContains(t, err, "is not in the snapshot")
+}
+
+func TestSnapshot_LoadRejectsUnknownVersion(t *testing.T) {
+	path := t.TempDir() + "/snapshot.json"
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 999, "responses": {}}`), 0644))
+
+	_, err := LoadSnapshot(path)
+	assert.ErrorContains(t, err, "version 999")
+}
@@ -0,0 +1,151 @@
+package dbtcloud
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+const (
+	// DefaultRequestsPerMinute is the default rate limit of the client. It
+	// matches the limit of the multi-tenant dbt Cloud instances, single-tenant
+	// instances might need a lower value.
+	DefaultRequestsPerMinute = 3000
+
+	// DefaultMaxRetries is the default number of times a request is retried
+	// after a 429, a 5xx or a network error.
+	DefaultMaxRetries = 5
+)
+
+// RateLimitedTransport limits the number of requests sent per minute and
+// retries the requests failing with a status code or an error that is likely
+// to be temporary.
+//
+// Retries use an exponential backoff with jitter, starting at minBackoff and
+// capped at maxBackoff. When the API returns a Retry-After header (usually
+// with a 429 or a 503) we wait for the time it asks for instead.
+type RateLimitedTransport struct {
+	*http.Transport
+	limiter    *rate.Limiter
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// NewRateLimitedTransport returns a transport sending at most
+// requestsPerMinute requests per minute (no limit if it is 0 or less) and
+// retrying each request up to maxRetries times.
+func NewRateLimitedTransport(requestsPerMinute, maxRetries int) *RateLimitedTransport {
+	limit := rate.Inf
+	if requestsPerMinute > 0 {
+		limit = rate.Limit(float64(requestsPerMinute) / 60)
+	}
+
+	// Create a custom transport which is a modified clone of DefaultTransport
+	// DefaultTransport handles https_proxy env var that we need to capture HTTP calls
+	return &RateLimitedTransport{
+		Transport:  http.DefaultTransport.(*http.Transport).Clone(),
+		limiter:    rate.NewLimiter(limit, 1),
+		maxRetries: max(maxRetries, 0),
+		minBackoff: time.Second,
+		maxBackoff: 30 * time.Second,
+	}
+}
+
+// RoundTrip overrides the http.RoundTrip to implement rate limiting and retries.
+func (t *RateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		// Wait for permission from the rate limiter, retries count as requests too
+		if err := t.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.Transport.RoundTrip(req)
+		if attempt >= t.maxRetries || !isRetryable(req, resp, err) {
+			return resp, err
+		}
+
+		delay := t.backoff(attempt)
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = retryAfter
+			}
+			// the body needs to be read fully for the connection to be reused
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+			resp.Body.Close()
+		}
+
+		log.Warnf("%s %s failed with %s, retrying in %s (retry %d of %d)", req.Method, req.URL, reason, delay.Round(time.Millisecond), attempt+1, t.maxRetries)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the time to wait before the retry following the given
+// attempt: minBackoff doubled at each attempt, capped at maxBackoff, with a
+// random jitter of up to half of it so that concurrent clients don't retry all
+// at the same time.
+func (t *RateLimitedTransport) backoff(attempt int) time.Duration {
+	delay := t.maxBackoff
+	if attempt < 32 && t.minBackoff<<attempt < t.maxBackoff {
+		delay = t.minBackoff << attempt
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// isRetryable reports whether a request can be sent again after getting resp
+// or err. Only GET and HEAD requests are retried, they are the only ones
+// that are safe to send twice and that don't have a body to replay.
+func isRetryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+
+	if err != nil {
+		// a request cancelled or timed out on our side is not a network error
+		return req.Context().Err() == nil
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// parseRetryAfter parses the value of a Retry-After header which can either
+// be a number of seconds or an HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	log.Debugf("ignoring invalid Retry-After header %q", value)
+	return 0, false
+}
@@ -0,0 +1,167 @@
+package dbtcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newScriptedServer returns a server answering the n-th request with the
+// n-th status code of script, and 200 once the script is exhausted.
+func newScriptedServer(t *testing.T, script []int, headers http.Header) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1)) - 1
+		for key, values := range headers {
+			w.Header()[key] = values
+		}
+		if call < len(script) {
+			w.WriteHeader(script[call])
+			fmt.Fprint(w, `{"status": {"user_message": "scripted failure"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data": [{"id": 1}], "extra": {"pagination": {"count": 1, "total_count": 1}}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// newTestTransport returns a transport with no rate limit and tiny backoffs
+// so that the tests don't wait.
+func newTestTransport(maxRetries int) *RateLimitedTransport {
+	transport := NewRateLimitedTransport(0, maxRetries)
+	transport.minBackoff = time.Millisecond
+	transport.maxBackoff = 5 * time.Millisecond
+	return transport
+}
+
+func TestRateLimitedTransport_RetriesTemporaryErrors(t *testing.T) {
+	server, calls := newScriptedServer(t, []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable}, nil)
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", newTestTransport(5))
+	data, err := client.GetData(context.Background(), server.URL+"/v3/accounts/1/groups/")
+
+	require.NoError(t, err)
+	assert.Len(t, data, 1)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestRateLimitedTransport_GivesUpAfterMaxRetries(t *testing.T) {
+	server, calls := newScriptedServer(t, []int{500, 500, 500, 500, 500}, nil)
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", newTestTransport(2))
+	_, err := client.GetData(context.Background(), server.URL+"/v3/accounts/1/groups/")
+
+	assert.ErrorContains(t, err, "500 Internal Server Error")
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRateLimitedTransport_DoesNotRetryClientErrors(t *testing.T) {
+	server, calls := newScriptedServer(t, []int{http.StatusForbidden}, nil)
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", newTestTransport(5))
+	_, err := client.GetData(context.Background(), server.URL+"/v3/accounts/1/webhooks/subscriptions")
+
+	assert.ErrorContains(t, err, "403 Forbidden")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRateLimitedTransport_HonorsRetryAfter(t *testing.T) {
+	server, calls := newScriptedServer(t, []int{http.StatusTooManyRequests}, http.Header{"Retry-After": []string{"0"}})
+
+	// without Retry-After the retry would wait for an hour
+	transport := newTestTransport(1)
+	transport.minBackoff = time.Hour
+	transport.maxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", transport)
+	_, err := client.GetData(ctx, server.URL+"/v3/accounts/1/groups/")
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRateLimitedTransport_RetriesNetworkErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			// drop the connection without answering
+			conn, _, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			conn.Close()
+			return
+		}
+		fmt.Fprint(w, `{"data": {"id": 1}}`)
+	}))
+	defer server.Close()
+
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", newTestTransport(3))
+	data, err := client.GetSingleData(context.Background(), server.URL+"/v3/accounts/1/connections/1/")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"id": float64(1)}, data)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestRateLimitedTransport_StopsWhenContextIsCancelled(t *testing.T) {
+	server, calls := newScriptedServer(t, []int{503, 503, 503}, nil)
+
+	transport := newTestTransport(3)
+	transport.minBackoff = time.Hour
+	transport.maxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := NewDbtCloudHTTPClient(server.URL, "token", "1", transport)
+	_, err := client.GetData(ctx, server.URL+"/v3/accounts/1/groups/")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRateLimitedTransport_Backoff(t *testing.T) {
+	transport := NewRateLimitedTransport(0, 5)
+
+	for attempt, expectedMax := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second} {
+		delay := transport.backoff(attempt)
+		assert.GreaterOrEqual(t, delay, expectedMax/2, "attempt %d", attempt)
+		assert.LessOrEqual(t, delay, expectedMax, "attempt %d", attempt)
+	}
+
+	// large attempts must not overflow
+	assert.LessOrEqual(t, transport.backoff(100), 30*time.Second)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"", 0, false},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0, true},
+	}
+
+	for _, test := range tests {
+		got, ok := parseRetryAfter(test.value)
+		assert.Equal(t, test.ok, ok, test.value)
+		assert.Equal(t, test.expected, got, test.value)
+	}
+
+	got, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.InDelta(t, time.Minute, got, float64(2*time.Second))
+}
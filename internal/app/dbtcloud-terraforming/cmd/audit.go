@@ -0,0 +1,431 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+}
+
+var auditFormat string
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Report the orphaned and inconsistent objects of the account that generate and import skip",
+	Long: `Checks the objects of the account (of the projects of --projects if set) and reports the ones that generate
+and import skip or work around, e.g. the credentials no environment uses or the webhooks pointing at deleted jobs,
+with their severity, the reason and the URL of their page in the dbt Cloud UI, in the --audit-format format.
+The command fails when a finding has the severity error.`,
+	Run:    runAudit(),
+	PreRun: sharedPreRun,
+}
+
+// auditRule is a check of the audit. The severity is one of the levels of
+// SARIF: error, warning or note.
+type auditRule struct {
+	id          string
+	severity    string
+	description string
+}
+
+var auditRules = []auditRule{
+	{"deleted-job", "error", "The object refers to jobs that were deleted"},
+	{"unused-credential", "warning", "The credentials are not used by any environment or profile"},
+	{"missing-connection", "error", "The object uses a connection that does not exist or was deleted"},
+	{"unused-connection", "warning", "The connection is not used by any environment or profile"},
+	{"notification-without-email", "warning", "The external email notification has no email address"},
+	{"notification-unknown-user", "warning", "The notification is for a user who is not in the account anymore"},
+	{"project-without-repository", "note", "The project has no repository"},
+}
+
+// auditSeverities orders the findings, the most severe first
+var auditSeverities = []string{"error", "warning", "note"}
+
+// auditFinding is an object of the account breaking an auditRule.
+type auditFinding struct {
+	Rule         string `json:"rule"`
+	Severity     string `json:"severity"`
+	ResourceType string `json:"resource_type"`
+	ID           string `json:"id"`
+	Name         string `json:"name,omitempty"`
+	Reason       string `json:"reason"`
+	URL          string `json:"url,omitempty"`
+}
+
+// object returns the description of the object of the finding, e.g.
+// dbtcloud_webhook wh_123 "Slack alerts".
+func (f auditFinding) object() string {
+	if f.Name == "" {
+		return fmt.Sprintf("%s %s", f.ResourceType, f.ID)
+	}
+	return fmt.Sprintf("%s %s %q", f.ResourceType, f.ID, f.Name)
+}
+
+// auditData is the data checked by the audit. The jobs are the ones of the
+// whole account, to tell the deleted ones from the ones of the projects not
+// selected.
+type auditData struct {
+	// projectsFiltered is set when --projects is, the objects of the other
+	// projects are missing
+	projectsFiltered  bool
+	projects          []dbtcloud.Project
+	environments      []dbtcloud.Environment
+	profiles          []dbtcloud.Profile
+	credentials       []dbtcloud.Credential
+	globalConnections []dbtcloud.GlobalConnection
+	jobs              []dbtcloud.Job
+	webhooks          []dbtcloud.Webhook
+	notifications     []dbtcloud.Notification
+	users             []dbtcloud.User
+}
+
+func runAudit() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if !lo.Contains([]string{"text", "json", "sarif"}, auditFormat) {
+			log.Fatalf("--audit-format must be either text, json or sarif, not %q", auditFormat)
+		}
+		if outputDir != "" {
+			log.Fatal("--output-dir can't be used with audit, use --output")
+		}
+
+		listFilterProjects = viper.GetIntSlice("projects")
+
+		findings := auditAccount(fetchAuditData(cmd))
+
+		writer, closer, err := getOutputWriter()
+		if err != nil {
+			log.Fatalf("failed to write findings: %v", err)
+		}
+		defer closer()
+
+		if err := writeFindings(writer, findings, auditFormat); err != nil {
+			log.Fatalf("failed to write findings: %v", err)
+		}
+
+		// the command fails after the summary of the errors of the run, see
+		// Execute
+		if errors := lo.CountBy(findings, func(finding auditFinding) bool { return finding.Severity == "error" }); errors > 0 {
+			log.Errorf("%d findings have the severity error", errors)
+			runFailed = true
+		}
+	}
+}
+
+// fetchAuditData fetches the data of the audit. A failure is recorded
+// against the resource type of the data, its checks are then incomplete.
+func fetchAuditData(cmd *cobra.Command) auditData {
+	ctx := cmd.Context()
+	data := auditData{projectsFiltered: len(listFilterProjects) > 0}
+
+	var err error
+	data.projects, err = dbtCloudClient.GetProjects(ctx, listFilterProjects)
+	recordError("dbtcloud_project", err)
+	data.environments, err = dbtCloudClient.GetEnvironments(ctx, listFilterProjects)
+	recordError("dbtcloud_environment", err)
+	data.profiles, err = dbtCloudClient.GetProfiles(ctx, listFilterProjects)
+	recordError("dbtcloud_profile", err)
+	data.credentials, err = dbtCloudClient.GetAllCredentials(ctx, listFilterProjects)
+	recordError("credentials", err)
+	data.globalConnections, err = dbtCloudClient.GetGlobalConnectionsSummary(ctx)
+	recordError("dbtcloud_global_connection", err)
+	data.jobs, err = dbtCloudClient.GetJobs(ctx, nil)
+	recordError("dbtcloud_job", err)
+	data.webhooks, err = dbtCloudClient.GetWebhooks(ctx)
+	recordError("dbtcloud_webhook", err)
+	data.notifications, err = dbtCloudClient.GetNotifications(ctx)
+	recordError("dbtcloud_notification", err)
+	data.users, err = dbtCloudClient.GetUsers(ctx)
+	recordError("dbtcloud_user", err)
+	return data
+}
+
+// auditAccount returns the findings of the audit of data, sorted by
+// severity, rule and object.
+func auditAccount(data auditData) []auditFinding {
+	findings := []auditFinding{}
+	add := func(ruleID, resourceType string, id any, name, reason, url string) {
+		rule, _ := lo.Find(auditRules, func(rule auditRule) bool { return rule.id == ruleID })
+		findings = append(findings, auditFinding{
+			Rule:         rule.id,
+			Severity:     rule.severity,
+			ResourceType: resourceType,
+			ID:           fmt.Sprint(id),
+			Name:         name,
+			Reason:       reason,
+			URL:          url,
+		})
+	}
+
+	jobIDs := lo.SliceToMap(data.jobs, func(job dbtcloud.Job) (int, bool) {
+		return job.ID, true
+	})
+	deletedJobs := func(ids []int) []int {
+		return lo.Filter(ids, func(id int, _ int) bool { return !jobIDs[id] })
+	}
+
+	// the webhooks and notifications are for the jobs of the whole account,
+	// none are deleted when the jobs could not be fetched
+	if len(data.jobs) == 0 {
+		deletedJobs = func([]int) []int { return nil }
+	}
+	for _, webhook := range data.webhooks {
+		ids := lo.Map(webhook.JobIDs, func(id string, _ int) int {
+			var jobID int
+			fmt.Sscan(id, &jobID)
+			return jobID
+		})
+		if deleted := deletedJobs(ids); len(deleted) > 0 {
+			reason := fmt.Sprintf("the webhook is for the deleted jobs %s", joinIDs(deleted))
+			if len(deleted) == len(ids) {
+				reason += ", it is not generated as removing them would make it apply to all the jobs"
+			}
+			add("deleted-job", "dbtcloud_webhook", webhook.ID, webhook.Name, reason, resourceHandlers["dbtcloud_webhook"].URL(webhook))
+		}
+	}
+
+	userIDs := lo.SliceToMap(data.users, func(user dbtcloud.User) (int, bool) {
+		return user.ID, true
+	})
+	for _, notification := range data.notifications {
+		name := lo.FromPtr(notification.ExternalEmail)
+		if notification.Type == dbtcloud.NotificationTypeExternalEmail && notification.ExternalEmail == nil {
+			add("notification-without-email", "dbtcloud_notification", notification.ID, name, "the external email notification has no email address, it is not generated", "")
+		}
+		if len(data.users) > 0 && !userIDs[notification.UserID] {
+			add("notification-unknown-user", "dbtcloud_notification", notification.ID, name, fmt.Sprintf("the user %d of the notification is not in the account", notification.UserID), "")
+		}
+		if deleted := deletedJobs(lo.Flatten([][]int{notification.OnCancel, notification.OnFailure, notification.OnSuccess, notification.OnWarning})); len(deleted) > 0 {
+			add("deleted-job", "dbtcloud_notification", notification.ID, name, fmt.Sprintf("the notification is for the deleted jobs %s", joinIDs(lo.Uniq(deleted))), "")
+		}
+	}
+
+	projectIDs := lo.SliceToMap(data.projects, func(project dbtcloud.Project) (int, bool) {
+		return project.ID, true
+	})
+	for _, job := range data.jobs {
+		if !projectIDs[job.ProjectID] || job.JobCompletionTriggerCondition == nil || job.JobCompletionTriggerCondition.Condition == nil {
+			continue
+		}
+		if triggerJobID := job.JobCompletionTriggerCondition.Condition.JobID; !jobIDs[triggerJobID] {
+			add("deleted-job", "dbtcloud_job", job.ID, job.Name, fmt.Sprintf("the job runs on the completion of the deleted job %d", triggerJobID), resourceHandlers["dbtcloud_job"].URL(job))
+		}
+	}
+
+	usedCredentials := map[int]bool{}
+	usedConnections := map[int]bool{}
+	for _, environment := range data.environments {
+		usedCredentials[lo.FromPtr(environment.CredentialsID)] = true
+		usedConnections[lo.FromPtr(environment.ConnectionID)] = true
+	}
+	for _, profile := range data.profiles {
+		usedCredentials[lo.FromPtr(profile.CredentialsID)] = true
+		usedConnections[lo.FromPtr(profile.ConnectionID)] = true
+	}
+	for _, credential := range data.credentials {
+		if !usedCredentials[credential.ID] {
+			add("unused-credential", "credentials", credential.ID, credential.WarehouseType(),
+				fmt.Sprintf("the credentials of the project %d are not used by any environment or profile, they are not generated", credential.ProjectID),
+				settingsURL("projects/%d/", credential.ProjectID))
+		}
+	}
+	// the connections are shared by the projects, those of the projects not
+	// selected can use them
+	if !data.projectsFiltered {
+		for _, connection := range data.globalConnections {
+			if !usedConnections[connection.ID] {
+				add("unused-connection", "dbtcloud_global_connection", connection.ID, connection.Name,
+					"the connection is not used by any environment or profile",
+					resourceHandlers["dbtcloud_global_connection"].URL(connection))
+			}
+		}
+	}
+
+	// the connections of the whole account are fetched, none are missing
+	// when they could not be fetched
+	connectionIDs := lo.SliceToMap(data.globalConnections, func(connection dbtcloud.GlobalConnection) (int, bool) {
+		return connection.ID, true
+	})
+	missingConnection := func(id *int) bool {
+		return len(data.globalConnections) > 0 && id != nil && *id != 0 && !connectionIDs[*id]
+	}
+	for _, project := range data.projects {
+		if project.Connection != nil && missingConnection(&project.Connection.ID) {
+			add("missing-connection", "dbtcloud_project", project.ID, project.Name, fmt.Sprintf("the project uses the connection %d, which does not exist", project.Connection.ID), resourceHandlers["dbtcloud_project"].URL(project))
+		}
+	}
+	for _, environment := range data.environments {
+		if missingConnection(environment.ConnectionID) {
+			add("missing-connection", "dbtcloud_environment", environment.ID, environment.Name, fmt.Sprintf("the environment uses the connection %d, which does not exist", *environment.ConnectionID), resourceHandlers["dbtcloud_environment"].URL(environment))
+		}
+	}
+	for _, profile := range data.profiles {
+		if missingConnection(profile.ConnectionID) {
+			add("missing-connection", "dbtcloud_profile", profile.ID, profile.Key, fmt.Sprintf("the profile uses the connection %d, which does not exist", *profile.ConnectionID), resourceHandlers["dbtcloud_profile"].URL(profile))
+		}
+	}
+
+	for _, project := range data.projects {
+		if project.RepositoryID == nil {
+			add("project-without-repository", "dbtcloud_project", project.ID, project.Name, "the project has no repository, its jobs can't run", resourceHandlers["dbtcloud_project"].URL(project))
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return lo.IndexOf(auditSeverities, a.Severity) < lo.IndexOf(auditSeverities, b.Severity)
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		// the numeric IDs are sorted by value, e.g. 9 before 10
+		if len(a.ID) != len(b.ID) {
+			return len(a.ID) < len(b.ID)
+		}
+		return a.ID < b.ID
+	})
+	return findings
+}
+
+func joinIDs(ids []int) string {
+	return strings.Join(lo.Map(ids, func(id int, _ int) string { return fmt.Sprint(id) }), ", ")
+}
+
+// writeFindings writes the findings in format, either text, json or sarif.
+func writeFindings(w io.Writer, findings []auditFinding, format string) error {
+	switch format {
+	case "text":
+		if len(findings) == 0 {
+			_, err := fmt.Fprintln(w, "no findings")
+			return err
+		}
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SEVERITY\tOBJECT\tREASON\tURL")
+		for _, finding := range findings {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", finding.Severity, finding.object(), finding.Reason, finding.URL)
+		}
+		return tw.Flush()
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(findings)
+	case "sarif":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sarifLog(findings))
+	}
+	return fmt.Errorf("--audit-format must be either text, json or sarif, not %q", format)
+}
+
+// sarifReport is the subset of SARIF 2.1.0 written by the audit. The objects
+// of the account are logical locations, with the URL of their page as
+// physical location when they have one.
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name           string      `json:"name"`
+			Version        string      `json:"version"`
+			InformationURI string      `json:"informationUri"`
+			Rules          []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifRule struct {
+	ID                   string       `json:"id"`
+	ShortDescription     sarifMessage `json:"shortDescription"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+	// the fingerprint identifies the finding across runs
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+func sarifLog(findings []auditFinding) sarifReport {
+	run := sarifRun{Results: []sarifResult{}}
+	run.Tool.Driver.Name = "dbtcloud-terraforming"
+	run.Tool.Driver.Version = versionString
+	run.Tool.Driver.InformationURI = "https://github.com/dbt-labs/dbtcloud-terraforming"
+	for _, rule := range auditRules {
+		sarifRule := sarifRule{ID: rule.id, ShortDescription: sarifMessage{Text: rule.description}}
+		sarifRule.DefaultConfiguration.Level = rule.severity
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule)
+	}
+
+	for _, finding := range findings {
+		location := sarifLocation{LogicalLocations: []sarifLogicalLocation{{
+			Name:               finding.object(),
+			FullyQualifiedName: fmt.Sprintf("%s.%s", finding.ResourceType, finding.ID),
+			Kind:               "resource",
+		}}}
+		if finding.URL != "" {
+			location.PhysicalLocation = &sarifPhysicalLocation{}
+			location.PhysicalLocation.ArtifactLocation.URI = finding.URL
+		}
+
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    finding.Rule,
+			Level:     finding.Severity,
+			Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", finding.object(), finding.Reason)},
+			Locations: []sarifLocation{location},
+			PartialFingerprints: map[string]string{
+				"object/v1": fmt.Sprintf("%s/%s.%s", finding.Rule, finding.ResourceType, finding.ID),
+			},
+		})
+	}
+
+	return sarifReport{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+}
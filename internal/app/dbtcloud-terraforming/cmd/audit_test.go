@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAudit_Account checks the findings of the account of
+// fabricatedAccountData with a sandbox project using a deleted connection, a
+// job triggered by a deleted job and the webhooks and notifications of deleted
+// jobs and users.
+func TestAudit_Account(t *testing.T) {
+	withTestClient(t)
+
+	data := fabricatedAccountData()
+	triggered := dbtcloud.Job{ID: 2, ProjectID: 10, Name: "After nightly"}
+	triggered.JobCompletionTriggerCondition = &dbtcloud.JobCompletionTriggerCondition{Condition: &dbtcloud.JobCompletionCondition{JobID: 3}}
+	findings := auditAccount(auditData{
+		projects:          append(data.projects, dbtcloud.Project{ID: 11, Name: "Sandbox", Connection: &dbtcloud.Connection{ID: 52}}),
+		environments:      data.environments,
+		credentials:       []dbtcloud.Credential{{ID: 1000, ProjectID: 10}, {ID: 1001, ProjectID: 10}, {ID: 999, ProjectID: 10}},
+		globalConnections: []dbtcloud.GlobalConnection{{ID: 50, Name: "Snowflake"}, {ID: 51, Name: "Old"}},
+		jobs:              append(data.jobs, triggered),
+		webhooks: []dbtcloud.Webhook{
+			{ID: "wh_1", Name: "Slack", JobIDs: []string{"1", "3"}},
+			{ID: "wh_2", Name: "Teams", JobIDs: []string{"3"}},
+		},
+		notifications: []dbtcloud.Notification{
+			{ID: 1, UserID: 7, Type: dbtcloud.NotificationTypeExternalEmail},
+			{ID: 2, UserID: 8, OnFailure: []int{1}},
+		},
+		users: []dbtcloud.User{{ID: 7}},
+	})
+
+	assert.Equal(t, []string{
+		"deleted-job dbtcloud_job 2",
+		"deleted-job dbtcloud_webhook wh_1",
+		"deleted-job dbtcloud_webhook wh_2",
+		"missing-connection dbtcloud_project 11",
+		"notification-unknown-user dbtcloud_notification 2",
+		"notification-without-email dbtcloud_notification 1",
+		"unused-connection dbtcloud_global_connection 51",
+		"unused-credential credentials 999",
+		"unused-credential credentials 1001",
+		"project-without-repository dbtcloud_project 11",
+	}, lo.Map(findings, func(finding auditFinding, _ int) string {
+		return finding.Rule + " " + finding.ResourceType + " " + finding.ID
+	}))
+
+	assert.Equal(t, "the webhook is for the deleted jobs 3", findings[1].Reason)
+	assert.Contains(t, findings[2].Reason, "it is not generated")
+	assert.Equal(t, "https://cloud.getdbt.com/settings/accounts/9999/pages/webhooks/wh_1/", findings[1].URL)
+	assert.Equal(t, "error", findings[0].Severity)
+	assert.Equal(t, "the project uses the connection 52, which does not exist", findings[3].Reason)
+	assert.Equal(t, "note", findings[9].Severity)
+}
+
+// TestAudit_ProjectsFiltered checks that the connections are not reported
+// when only some projects are audited, as the other ones can use them.
+func TestAudit_ProjectsFiltered(t *testing.T) {
+	findings := auditAccount(auditData{
+		projectsFiltered:  true,
+		globalConnections: []dbtcloud.GlobalConnection{{ID: 51, Name: "Old"}},
+	})
+	assert.Empty(t, findings)
+}
+
+func TestAudit_Write(t *testing.T) {
+	findings := []auditFinding{
+		{Rule: "project-without-repository", Severity: "note", ResourceType: "dbtcloud_project", ID: "11", Name: "Sandbox", Reason: "the project has no repository"},
+		{Rule: "deleted-job", Severity: "error", ResourceType: "dbtcloud_webhook", ID: "wh_1", Name: "Slack", Reason: "the webhook is for the deleted jobs 3", URL: "https://cloud.getdbt.com/settings/accounts/9999/pages/webhooks/wh_1/"},
+	}
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, writeFindings(&out, findings, "text"))
+		assert.Contains(t, out.String(), "SEVERITY  OBJECT")
+		assert.Contains(t, out.String(), "error     dbtcloud_webhook wh_1 \"Slack\"")
+
+		out.Reset()
+		require.NoError(t, writeFindings(&out, []auditFinding{}, "text"))
+		assert.Equal(t, "no findings\n", out.String())
+	})
+
+	t.Run("sarif", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, writeFindings(&out, findings, "sarif"))
+
+		var report sarifReport
+		require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+		require.Len(t, report.Runs, 1)
+		assert.Equal(t, "2.1.0", report.Version)
+		assert.Len(t, report.Runs[0].Tool.Driver.Rules, len(auditRules))
+
+		result := report.Runs[0].Results[1]
+		assert.Equal(t, "deleted-job", result.RuleID)
+		assert.Equal(t, "error", result.Level)
+		assert.Equal(t, "dbtcloud_webhook.wh_1", result.Locations[0].LogicalLocations[0].FullyQualifiedName)
+		assert.Equal(t, "https://cloud.getdbt.com/settings/accounts/9999/pages/webhooks/wh_1/", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+		assert.Equal(t, "deleted-job/dbtcloud_webhook.wh_1", result.PartialFingerprints["object/v1"])
+	})
+
+	t.Run("unknown format", func(t *testing.T) {
+		assert.Error(t, writeFindings(&bytes.Buffer{}, findings, "xml"))
+	})
+}
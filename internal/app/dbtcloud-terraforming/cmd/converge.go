@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/samber/lo"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+var converge bool
+var convergeMaxIterations int
+
+// convergeConfig plans the output of genimport and rewrites the resources the
+// plan would change, until the plan only imports resources or
+// --converge-max-iterations plans were run. It returns the resources that
+// would still change.
+func convergeConfig(ctx context.Context, output *outputFiles) ([]resourceDrift, error) {
+	workingDir, err := os.MkdirTemp("", "tfconverge")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workingDir)
+
+	if err := output.writeDir(workingDir); err != nil {
+		return nil, err
+	}
+
+	tf, cleanup, err := newTerraform(ctx, workingDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	log.Debugf("initializing Terraform in %s", workingDir)
+	if err := initWorkingDir(ctx, tf); err != nil {
+		return nil, err
+	}
+
+	ps, err := tf.ProvidersSchema(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider schema: %w", err)
+	}
+	schema, err := dbtCloudProviderSchema(ps)
+	if err != nil {
+		return nil, err
+	}
+
+	for iteration := 1; ; iteration++ {
+		plan, err := planConfig(ctx, tf, workingDir)
+		if err != nil {
+			return nil, err
+		}
+
+		drifts := planDrift(plan)
+		if len(drifts) == 0 {
+			log.Infof("the plan only imports resources after %d iterations", iteration)
+			return drifts, nil
+		}
+		if iteration >= convergeMaxIterations {
+			return drifts, nil
+		}
+
+		log.Debugf("iteration %d: rewriting %d resources", iteration, len(drifts))
+		rewritten, err := convergeFiles(output, plan, schema)
+		if err != nil {
+			return nil, err
+		}
+		if !rewritten {
+			// the next plan would be the same
+			return drifts, nil
+		}
+		if err := output.writeDir(workingDir); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// convergeFiles rewrites the resources of output that the plan would change
+// and returns whether any of them was rewritten.
+func convergeFiles(output *outputFiles, plan *tfjson.Plan, schema *tfjson.ProviderSchema) (bool, error) {
+	changes := map[string]*tfjson.ResourceChange{}
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change != nil && (rc.Change.Actions.Update() || rc.Change.Actions.Replace()) {
+			changes[rc.Address] = rc
+		}
+	}
+
+	rewritten := false
+	for _, name := range output.names {
+		file, diags := hclwrite.ParseConfig(output.contents[name].Bytes(), name, hcl.InitialPos)
+		if diags.HasErrors() {
+			return false, diags
+		}
+
+		fileRewritten := false
+		for _, block := range file.Body().Blocks() {
+			if block.Type() != "resource" || len(block.Labels()) != 2 {
+				continue
+			}
+			rc := changes[block.Labels()[0]+"."+block.Labels()[1]]
+			resourceSchema := schema.ResourceSchemas[block.Labels()[0]]
+			if rc == nil || resourceSchema == nil {
+				continue
+			}
+
+			resourceRewritten, err := convergeResource(block.Body(), rc.Change, resourceSchema.Block)
+			if err != nil {
+				return false, fmt.Errorf("failed to rewrite %s: %w", rc.Address, err)
+			}
+			fileRewritten = fileRewritten || resourceRewritten
+		}
+
+		if fileRewritten {
+			output.contents[name].Reset()
+			output.contents[name].Write(hclwrite.Format(file.Bytes()))
+			rewritten = true
+		}
+	}
+	return rewritten, nil
+}
+
+// convergeResource rewrites the attributes of the resource that would change
+// with their value in the imported state, when they are missing or set to a
+// literal. The attributes that can't be read
+// from the state, e.g. sensitive and write-only ones like passwords and
+// tokens, and the nested blocks are added to lifecycle.ignore_changes
+// instead. It returns whether the resource was rewritten.
+func convergeResource(body *hclwrite.Body, change *tfjson.Change, schema *tfjson.SchemaBlock) (bool, error) {
+	before, _ := change.Before.(map[string]any)
+	after, _ := change.After.(map[string]any)
+	afterUnknown, _ := change.AfterUnknown.(map[string]any)
+	beforeSensitive, _ := change.BeforeSensitive.(map[string]any)
+
+	rewritten := false
+	ignored := []string{}
+	for _, attribute := range changedAttributes(change) {
+		if unknown, _ := afterUnknown[attribute].(bool); unknown {
+			// computed by the provider, not set in the config
+			continue
+		}
+
+		attributeSchema := schema.Attributes[attribute]
+		if attributeSchema == nil {
+			if schema.NestedBlocks[attribute] != nil {
+				ignored = append(ignored, attribute)
+			}
+			continue
+		}
+		if attributeSchema.Computed && !attributeSchema.Optional && !attributeSchema.Required {
+			continue
+		}
+
+		sensitive, _ := beforeSensitive[attribute].(bool)
+		if attributeSchema.Sensitive || sensitive || missingInState(before[attribute], after[attribute]) {
+			ignored = append(ignored, attribute)
+			continue
+		}
+
+		value, err := stateValue(before[attribute], attributeSchema)
+		if err != nil {
+			return false, fmt.Errorf("failed to read %s from the state: %w", attribute, err)
+		}
+		if current := body.GetAttribute(attribute); current != nil {
+			if sameTokens(current.Expr().BuildTokens(nil), hclwrite.TokensForValue(value)) {
+				continue
+			}
+			if !literalExpression(current.Expr().BuildTokens(nil)) {
+				// the references, the conditionals of the parameterized jobs
+				// and the variables are kept, the drift is reported
+				log.Debugf("keeping the expression of %s, it is not a literal", attribute)
+				continue
+			}
+		}
+		body.SetAttributeValue(attribute, value)
+		rewritten = true
+	}
+
+	if len(ignored) > 0 && addIgnoreChanges(body, ignored) {
+		rewritten = true
+	}
+	return rewritten, nil
+}
+
+// missingInState returns whether a value set in the config is null in the
+// imported state, like the write-only fields the API doesn't return.
+func missingInState(before, after any) bool {
+	if after == nil {
+		return false
+	}
+	switch after := after.(type) {
+	case map[string]any:
+		before, ok := before.(map[string]any)
+		if !ok {
+			return true
+		}
+		return lo.SomeBy(lo.Keys(after), func(key string) bool {
+			return missingInState(before[key], after[key])
+		})
+	case []any:
+		before, ok := before.([]any)
+		if !ok {
+			return true
+		}
+		for i := range after {
+			if i >= len(before) || missingInState(before[i], after[i]) {
+				return true
+			}
+		}
+		return false
+	default:
+		return before == nil
+	}
+}
+
+// literalExpression returns whether the tokens are an expression whose value
+// is known without evaluation context, e.g. not a reference, a variable or a
+// function call.
+func literalExpression(tokens hclwrite.Tokens) bool {
+	expr, diags := hclsyntax.ParseExpression(tokens.Bytes(), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return false
+	}
+	_, diags = expr.Value(nil)
+	return !diags.HasErrors()
+}
+
+// sameTokens returns whether the tokens are the same expression, ignoring the
+// spaces.
+func sameTokens(a, b hclwrite.Tokens) bool {
+	return bytes.Equal(bytes.TrimSpace(hclwrite.Format(a.Bytes())), bytes.TrimSpace(hclwrite.Format(b.Bytes())))
+}
+
+// stateValue converts the value of an attribute in the JSON plan to a cty
+// value of the type of the attribute.
+func stateValue(value any, schema *tfjson.SchemaAttribute) (cty.Value, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return cty.NilVal, err
+	}
+
+	valueType := schema.AttributeType
+	if valueType == cty.NilType {
+		// nested attributes, the type is implied from the value
+		valueType, err = ctyjson.ImpliedType(raw)
+		if err != nil {
+			return cty.NilVal, err
+		}
+	}
+	return ctyjson.Unmarshal(raw, valueType)
+}
+
+// addIgnoreChanges adds the attributes to the ignore_changes of the lifecycle
+// block of the resource and returns whether any was missing.
+func addIgnoreChanges(body *hclwrite.Body, attributes []string) bool {
+	lifecycle := body.FirstMatchingBlock("lifecycle", nil)
+	if lifecycle == nil {
+		body.AppendNewline()
+		lifecycle = body.AppendNewBlock("lifecycle", nil)
+	}
+
+	existing := []string{}
+	if ignoreChanges := lifecycle.Body().GetAttribute("ignore_changes"); ignoreChanges != nil {
+		for _, token := range ignoreChanges.Expr().BuildTokens(nil) {
+			if token.Type == hclsyntax.TokenIdent {
+				existing = append(existing, string(token.Bytes))
+			}
+		}
+	}
+
+	missing, _ := lo.Difference(lo.Uniq(attributes), existing)
+	if len(missing) == 0 {
+		return false
+	}
+
+	elems := lo.Map(append(existing, missing...), func(attribute string, _ int) hclwrite.Tokens {
+		return hclwrite.TokensForIdentifier(attribute)
+	})
+	lifecycle.Body().SetAttributeRaw("ignore_changes", hclwrite.TokensForTuple(elems))
+	return true
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const convergeTestConfig = `resource "dbtcloud_job" "terraform_managed_resource_2" {
+  name          = "Daily"
+  execute_steps = ["dbt build"]
+  project_id    = dbtcloud_project.terraform_managed_resource_1.id
+}
+
+resource "dbtcloud_global_connection" "terraform_managed_resource_3" {
+  name = "BigQuery"
+  bigquery = {
+    private_key = var.dbtcloud_global_connection_private_key_3
+  }
+}
+
+import {
+  to = dbtcloud_job.terraform_managed_resource_2
+  id = "2"
+}
+`
+
+const convergeTestPlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "dbtcloud_job.terraform_managed_resource_2",
+      "mode": "managed",
+      "type": "dbtcloud_job",
+      "name": "terraform_managed_resource_2",
+      "change": {
+        "actions": ["update"],
+        "before": {"id": "2", "job_id": 2, "name": "Daily", "project_id": 1, "execute_steps": ["dbt deps", "dbt build"], "triggers": {"schedule": true}, "timeout_seconds": 0},
+        "after": {"id": "2", "name": "Daily", "project_id": 5, "execute_steps": ["dbt build"], "triggers": {"schedule": false}, "timeout_seconds": null},
+        "after_unknown": {"job_id": true},
+        "importing": {"id": "2"}
+      }
+    },
+    {
+      "address": "dbtcloud_global_connection.terraform_managed_resource_3",
+      "mode": "managed",
+      "type": "dbtcloud_global_connection",
+      "name": "terraform_managed_resource_3",
+      "change": {
+        "actions": ["update"],
+        "before": {"id": 3, "name": "BigQuery", "bigquery": {"private_key": null}},
+        "after": {"id": 3, "name": "BigQuery", "bigquery": {"private_key": ""}},
+        "importing": {"id": "3"}
+      }
+    }
+  ]
+}`
+
+var convergeTestSchema = &tfjson.ProviderSchema{
+	ResourceSchemas: map[string]*tfjson.Schema{
+		"dbtcloud_job": {
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"id":              {AttributeType: cty.String, Computed: true},
+					"job_id":          {AttributeType: cty.Number, Computed: true},
+					"name":            {AttributeType: cty.String, Required: true},
+					"project_id":      {AttributeType: cty.Number, Required: true},
+					"execute_steps":   {AttributeType: cty.List(cty.String), Required: true},
+					"timeout_seconds": {AttributeType: cty.Number, Optional: true},
+				},
+				NestedBlocks: map[string]*tfjson.SchemaBlockType{
+					"triggers": {NestingMode: tfjson.SchemaNestingModeSingle, Block: &tfjson.SchemaBlock{}},
+				},
+			},
+		},
+		"dbtcloud_global_connection": {
+			Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"id":       {AttributeType: cty.Number, Computed: true},
+					"name":     {AttributeType: cty.String, Required: true},
+					"bigquery": {AttributeNestedType: &tfjson.SchemaNestedAttributeType{NestingMode: tfjson.SchemaNestingModeSingle}, Optional: true},
+				},
+			},
+		},
+	},
+}
+
+func TestConverge_ConvergeFiles(t *testing.T) {
+	defer func(dir string) { outputDir = dir }(outputDir)
+	outputDir = ""
+
+	var plan tfjson.Plan
+	require.NoError(t, json.Unmarshal([]byte(convergeTestPlan), &plan))
+
+	output := newOutputFiles()
+	output.file("").WriteString(convergeTestConfig)
+
+	rewritten, err := convergeFiles(output, &plan, convergeTestSchema)
+	require.NoError(t, err)
+	assert.True(t, rewritten)
+	assert.Equal(t, `resource "dbtcloud_job" "terraform_managed_resource_2" {
+  name            = "Daily"
+  execute_steps   = ["dbt deps", "dbt build"]
+  project_id      = dbtcloud_project.terraform_managed_resource_1.id
+  timeout_seconds = 0
+
+  lifecycle {
+    ignore_changes = [triggers]
+  }
+}
+
+resource "dbtcloud_global_connection" "terraform_managed_resource_3" {
+  name = "BigQuery"
+  bigquery = {
+    private_key = var.dbtcloud_global_connection_private_key_3
+  }
+
+  lifecycle {
+    ignore_changes = [bigquery]
+  }
+}
+
+import {
+  to = dbtcloud_job.terraform_managed_resource_2
+  id = "2"
+}
+`, output.file("").String())
+
+	// the same plan doesn't rewrite the config again
+	rewritten, err = convergeFiles(output, &plan, convergeTestSchema)
+	require.NoError(t, err)
+	assert.False(t, rewritten)
+}
+
+// TestConverge_LiteralExpression checks that only the literals are rewritten,
+// the project_id of the job above keeping its reference although it differs
+// in the state.
+func TestConverge_LiteralExpression(t *testing.T) {
+	for src, literal := range map[string]bool{
+		`"Daily"`:                   true,
+		`["dbt deps", "dbt build"]`: true,
+		`{ schedule = true }`:       true,
+		`dbtcloud_project.terraform_managed_resource_1.id`: false,
+		`var.dbtcloud_token`:                   false,
+		`local.deactivate_jobs ? false : true`: false,
+		`jsonencode({ a = 1 })`:                false,
+	} {
+		assert.Equal(t, literal, literalExpression(hclwrite.TokensForIdentifier(src)), src)
+	}
+}
+
+func TestConverge_AddIgnoreChanges(t *testing.T) {
+	defer func(dir string) { outputDir = dir }(outputDir)
+	outputDir = ""
+
+	output := newOutputFiles()
+	output.file("").WriteString(`resource "dbtcloud_job" "terraform_managed_resource_2" {
+  name = "Daily"
+
+  lifecycle {
+    ignore_changes = [triggers]
+  }
+}
+`)
+	plan := tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: "dbtcloud_job.terraform_managed_resource_2",
+				Mode:    tfjson.ManagedResourceMode,
+				Change: &tfjson.Change{
+					Actions: tfjson.Actions{tfjson.ActionUpdate},
+					Before:  map[string]any{"name": "Daily", "triggers": map[string]any{"schedule": true}},
+					After:   map[string]any{"name": "Daily", "triggers": map[string]any{"schedule": false}},
+				},
+			},
+		},
+	}
+
+	rewritten, err := convergeFiles(output, &plan, convergeTestSchema)
+	require.NoError(t, err)
+	assert.False(t, rewritten)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+)
+
+// fetchError is an error returned by the dbt Cloud API while fetching the
+// data for a resource type.
+type fetchError struct {
+	resourceType string
+	err          error
+}
+
+// runErrors collects the fetch errors of the current run. A failing resource
+// type (or a single failing project of a resource type) doesn't stop the run:
+// the rest of the config is still generated, and the errors are listed at the
+// end by printErrorSummary so that the process exits with a non-zero code.
+var runErrors []fetchError
+
+// runFailed is set by the commands whose result is a failure, e.g. audit
+// with findings of the severity error, for the process to exit with a
+// non-zero code after the summary of the errors.
+var runFailed bool
+
+// recordError adds err to the errors of the run if it is not nil, and logs it
+// straight away so that it shows up next to the resource type being
+// processed. It returns true if there was an error.
+func recordError(resourceType string, err error) bool {
+	if err == nil {
+		return false
+	}
+	log.Warnf("error fetching %s, the output will be incomplete: %v", resourceType, err)
+	runErrors = append(runErrors, fetchError{resourceType: resourceType, err: err})
+	return true
+}
+
+// printErrorSummary writes the list of errors recorded during the run and
+// returns the number of errors printed.
+//
+// The errors of the client looping through projects are joined with
+// errors.Join, we print them one per line. genimport runs generate and import
+// on the same data so the same error is recorded twice, we only print it once.
+func printErrorSummary(w io.Writer) int {
+	seen := map[string]bool{}
+	lines := []string{}
+	for _, runError := range runErrors {
+		errs := []error{runError.err}
+		if joinedErr, ok := runError.err.(interface{ Unwrap() []error }); ok {
+			errs = joinedErr.Unwrap()
+		}
+		for _, err := range errs {
+			line := fmt.Sprintf("  - %s: %v", runError.resourceType, err)
+			if seen[line] {
+				continue
+			}
+			seen[line] = true
+			lines = append(lines, line)
+		}
+	}
+
+	if len(lines) == 0 {
+		return 0
+	}
+
+	fmt.Fprintf(w, "\n%d error(s) while fetching data from dbt Cloud, the resources below are missing from the output:\n", len(lines))
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	return len(lines)
+}
@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/samber/lo"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var existingConfigDir string
+
+// configResource is a dbt Cloud resource of --existing-config-dir.
+type configResource struct {
+	address      string
+	resourceType string
+	attributes   hclsyntax.Attributes
+}
+
+// existingResources are the dbt Cloud resources of --existing-config-dir.
+var existingResources []configResource
+
+// readExistingConfig returns the dbt Cloud resources of the *.tf files of
+// dir. The resources with count or for_each are left out as their instances
+// can't be matched to items.
+func readExistingConfig(dir string) ([]configResource, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	resources := []configResource{}
+	for _, path := range paths {
+		file, diags := parser.ParseHCLFile(path)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+			if block.Type != "resource" || len(block.Labels) != 2 || !strings.HasPrefix(block.Labels[0], "dbtcloud_") {
+				continue
+			}
+			if block.Body.Attributes["count"] != nil || block.Body.Attributes["for_each"] != nil {
+				log.Debugf("%s.%s has count or for_each, it can't be matched", block.Labels[0], block.Labels[1])
+				continue
+			}
+			resources = append(resources, configResource{
+				address:      block.Labels[0] + "." + block.Labels[1],
+				resourceType: block.Labels[0],
+				attributes:   block.Body.Attributes,
+			})
+		}
+	}
+	return resources, nil
+}
+
+// skipExistingConfigItems removes from items the ones whose Identity matches a
+// resource of --existing-config-dir, and records its address so that the
+// resources linked to them reference it. The references of the existing
+// resources to the ones of other types, e.g. the project_id of an
+// environment, only match once the resource they point to is matched, so the
+// items are matched until no more match.
+func skipExistingConfigItems(handlers []ResourceHandler, items map[string][]any) {
+	if len(existingResources) == 0 {
+		return
+	}
+
+	// the Label of the item matched by the address of a resource
+	matched := map[string]string{}
+	for {
+		matchedBefore := len(matched)
+		for _, handler := range handlers {
+			resourceType := handler.ResourceType()
+			candidates := lo.Filter(existingResources, func(resource configResource, _ int) bool {
+				return resource.resourceType == resourceType
+			})
+			if len(candidates) == 0 {
+				continue
+			}
+
+			unmatched := lo.Filter(items[resourceType], func(item any, _ int) bool {
+				identity := handler.Identity(item)
+				if identity == nil {
+					return true
+				}
+				for _, resource := range candidates {
+					if _, ok := matched[resource.address]; !ok && resourceMatches(resource, identity, matched) {
+						matched[resource.address] = handler.Label(item)
+						addManagedLabel(resourceType, handler.Label(item), resource.address)
+						return false
+					}
+				}
+				return true
+			})
+			if skipped := len(items[resourceType]) - len(unmatched); skipped > 0 {
+				log.Infof("skipping %d %s already in %s", skipped, resourceType, existingConfigDir)
+			}
+			items[resourceType] = unmatched
+		}
+
+		if len(matched) == matchedBefore {
+			return
+		}
+	}
+}
+
+// resourceMatches returns whether the attributes of resource have the values
+// of identity.
+func resourceMatches(resource configResource, identity map[string]any, matched map[string]string) bool {
+	names := lo.Keys(identity)
+	sort.Strings(names)
+	for _, name := range names {
+		attribute, ok := resource.attributes[name]
+		if !ok {
+			return false
+		}
+		value, ok := configAttributeValue(attribute.Expr, matched)
+		if !ok || value != fmt.Sprint(identity[name]) {
+			return false
+		}
+	}
+	return true
+}
+
+// configAttributeValue returns the value of an attribute of an existing
+// resource: a literal value, or for a reference to another existing resource
+// the Label of the item it matched, e.g. the ID of the project for
+// dbtcloud_project.analytics.id. It returns false for the other expressions,
+// e.g. variables.
+func configAttributeValue(expr hclsyntax.Expression, matched map[string]string) (string, bool) {
+	if traversal, diags := hcl.AbsTraversalForExpr(expr); !diags.HasErrors() {
+		if len(traversal) < 2 {
+			return "", false
+		}
+		label, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			return "", false
+		}
+		value, ok := matched[traversal.RootName()+"."+label.Name]
+		return value, ok
+	}
+
+	value, diags := expr.Value(nil)
+	if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() {
+		return "", false
+	}
+	switch value.Type() {
+	case cty.String:
+		return value.AsString(), true
+	case cty.Number:
+		return value.AsBigFloat().Text('f', -1), true
+	case cty.Bool:
+		return fmt.Sprint(value.True()), true
+	default:
+		return "", false
+	}
+}
+
+// addManagedLabel records the address of the resource of the item of
+// resourceType with the Label key, see managedLabels.
+func addManagedLabel(resourceType, key, address string) {
+	if managedLabels == nil {
+		managedLabels = map[string]map[string]string{}
+	}
+	if managedLabels[resourceType] == nil {
+		managedLabels[resourceType] = map[string]string{}
+	}
+	managedLabels[resourceType][key] = address
+}
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const existingConfigTestFile = `
+resource "dbtcloud_project" "analytics" {
+  name = "Analytics"
+}
+
+resource "dbtcloud_environment" "prod" {
+  project_id = dbtcloud_project.analytics.id
+  name       = "Prod"
+  type       = "deployment"
+}
+
+resource "dbtcloud_job" "daily" {
+  environment_id = dbtcloud_environment.prod.environment_id
+  name           = "Daily"
+}
+
+resource "dbtcloud_job" "other" {
+  environment_id = var.environment_id
+  name           = "Hourly"
+}
+
+resource "dbtcloud_group" "analysts" {
+  for_each = toset(["Analysts"])
+  name     = each.key
+}
+
+resource "dbtcloud_global_connection" "snowflake" {
+  name = "Snowflake"
+}
+
+resource "null_resource" "other" {
+}
+`
+
+// TestExistingConfig_SkipExistingConfigItems checks that the items matching
+// the existing resources are skipped, including through the references of
+// the existing resources to each other, and that the references to them use
+// their address.
+func TestExistingConfig_SkipExistingConfigItems(t *testing.T) {
+	defer func(resources []configResource) {
+		existingResources = resources
+		managedLabels = nil
+	}(existingResources)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(existingConfigTestFile), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("not terraform"), 0644))
+
+	var err error
+	existingResources, err = readExistingConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"dbtcloud_project.analytics",
+		"dbtcloud_environment.prod",
+		"dbtcloud_job.daily",
+		"dbtcloud_job.other",
+		"dbtcloud_global_connection.snowflake",
+	}, lo.Map(existingResources, func(resource configResource, _ int) string { return resource.address }))
+
+	projects := []dbtcloud.Project{{ID: 1, Name: "Analytics"}, {ID: 2, Name: "Marketing"}}
+	environments := []dbtcloud.Environment{
+		{ID: 10, ProjectID: 1, Name: "Prod"},
+		{ID: 20, ProjectID: 2, Name: "Prod"},
+	}
+	jobs := []dbtcloud.Job{
+		{ID: 100, EnvironmentID: 10, Name: "Daily"},
+		{ID: 200, EnvironmentID: 20, Name: "Daily"},
+		{ID: 300, EnvironmentID: 10, Name: "Hourly"},
+	}
+	items := map[string][]any{
+		"dbtcloud_job":         lo.ToAnySlice(jobs),
+		"dbtcloud_environment": lo.ToAnySlice(environments),
+		"dbtcloud_project":     lo.ToAnySlice(projects),
+	}
+	handlers, err := selectedHandlers([]string{"dbtcloud_job", "dbtcloud_environment", "dbtcloud_project"}, "test")
+	require.NoError(t, err)
+
+	skipExistingConfigItems(handlers, items)
+
+	assert.Equal(t, lo.ToAnySlice(projects[1:]), items["dbtcloud_project"])
+	assert.Equal(t, lo.ToAnySlice(environments[1:]), items["dbtcloud_environment"])
+	assert.Equal(t, lo.ToAnySlice(jobs[1:]), items["dbtcloud_job"])
+
+	assert.Equal(t, "dbtcloud_project.analytics.id", resourceReference("dbtcloud_project", 1, "id").String())
+	assert.Equal(t, "dbtcloud_environment.prod.environment_id", resourceReference("dbtcloud_environment", 10, "environment_id").String())
+	assert.Equal(t, "dbtcloud_environment.terraform_managed_resource_20.environment_id", resourceReference("dbtcloud_environment", 20, "environment_id").String())
+}
+
+func TestExistingConfig_ReadExistingConfigError(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "dbtcloud_project" "analytics" {`), 0644))
+
+	_, err := readExistingConfig(dir)
+	assert.Error(t, err)
+}
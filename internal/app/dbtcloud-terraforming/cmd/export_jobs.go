@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	rootCmd.AddCommand(exportJobsCmd)
+}
+
+var jobsAsCodeVars bool
+
+var exportJobsCmd = &cobra.Command{
+	Use:   "export-jobs",
+	Short: "Output the jobs in the dbt-jobs-as-code YAML format",
+	Long: `Fetches the jobs (of the projects of --projects if set) and outputs them in the YAML format of dbt-jobs-as-code,
+with the linked_id of each job so that dbt-jobs-as-code can take over the existing jobs with its link command.
+The jobs are identified by import_<job ID>, or by their names with --label-strategy name.
+With --jobs-as-code-vars, the account, project and environment IDs are replaced with variables, written to vars.yml
+next to jobs.yml in --output-dir, to be used with the --vars-yml option of dbt-jobs-as-code.`,
+	Run:    runExportJobs(),
+	PreRun: sharedPreRun,
+}
+
+// jobAsCode is a job in the dbt-jobs-as-code YAML format, the fields are in
+// the order dbt-jobs-as-code writes them. The IDs are any as they can be
+// templated variables.
+type jobAsCode struct {
+	LinkedID                      int                           `yaml:"linked_id"`
+	AccountID                     any                           `yaml:"account_id"`
+	ProjectID                     any                           `yaml:"project_id"`
+	EnvironmentID                 any                           `yaml:"environment_id"`
+	DbtVersion                    *string                       `yaml:"dbt_version"`
+	Name                          string                        `yaml:"name"`
+	Settings                      jobAsCodeSettings             `yaml:"settings"`
+	Execution                     jobAsCodeExecution            `yaml:"execution"`
+	DeferringEnvironmentID        any                           `yaml:"deferring_environment_id"`
+	RunGenerateSources            bool                          `yaml:"run_generate_sources"`
+	ExecuteSteps                  []string                      `yaml:"execute_steps"`
+	GenerateDocs                  bool                          `yaml:"generate_docs"`
+	Schedule                      jobAsCodeSchedule             `yaml:"schedule"`
+	Triggers                      jobAsCodeTriggers             `yaml:"triggers"`
+	Description                   string                        `yaml:"description"`
+	RunCompareChanges             bool                          `yaml:"run_compare_changes"`
+	CompareChangesFlags           string                        `yaml:"compare_changes_flags,omitempty"`
+	JobType                       string                        `yaml:"job_type"`
+	TriggersOnDraftPR             bool                          `yaml:"triggers_on_draft_pr"`
+	JobCompletionTriggerCondition *jobAsCodeCompletionCondition `yaml:"job_completion_trigger_condition,omitempty"`
+	CustomEnvironmentVariables    []map[string]string           `yaml:"custom_environment_variables,omitempty"`
+}
+
+type jobAsCodeSettings struct {
+	Threads    int    `yaml:"threads"`
+	TargetName string `yaml:"target_name"`
+}
+
+type jobAsCodeExecution struct {
+	TimeoutSeconds int `yaml:"timeout_seconds"`
+}
+
+type jobAsCodeSchedule struct {
+	Cron string `yaml:"cron"`
+}
+
+type jobAsCodeTriggers struct {
+	GithubWebhook      bool `yaml:"github_webhook"`
+	GitProviderWebhook bool `yaml:"git_provider_webhook"`
+	Schedule           bool `yaml:"schedule"`
+	OnMerge            bool `yaml:"on_merge"`
+}
+
+type jobAsCodeCompletionCondition struct {
+	Condition struct {
+		JobID     int   `yaml:"job_id"`
+		ProjectID any   `yaml:"project_id"`
+		Statuses  []int `yaml:"statuses"`
+	} `yaml:"condition"`
+}
+
+func runExportJobs() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if jobsAsCodeVars && outputDir == "" {
+			log.Fatal("--jobs-as-code-vars requires --output-dir, to write the vars.yml file next to jobs.yml")
+		}
+
+		listFilterProjects = viper.GetIntSlice("projects")
+
+		ctx := cmd.Context()
+		jobHandler := resourceHandlers["dbtcloud_job"]
+		data := prefetchAccountData(ctx, []ResourceHandler{jobHandler})
+		var err error
+		data.jobs, err = dbtCloudClient.GetJobs(ctx, listFilterProjects)
+		recordError("dbtcloud_job", err)
+		overrides, err := dbtCloudClient.GetEnvironmentVariableJobOverrides(ctx, listFilterProjects, data.jobs)
+		recordError("dbtcloud_environment_variable_job_override", err)
+
+		assignResourceLabels(jobHandler, lo.ToAnySlice(data.jobs), data)
+
+		files, err := jobsAsCodeFiles(data, overrides)
+		if err != nil {
+			log.Fatalf("failed to write jobs: %v", err)
+		}
+		if err := files.write(); err != nil {
+			log.Fatalf("failed to write jobs: %v", err)
+		}
+	}
+}
+
+// jobsAsCodeFiles returns the jobs in the dbt-jobs-as-code format, in
+// jobs.yml, and with --jobs-as-code-vars the values of their variables, in
+// vars.yml.
+func jobsAsCodeFiles(data *accountData, overrides []dbtcloud.EnvironmentVariableJobOverride) (*outputFiles, error) {
+	vars := newJobsAsCodeVariables(data)
+
+	overridesByJob := lo.GroupBy(overrides, func(override dbtcloud.EnvironmentVariableJobOverride) int {
+		return override.JobDefinitionID
+	})
+
+	jobs := map[string]jobAsCode{}
+	for _, job := range data.jobs {
+		jobs[jobIdentifier(job)] = jobToJobAsCode(job, overridesByJob[job.ID], vars)
+	}
+
+	content, err := marshalYAML(map[string]any{"jobs": jobs})
+	if err != nil {
+		return nil, err
+	}
+
+	files := newOutputFiles()
+	files.file("jobs.yml").Write(vars.unquote(content))
+	if len(vars.values) > 0 {
+		content, err := marshalYAML(vars.values)
+		if err != nil {
+			return nil, err
+		}
+		files.file("vars.yml").Write(content)
+	}
+	return files, nil
+}
+
+// marshalYAML returns value as YAML indented with 2 spaces, like the files of
+// dbt-jobs-as-code.
+func marshalYAML(value any) ([]byte, error) {
+	var b bytes.Buffer
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// jobIdentifier returns the identifier of the job for dbt-jobs-as-code:
+// import_<job ID> like dbt-jobs-as-code import-jobs, or the label of the job
+// with --label-strategy name.
+func jobIdentifier(job dbtcloud.Job) string {
+	key := fmt.Sprint(job.ID)
+	if label, ok := resourceLabels["dbtcloud_job"][key]; ok {
+		return label
+	}
+	return "import_" + key
+}
+
+func jobToJobAsCode(job dbtcloud.Job, overrides []dbtcloud.EnvironmentVariableJobOverride, vars *jobsAsCodeVariables) jobAsCode {
+	// the fields missing from dbtcloud.Job are read from the payload
+	rawBool := func(name string) bool {
+		value, _ := job.Raw[name].(bool)
+		return value
+	}
+	compareChangesFlags, _ := job.Raw["compare_changes_flags"].(string)
+
+	jobAsCode := jobAsCode{
+		LinkedID:            job.ID,
+		AccountID:           vars.account(),
+		ProjectID:           vars.project(job.ProjectID),
+		EnvironmentID:       vars.environment(job.EnvironmentID),
+		DbtVersion:          job.DbtVersion,
+		Name:                job.Name,
+		Settings:            jobAsCodeSettings{Threads: job.Settings.Threads, TargetName: job.Settings.TargetName},
+		Execution:           jobAsCodeExecution{TimeoutSeconds: job.Execution.TimeoutSeconds},
+		RunGenerateSources:  rawBool("run_generate_sources"),
+		ExecuteSteps:        job.ExecuteSteps,
+		GenerateDocs:        rawBool("generate_docs"),
+		Schedule:            jobAsCodeSchedule{Cron: jobCron(job.Schedule)},
+		Triggers:            jobAsCodeTriggers(job.Triggers),
+		Description:         job.Description,
+		RunCompareChanges:   rawBool("run_compare_changes"),
+		CompareChangesFlags: compareChangesFlags,
+		JobType:             job.JobType,
+		TriggersOnDraftPR:   rawBool("triggers_on_draft_pr"),
+	}
+	if job.DeferringEnvironmentID != nil {
+		jobAsCode.DeferringEnvironmentID = vars.environment(*job.DeferringEnvironmentID)
+	}
+	if job.JobCompletionTriggerCondition != nil && job.JobCompletionTriggerCondition.Condition != nil {
+		condition := job.JobCompletionTriggerCondition.Condition
+		jobAsCode.JobCompletionTriggerCondition = &jobAsCodeCompletionCondition{}
+		jobAsCode.JobCompletionTriggerCondition.Condition.JobID = condition.JobID
+		jobAsCode.JobCompletionTriggerCondition.Condition.ProjectID = vars.project(condition.ProjectID)
+		jobAsCode.JobCompletionTriggerCondition.Condition.Statuses = condition.Statuses
+	}
+	for _, override := range overrides {
+		jobAsCode.CustomEnvironmentVariables = append(jobAsCode.CustomEnvironmentVariables, map[string]string{override.Name: override.RawValue})
+	}
+	return jobAsCode
+}
+
+// jobCron returns the cron expression of the schedule, dbt-jobs-as-code
+// only supporting those. It is built from the days and the hours of the
+// schedule when the API doesn't return it, e.g. 0 */4 * * 1,2 every 4 hours
+// on Mondays and Tuesdays.
+func jobCron(schedule dbtcloud.JobSchedule) string {
+	if schedule.Cron != "" {
+		return schedule.Cron
+	}
+	if schedule.Date.Type == "custom_cron" || schedule.Date.Type == "interval_cron" {
+		return schedule.Date.Cron
+	}
+
+	hours := "*"
+	switch {
+	case schedule.Time.Type == "at_exact_hours" && len(schedule.Time.Hours) > 0:
+		hours = joinInts(schedule.Time.Hours)
+	case schedule.Time.Type == "every_hour" && schedule.Time.Interval > 1:
+		hours = fmt.Sprintf("*/%d", schedule.Time.Interval)
+	}
+	days := "*"
+	if schedule.Date.Type == "days_of_week" && len(schedule.Date.Days) > 0 {
+		days = joinInts(schedule.Date.Days)
+	}
+	return fmt.Sprintf("0 %s * * %s", hours, days)
+}
+
+func joinInts(values []int) string {
+	return strings.Join(lo.Map(values, func(value int, _ int) string { return fmt.Sprint(value) }), ",")
+}
+
+// jobsAsCodeVariables are the templated variables replacing the account,
+// project and environment IDs with --jobs-as-code-vars, e.g.
+// {{ analytics__prod_environment_id }}. The variables are named after the
+// projects and the environments, with their IDs when the names are missing or
+// repeat.
+type jobsAsCodeVariables struct {
+	accountID    string
+	projects     map[int]string
+	environments map[int]string
+	names        map[string]bool
+	values       map[string]any
+}
+
+func newJobsAsCodeVariables(data *accountData) *jobsAsCodeVariables {
+	vars := &jobsAsCodeVariables{
+		accountID:    dbtCloudClient.AccountID,
+		projects:     map[int]string{},
+		environments: map[int]string{},
+		names:        map[string]bool{},
+		values:       map[string]any{},
+	}
+	projectNames := lo.SliceToMap(data.projects, func(project dbtcloud.Project) (int, string) {
+		return project.ID, labelName(project.Name)
+	})
+	for _, project := range data.projects {
+		vars.projects[project.ID] = vars.name(projectNames[project.ID], "project_id", project.ID)
+	}
+	for _, environment := range data.environments {
+		name := labelName(environment.Name)
+		if projectName := projectNames[environment.ProjectID]; projectName != "" && name != "" {
+			name = projectName + "__" + strings.TrimPrefix(name, "_")
+		}
+		vars.environments[environment.ID] = vars.name(name, "environment_id", environment.ID)
+	}
+	return vars
+}
+
+// name returns a new variable name, e.g. analytics_project_id, or
+// project_id_123 when the name is empty or already taken.
+func (v *jobsAsCodeVariables) name(name, suffix string, id int) string {
+	variable := strings.TrimPrefix(name+"_"+suffix, "_")
+	if name == "" || v.names[variable] {
+		variable = fmt.Sprintf("%s_%d", suffix, id)
+	}
+	v.names[variable] = true
+	return variable
+}
+
+// value returns the ID as is, or the reference to its variable with
+// --jobs-as-code-vars. The IDs without a variable, e.g. of the environments
+// of other projects, are kept.
+func (v *jobsAsCodeVariables) value(variable string, id any) any {
+	if !jobsAsCodeVars || variable == "" {
+		return id
+	}
+	v.values[variable] = id
+	return fmt.Sprintf("{{ %s }}", variable)
+}
+
+func (v *jobsAsCodeVariables) account() any {
+	// the account ID is a number for dbt-jobs-as-code
+	if accountID, err := strconv.Atoi(v.accountID); err == nil {
+		return v.value("account_id", accountID)
+	}
+	return v.value("account_id", v.accountID)
+}
+
+func (v *jobsAsCodeVariables) project(id int) any {
+	return v.value(v.projects[id], id)
+}
+
+func (v *jobsAsCodeVariables) environment(id int) any {
+	return v.value(v.environments[id], id)
+}
+
+// unquote removes the quotes around the references to the variables in the
+// YAML content: dbt-jobs-as-code renders the variables before parsing the
+// YAML, and the IDs must be numbers.
+func (v *jobsAsCodeVariables) unquote(content []byte) []byte {
+	names := lo.Keys(v.values)
+	sort.Strings(names)
+	for _, name := range names {
+		content = bytes.ReplaceAll(content, []byte(fmt.Sprintf("'{{ %s }}'", name)), []byte(fmt.Sprintf("{{ %s }}", name)))
+	}
+	return content
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportJobs_YAML(t *testing.T) {
+	withTestClient(t)
+	files, err := jobsAsCodeFiles(fabricatedAccountData(), []dbtcloud.EnvironmentVariableJobOverride{
+		{ID: 5, Name: "DBT_TARGET", ProjectID: 10, JobDefinitionID: 1, RawValue: "nightly"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{""}, files.names)
+	content := files.file("").String()
+	assert.Contains(t, content, "jobs:\n  import_1:\n    linked_id: 1\n    account_id: 9999\n    project_id: 10\n    environment_id: 100\n")
+	assert.Contains(t, content, "    deferring_environment_id: 101\n")
+	assert.Contains(t, content, "    generate_docs: true\n")
+	assert.Contains(t, content, "    schedule:\n      cron: 0 2,14 * * 1,2\n")
+	assert.Contains(t, content, "    custom_environment_variables:\n      - DBT_TARGET: nightly\n")
+	assert.NotContains(t, content, "job_completion_trigger_condition")
+}
+
+// TestExportJobs_Vars checks that the IDs are replaced with variables named
+// after the projects and the environments, with the ID of the environments
+// whose names repeat.
+func TestExportJobs_Vars(t *testing.T) {
+	defer func() {
+		jobsAsCodeVars = false
+		outputDir = ""
+	}()
+	jobsAsCodeVars = true
+	outputDir = "jobs"
+
+	withTestClient(t)
+	files, err := jobsAsCodeFiles(fabricatedAccountData(), []dbtcloud.EnvironmentVariableJobOverride{
+		{ID: 5, Name: "DBT_TARGET", ProjectID: 10, JobDefinitionID: 1, RawValue: "nightly"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"jobs.yml", "vars.yml"}, files.names)
+	jobs := files.file("jobs.yml").String()
+	assert.Contains(t, jobs, "    account_id: {{ account_id }}\n    project_id: {{ analytics_project_id }}\n    environment_id: {{ analytics__prod_environment_id }}\n")
+	assert.Contains(t, jobs, "    deferring_environment_id: {{ environment_id_101 }}\n")
+	assert.Equal(t, "account_id: 9999\nanalytics__prod_environment_id: 100\nanalytics_project_id: 10\nenvironment_id_101: 101\n", files.file("vars.yml").String())
+}
+
+func TestExportJobs_Identifier(t *testing.T) {
+	defer func() { resourceLabels = map[string]map[string]string{} }()
+	resourceLabels = map[string]map[string]string{"dbtcloud_job": {"1": "analytics__nightly"}}
+
+	assert.Equal(t, "analytics__nightly", jobIdentifier(dbtcloud.Job{ID: 1}))
+	assert.Equal(t, "import_2", jobIdentifier(dbtcloud.Job{ID: 2}))
+}
+
+func TestExportJobs_Cron(t *testing.T) {
+	assert.Equal(t, "0 * * * *", jobCron(dbtcloud.JobSchedule{Date: dbtcloud.JobScheduleDate{Type: "every_day"}, Time: dbtcloud.JobScheduleTime{Type: "every_hour"}}))
+	assert.Equal(t, "0 * * * *", jobCron(dbtcloud.JobSchedule{Date: dbtcloud.JobScheduleDate{Type: "every_day"}, Time: dbtcloud.JobScheduleTime{Type: "every_hour", Interval: 1}}))
+	assert.Equal(t, "0 */4 * * 1,2", jobCron(dbtcloud.JobSchedule{Date: dbtcloud.JobScheduleDate{Type: "days_of_week", Days: []int{1, 2}}, Time: dbtcloud.JobScheduleTime{Type: "every_hour", Interval: 4}}))
+	assert.Equal(t, "5 4 * * *", jobCron(dbtcloud.JobSchedule{Date: dbtcloud.JobScheduleDate{Type: "custom_cron", Cron: "5 4 * * *"}}))
+	assert.Equal(t, "0 6 * * *", jobCron(dbtcloud.JobSchedule{Cron: "0 6 * * *", Date: dbtcloud.JobScheduleDate{Type: "every_day"}}))
+}
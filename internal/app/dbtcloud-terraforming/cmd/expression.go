@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// hclExpression is a value of the generated config written as an expression
+// instead of a literal, e.g. a reference to another resource or a variable.
+// It can be used anywhere a literal can, including in lists and maps.
+type hclExpression struct {
+	tokens hclwrite.Tokens
+}
+
+// String returns the expression as written in the config.
+func (e hclExpression) String() string {
+	return string(hclwrite.Format(e.tokens.Bytes()))
+}
+
+// hclReference returns the expression referencing the object at path, e.g.
+// dbtcloud_project.terraform_managed_resource_123.id or var.token. It panics
+// if path is not a valid traversal, as the paths are built by the tool.
+func hclReference(path string) hclExpression {
+	traversal, diags := hclsyntax.ParseTraversalAbs([]byte(path), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		panic(fmt.Sprintf("invalid reference %q: %s", path, diags.Error()))
+	}
+	return hclExpression{tokens: hclwrite.TokensForTraversal(traversal)}
+}
+
+// hclRaw returns an arbitrary expression, e.g. a conditional or a for
+// expression. It panics if expr is not a valid expression, as the
+// expressions are built by the tool.
+func hclRaw(expr string) hclExpression {
+	file, diags := hclwrite.ParseConfig([]byte("expr = "+expr+"\n"), "", hcl.InitialPos)
+	if diags.HasErrors() {
+		panic(fmt.Sprintf("invalid expression %q: %s", expr, diags.Error()))
+	}
+	return hclExpression{tokens: file.Body().GetAttribute("expr").Expr().BuildTokens(nil)}
+}
+
+// hclJSONEncode returns the call to jsonencode of the JSON document raw
+// written as HCL, e.g. jsonencode({ type = "snowflake" }), so that its
+// structure can be reviewed. The keys of the objects are sorted.
+func hclJSONEncode(raw []byte) (hclExpression, error) {
+	valueType, err := ctyjson.ImpliedType(raw)
+	if err != nil {
+		return hclExpression{}, err
+	}
+	value, err := ctyjson.Unmarshal(raw, valueType)
+	if err != nil {
+		return hclExpression{}, err
+	}
+	return hclExpression{tokens: hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForValue(value))}, nil
+}
+
+// valueTokens returns the tokens of a value of the generated config: a
+// literal, an hclExpression, or a list or a map of them, in any combination.
+// The keys of the maps are sorted. It returns false for the values of other
+// types and for nil.
+func valueTokens(value any) (hclwrite.Tokens, bool) {
+	switch value := value.(type) {
+	case nil:
+		return nil, false
+	case hclExpression:
+		return value.tokens, true
+	case string:
+		return hclwrite.TokensForValue(cty.StringVal(value)), true
+	case int:
+		return hclwrite.TokensForValue(cty.NumberIntVal(int64(value))), true
+	case float64:
+		return hclwrite.TokensForValue(cty.NumberFloatVal(value)), true
+	case bool:
+		return hclwrite.TokensForValue(cty.BoolVal(value)), true
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		attrs := []hclwrite.ObjectAttrTokens{}
+		for _, key := range keys {
+			tokens, ok := valueTokens(value[key])
+			if !ok {
+				continue
+			}
+			attrs = append(attrs, hclwrite.ObjectAttrTokens{Name: objectKeyTokens(key), Value: tokens})
+		}
+		return hclwrite.TokensForObject(attrs), true
+	}
+
+	// the lists and maps of other types, e.g. []string or []hclExpression
+	switch reflected := reflect.ValueOf(value); reflected.Kind() {
+	case reflect.Slice:
+		elems := []hclwrite.Tokens{}
+		for i := 0; i < reflected.Len(); i++ {
+			if tokens, ok := valueTokens(reflected.Index(i).Interface()); ok {
+				elems = append(elems, tokens)
+			}
+		}
+		return hclwrite.TokensForTuple(elems), true
+	case reflect.Map:
+		if reflected.Type().Key().Kind() != reflect.String {
+			return nil, false
+		}
+		values := map[string]any{}
+		for _, key := range reflected.MapKeys() {
+			values[key.String()] = reflected.MapIndex(key).Interface()
+		}
+		return valueTokens(values)
+	}
+	return nil, false
+}
+
+// objectKeyTokens returns the key of an object, quoted when it is not a valid
+// identifier, e.g. for the names of the environments in environment_values.
+func objectKeyTokens(key string) hclwrite.Tokens {
+	if hclsyntax.ValidIdentifier(key) {
+		return hclwrite.TokensForIdentifier(key)
+	}
+	return hclwrite.TokensForValue(cty.StringVal(key))
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/briandowns/spinner"
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(fetchCmd)
+}
+
+var fetchCmd = &cobra.Command{
+	Use:   "fetch",
+	Short: "Fetch the dbt Cloud API payloads needed by generate and import and save them in a snapshot file",
+	Long: `Fetch calls the dbt Cloud API for all the selected resource types (all of them if --resource-types is not set)
+and saves the responses in a versioned JSON snapshot file. The snapshot can then be passed to generate, import
+and genimport with --from-snapshot to work offline from a fixed copy of the account.`,
+	Run:    runFetch(),
+	PreRun: sharedPreRun,
+}
+
+func runFetch() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if fromSnapshot != "" {
+			log.Fatal("--from-snapshot can't be used with fetch")
+		}
+
+		if outputFile != "" {
+			spin := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(cmd.OutOrStderr()))
+			spin.Suffix = " Downloading resources and saving snapshot\n"
+			spin.Start()
+			defer spin.Stop()
+		}
+
+		if len(resourceTypes) == 0 || (len(resourceTypes) == 1 && resourceTypes[0] == "all") {
+			resourceTypes = resourceTypeNames()
+		}
+
+		if len(excludeResourceTypes) > 0 {
+			resourceTypes = lo.Filter(resourceTypes, func(resourceType string, _ int) bool {
+				return !lo.Contains(excludeResourceTypes, resourceType)
+			})
+		}
+
+		listFilterProjects = viper.GetIntSlice("projects")
+
+		snapshot := dbtcloud.NewSnapshot(dbtCloudClient.HostURL, dbtCloudClient.AccountID, listFilterProjects)
+		dbtCloudClient.Client.Transport = dbtcloud.NewRecordingTransport(dbtCloudClient.Client.Transport, snapshot)
+
+		handlers, err := selectedHandlers(resourceTypes, "fetching")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// the same calls as in generate and import, the responses are shared
+		// by multiple resource types
+		ctx := cmd.Context()
+		data := prefetchAccountData(ctx, handlers)
+
+		// failed calls are not in the snapshot, the errors are listed at the end
+		// of the run and replaying the snapshot will report the same URLs
+		for _, handler := range handlers {
+			log.Debugf("fetching the payloads for %s", handler.ResourceType())
+			_, err := handler.Fetch(ctx, data)
+			recordError(handler.ResourceType(), err)
+		}
+
+		writer, closer, err := getOutputWriter()
+		if err != nil {
+			log.Fatalf("failed to write snapshot: %v", err)
+		}
+		defer closer()
+
+		if err := snapshot.Write(writer); err != nil {
+			log.Fatalf("failed to write snapshot: %v", err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+// withTestClient points the client to the account 9999 of cloud.getdbt.com
+// for the duration of a test, the URLs of the objects are built from it. The
+// API is not called, see fakeAPI for the tests fetching data.
+func withTestClient(t *testing.T) {
+	t.Helper()
+	origClient := dbtCloudClient
+	dbtCloudClient = dbtcloud.NewDbtCloudHTTPClient("https://cloud.getdbt.com/api", "token", "9999", nil)
+	t.Cleanup(func() {
+		dbtCloudClient = origClient
+	})
+}
+
+// fabricatedAccountData returns the account data shared by the tests: the
+// project 10 "Analytics" with its environments 100 and 101, both named
+// "Prod", and the job 1 "Nightly", scheduled in 100 and deferring to 101.
+// The tests change the returned data for their own cases.
+func fabricatedAccountData() *accountData {
+	job := dbtcloud.Job{ID: 1, ProjectID: 10, EnvironmentID: 100, DeferringEnvironmentID: lo.ToPtr(101), Name: "Nightly", JobType: "scheduled", ExecuteSteps: []string{"dbt build"}}
+	job.Raw = map[string]any{"generate_docs": true}
+	job.Settings = dbtcloud.JobSettings{Threads: 4, TargetName: "prod"}
+	job.Schedule.Date = dbtcloud.JobScheduleDate{Type: "days_of_week", Days: []int{1, 2}}
+	job.Schedule.Time = dbtcloud.JobScheduleTime{Type: "at_exact_hours", Hours: []int{2, 14}}
+	job.Triggers = dbtcloud.JobTriggers{Schedule: true}
+
+	return &accountData{
+		projects: []dbtcloud.Project{{ID: 10, Name: "Analytics", RepositoryID: lo.ToPtr(1)}},
+		environments: []dbtcloud.Environment{
+			{ID: 100, ProjectID: 10, Name: "Prod", CredentialsID: lo.ToPtr(1000), ConnectionID: lo.ToPtr(50)},
+			{ID: 101, ProjectID: 10, Name: "Prod"},
+		},
+		jobs: []dbtcloud.Job{job},
+	}
+}
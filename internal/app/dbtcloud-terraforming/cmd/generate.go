@@ -1,31 +1,22 @@
 package cmd
 
 import (
-	"context"
-	"encoding/json"
-	"os"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
-	"github.com/gosimple/slug"
-	"github.com/hashicorp/go-version"
-	"github.com/hashicorp/hc-install/product"
-	"github.com/hashicorp/hc-install/releases"
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
-	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/zclconf/go-cty/cty"
 
 	"fmt"
 )
 
 var resourceTypes, listLinkedResources, excludeResourceTypes []string
-var prefixNoQuotes = "~no-quotes~"
 
 func init() {
 	rootCmd.AddCommand(generateCmd)
@@ -45,1514 +36,370 @@ type tfVar struct {
 }
 
 var AllTFVars = []tfVar{}
-var AllLocals = map[string]string{}
+var AllLocals = map[string]any{}
 
-// defaultGroups are the built-in dbt platform groups that are never generated
-// as dbtcloud_group resources (their membership is managed by dbt platform
-// itself, not Terraform). Any other resource that references groups (e.g.
-// dbtcloud_user_groups) must exclude these from its output to avoid dangling
-// references to resources that are never created.
-var defaultGroups = []string{"Owner", "Member", "Everyone"}
-
-// buildGroupIDToNameMap indexes a list of raw group payloads (as returned by
-// dbtCloudClient.GetGroups()) by their numeric ID, so callers can resolve a
-// group ID to its name (e.g. to check whether it's a default group).
-func buildGroupIDToNameMap(listGroups []any) map[int]string {
-	groupIDToName := map[int]string{}
-	for _, group := range listGroups {
-		groupTyped := group.(map[string]any)
-		groupIDToName[int(groupTyped["id"].(float64))] = groupTyped["name"].(string)
-	}
-	return groupIDToName
-}
-
-// isDefaultGroupID reports whether groupID resolves (via groupIDToName) to
-// one of the built-in default groups (Owner/Member/Everyone). If the ID
-// isn't found in the map, it is treated as not default.
-func isDefaultGroupID(groupID int, groupIDToName map[int]string) bool {
-	name, ok := groupIDToName[groupID]
-	if !ok {
-		return false
-	}
-	return lo.Contains(defaultGroups, name)
-}
-
-// filterOutDefaultGroupIDs returns groupIDs with any built-in default group
-// (Owner/Member/Everyone) removed. Those groups are never generated as
-// dbtcloud_group resources, so any resource referencing group IDs must
-// exclude them to avoid dangling references (when linked) or attempting to
-// manage membership dbt platform itself controls (when not linked).
-func filterOutDefaultGroupIDs(groupIDs []int, groupIDToName map[int]string) []int {
-	filtered := []int{}
-	for _, groupID := range groupIDs {
-		if isDefaultGroupID(groupID, groupIDToName) {
-			continue
-		}
-		filtered = append(filtered, groupID)
-	}
-	return filtered
-}
+// generatedProviderSchema is the schema of the provider the config is
+// generated for, see formatConfig.
+var generatedProviderSchema *tfjson.ProviderSchema
 
 func linkResource(resourceType string) bool {
 	if len(listLinkedResources) == 0 {
 		return false
 	}
+	// the lookups of the users by email rely on count and for expressions,
+	// Pulumi YAML has neither
+	if resourceType == "users_by_email" && target == "pulumi-yaml" {
+		return false
+	}
 	return lo.Contains(listLinkedResources, resourceType) || listLinkedResources[0] == "all"
 }
 
-// computeResourceLabel derives the `terraform_managed_resource_<suffix>` label
-// used for a generated `resource "..." "..."` block.
-//
-// If resourceIDOverride is non-empty, it is used directly as the suffix - this
-// is how a resource's `case` above opts a resource out of id-based labelling,
-// which is needed for resources that aren't keyed by a single numeric/string
-// id (e.g. dbtcloud_account_features, a singleton with no per-item id).
-//
-// Otherwise the suffix is derived from structData["id"], exactly as it always
-// has been for every other (list-based, id-keyed) resource type.
-func computeResourceLabel(resourceType string, structData map[string]interface{}, resourceIDOverride string) string {
-	if resourceIDOverride != "" {
-		return fmt.Sprintf("terraform_managed_resource_%s", resourceIDOverride)
-	}
+// linkCredentials reports whether any of the credential resource types is
+// linked.
+func linkCredentials() bool {
+	return linkResource("dbtcloud_snowflake_credential") || linkResource("dbtcloud_bigquery_credential") || linkResource("dbtcloud_databricks_credential")
+}
 
-	id := ""
-	switch structData["id"].(type) {
-	case float64:
-		id = fmt.Sprintf("%.0f", structData["id"].(float64))
-	case nil:
-		panic(fmt.Sprintf("There is no `id` defined for the resource %s", resourceType))
-	default:
-		id = structData["id"].(string)
+// credentialReference returns the reference to the credential resource of
+// credentialID, whose resource type depends on the type of its credentials.
+// The environments and profiles embed their credentials for this purpose,
+// when they don't or when the type is not supported yet the reference is
+// left to be filled by hand.
+func credentialReference(credentials *dbtcloud.Credential, credentialID int) any {
+	if credentials == nil {
+		return "---TBD---"
 	}
 
-	return fmt.Sprintf("terraform_managed_resource_%s", id)
+	if resourceType := credentialResourceType(credentials); resourceType != "" {
+		return resourceReference(resourceType, credentialID, "credential_id")
+	}
+	return fmt.Sprintf("---TBD---credential type not supported yet for %s---", credentials.AdapterVersion)
 }
 
-// transformEnvironmentForGenerate applies the dbtcloud_environment-specific
-// generate-time transforms to a single environment payload: optionally
-// linking project_id to a generated dbtcloud_project resource, and - the
-// core either/or invariant - binding the environment to either a profile
-// (via primary_profile_id) or the legacy connection_id/credential_id/
-// extended_attributes_id trio, but never both. The provider rejects setting
-// primary_profile_id alongside any of the legacy trio, so whichever
-// primary_profile_id is present on the payload decides which branch runs,
-// and the branch not taken is guaranteed absent from the result.
-//
-// It mutates and returns environmentsTyped in place, matching the mutate-in-
-// place style used by every other resource case in generateResources().
-func transformEnvironmentForGenerate(environmentsTyped map[string]any) map[string]any {
-	projectID := environmentsTyped["project_id"].(float64)
-
-	if linkResource("dbtcloud_project") {
-		environmentsTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
+// credentialResourceType returns the resource type of the credentials, or ""
+// if they are not supported yet.
+func credentialResourceType(credentials *dbtcloud.Credential) string {
+	if credentials == nil {
+		return ""
 	}
-
-	if primaryProfileID, hasProfile := environmentsTyped["primary_profile_id"].(float64); hasProfile {
-		if linkResource("dbtcloud_profile") {
-			environmentsTyped["primary_profile_id"] = fmt.Sprintf("%sdbtcloud_profile.terraform_managed_resource_%0.f_%0.f.profile_id", prefixNoQuotes, projectID, primaryProfileID)
-		}
-
-		// omit the legacy trio entirely - both the raw API field names and the
-		// "credential_id" rename performed in the non-profile branch below.
-		delete(environmentsTyped, "connection_id")
-		delete(environmentsTyped, "credentials_id")
-		delete(environmentsTyped, "credential_id")
-		delete(environmentsTyped, "extended_attributes_id")
-	} else {
-		// handle the case when credentialID is not a float because it is null
-		if credentialID, ok := environmentsTyped["credentials_id"].(float64); ok {
-
-			environmentsTyped["credential_id"] = credentialID
-			if linkResource("dbtcloud_snowflake_credential") || linkResource("dbtcloud_bigquery_credential") || linkResource("dbtcloud_databricks_credential") {
-
-				credentials, credentialsOK := environmentsTyped["credentials"].(map[string]any)
-
-				if credentialsOK {
-					credentialsType := credentials["type"].(string)
-					adapterVersion := credentials["adapter_version"].(string)
-
-					if lo.Contains([]string{"snowflake", "bigquery"}, credentialsType) {
-						environmentsTyped["credential_id"] = fmt.Sprintf("%sdbtcloud_%s_credential.terraform_managed_resource_%0.f.credential_id", prefixNoQuotes, credentialsType, credentialID)
-					} else if adapterVersion == "databricks_v0" {
-						environmentsTyped["credential_id"] = fmt.Sprintf("%sdbtcloud_databricks_credential.terraform_managed_resource_%0.f.credential_id", prefixNoQuotes, credentialID)
-					} else {
-						environmentsTyped["credential_id"] = fmt.Sprintf("---TBD---credential type not supported yet for %s---", adapterVersion)
-					}
-
-				} else {
-					environmentsTyped["credential_id"] = "---TBD---"
-				}
-			}
-		}
-		if linkResource("dbtcloud_global_connection") {
-			connectionID := environmentsTyped["connection_id"].(float64)
-			environmentsTyped["connection_id"] = fmt.Sprintf("%sdbtcloud_global_connection.terraform_managed_resource_%0.f.id", prefixNoQuotes, connectionID)
-		}
-
-		// handle the case when extended_attributes_id is not set
-		if extendedAttributesID, ok := environmentsTyped["extended_attributes_id"].(float64); ok {
-			if linkResource("dbtcloud_extended_attributes") {
-				environmentsTyped["extended_attributes_id"] = fmt.Sprintf("%sdbtcloud_extended_attributes.terraform_managed_resource_%0.f.extended_attributes_id", prefixNoQuotes, extendedAttributesID)
-			}
-		}
+	if lo.Contains([]string{"snowflake", "bigquery"}, credentials.Type) {
+		return fmt.Sprintf("dbtcloud_%s_credential", credentials.Type)
+	} else if credentials.AdapterVersion == "databricks_v0" {
+		return "dbtcloud_databricks_credential"
 	}
-
-	return environmentsTyped
+	return ""
 }
 
-// transformProfileForGenerate applies the dbtcloud_profile-specific
-// generate-time transforms to a single profile payload: it folds project_id
-// into the "id" field (see the resourceIDOverride discussion on the
-// dbtcloud_profile case above for why - profile_id is only unique within a
-// project, not across the account), keeps the plain numeric profile id
-// separately as "profile_id" for the resource's own attribute, and
-// optionally links project_id/connection_id/credentials_id/
-// extended_attributes_id to their generated counterparts, mirroring
-// transformEnvironmentForGenerate's linking logic exactly.
-//
-// It mutates and returns profileTyped in place, matching the mutate-in-place
-// style used by every other resource case in generateResources().
-func transformProfileForGenerate(profileTyped map[string]any) map[string]any {
-	projectID := profileTyped["project_id"].(float64)
-	profileID := profileTyped["id"].(float64)
+// credentialLabel and credentialImportID are shared by the credential
+// resource types, which are all keyed by project.
+func credentialLabel(credential dbtcloud.Credential) string {
+	return fmt.Sprintf("%d", credential.ID)
+}
 
-	profileTyped["profile_id"] = profileID
-	profileTyped["id"] = fmt.Sprintf("%.0f_%.0f", projectID, profileID)
+func credentialImportID(credential dbtcloud.Credential) string {
+	return fmt.Sprintf("%d:%d", credential.ProjectID, credential.ID)
+}
 
-	if linkResource("dbtcloud_project") {
-		profileTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
+// credentialInventory and credentialURL are shared by the credential
+// resource types, whose page is the one of the environment using them, if
+// any.
+func credentialInventory(credential dbtcloud.Credential, _ *accountData) []inventoryColumn {
+	return []inventoryColumn{
+		column("warehouse_type", credential.WarehouseType()),
+		column("auth_type", credential.AuthType),
+		column("environment_id", lo.EmptyableToPtr(credential.EnvironmentID)),
 	}
+}
 
-	if connectionID, ok := profileTyped["connection_id"].(float64); ok {
-		if linkResource("dbtcloud_global_connection") {
-			profileTyped["connection_id"] = fmt.Sprintf("%sdbtcloud_global_connection.terraform_managed_resource_%0.f.id", prefixNoQuotes, connectionID)
-		}
+func credentialURL(credential dbtcloud.Credential) string {
+	if credential.EnvironmentID == 0 {
+		return ""
 	}
+	return deployURL("projects/%d/environments/%d/settings/", credential.ProjectID, credential.EnvironmentID)
+}
 
-	// handle the case when credentialsID is not a float because it is null
-	if credentialsID, ok := profileTyped["credentials_id"].(float64); ok {
-		if linkResource("dbtcloud_snowflake_credential") || linkResource("dbtcloud_bigquery_credential") || linkResource("dbtcloud_databricks_credential") {
-
-			credentials, credentialsOK := profileTyped["credentials"].(map[string]any)
-
-			if credentialsOK {
-				credentialsType := credentials["type"].(string)
-				adapterVersion := credentials["adapter_version"].(string)
-
-				if lo.Contains([]string{"snowflake", "bigquery"}, credentialsType) {
-					profileTyped["credentials_id"] = fmt.Sprintf("%sdbtcloud_%s_credential.terraform_managed_resource_%0.f.credential_id", prefixNoQuotes, credentialsType, credentialsID)
-				} else if adapterVersion == "databricks_v0" {
-					profileTyped["credentials_id"] = fmt.Sprintf("%sdbtcloud_databricks_credential.terraform_managed_resource_%0.f.credential_id", prefixNoQuotes, credentialsID)
-				} else {
-					profileTyped["credentials_id"] = fmt.Sprintf("---TBD---credential type not supported yet for %s---", adapterVersion)
-				}
-
-			} else {
-				profileTyped["credentials_id"] = "---TBD---"
-			}
+func generateResources() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		files, _ := generateConfig(cmd)
+		if files == nil {
+			return
 		}
-	}
 
-	// handle the case when extended_attributes_id is not set
-	if extendedAttributesID, ok := profileTyped["extended_attributes_id"].(float64); ok {
-		if linkResource("dbtcloud_extended_attributes") {
-			profileTyped["extended_attributes_id"] = fmt.Sprintf("%sdbtcloud_extended_attributes.terraform_managed_resource_%0.f.extended_attributes_id", prefixNoQuotes, extendedAttributesID)
+		files, err := formatConfig(files)
+		if err != nil {
+			log.Fatalf("failed to write output: %v", err)
+		}
+		if err := files.write(); err != nil {
+			log.Fatalf("failed to write output: %v", err)
 		}
 	}
-
-	return profileTyped
 }
 
-// transformJobCompletionTriggerForGenerate builds the jsonStructData entry
-// for a single dbtcloud_job_completion_trigger, from the
-// job_completion_trigger_condition field already present on a dbtcloud_job
-// payload (see the dbtcloud_job case in generateResources(), which parses
-// this same field for its own, separate purposes).
-//
-// It returns (nil, false) when the job carries no completion trigger
-// condition at all, so callers can skip it without generating an empty
-// resource.
-//
-// The real dbtcloud_job_completion_trigger resource schema is flat - job_id
-// (the downstream job this trigger is attached to), trigger_job_id (the
-// upstream job whose completion fires it), project_id, and statuses - with
-// no nested "condition" block, confirmed against the provider's schema
-// source. Its id is just the downstream job's plain numeric id (matching the
-// provider's ImportState, which parses the import id directly as job_id),
-// so no composite-id folding is needed here, unlike
-// transformProfileForGenerate.
-func transformJobCompletionTriggerForGenerate(jobTyped map[string]any) (map[string]any, bool) {
-	jobCompletionTrigger, ok := jobTyped["job_completion_trigger_condition"].(map[string]any)
-	if !ok {
-		return nil, false
+// generateConfig returns the generated config of the resource types selected
+// and the account data it is generated from, or nil if they can't be
+// generated.
+func generateConfig(cmd *cobra.Command) (*outputFiles, *fetchedAccount) {
+	ctx := cmd.Context()
+
+	if outputFile != "" || outputDir != "" {
+		spin := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(cmd.OutOrStderr()))
+		spin.Suffix = " Downloading resources and generating config\n"
+		spin.Color("purple")
+		spin.Start()
+		defer spin.Stop()
 	}
-	condition, ok := jobCompletionTrigger["condition"].(map[string]any)
-	if !ok {
-		return nil, false
-	}
-
-	downstreamJobID := jobTyped["id"].(float64)
-	upstreamProjectID := condition["project_id"].(float64)
-	upstreamJobID := condition["job_id"].(float64)
 
-	triggerData := map[string]any{
-		"id":             downstreamJobID,
-		"job_id":         downstreamJobID,
-		"trigger_job_id": upstreamJobID,
-		"project_id":     upstreamProjectID,
-		"statuses":       mapJobStatusCodeToText(condition["statuses"].([]any)),
+	if len(resourceTypes) == 0 {
+		log.Fatal("you must define at least one --resource-types to generate the config")
 	}
 
-	if linkResource("dbtcloud_job") {
-		triggerData["job_id"] = fmt.Sprintf("%sdbtcloud_job.terraform_managed_resource_%0.f.id", prefixNoQuotes, downstreamJobID)
-		triggerData["trigger_job_id"] = fmt.Sprintf("%sdbtcloud_job.terraform_managed_resource_%0.f.id", prefixNoQuotes, upstreamJobID)
-	}
-	if linkResource("dbtcloud_project") {
-		triggerData["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, upstreamProjectID)
+	if len(resourceTypes) == 1 && resourceTypes[0] == "all" {
+		resourceTypes = resourceTypeNames()
 	}
 
-	return triggerData, true
-}
-
-// transformEnvironmentVariableJobOverrideForGenerate applies the
-// dbtcloud_environment_variable_job_override-specific generate-time
-// transforms to a single override payload (as returned by
-// GetEnvironmentVariableJobOverrides): it folds project_id/job_definition_id/
-// the override's own numeric id into "id" (mirroring transformProfileForGenerate's
-// approach, since environment_variable_job_override_id is only meaningful
-// together with the project/job scope it lives in), externalizes the value
-// of a secret-named override (DBT_ENV_SECRET_ prefix) to a Terraform
-// variable exactly as the existing dbtcloud_environment_variable case does,
-// and optionally links job_definition_id/project_id to their generated
-// counterparts.
-//
-// It mutates and returns overrideTyped in place, matching the mutate-in-place
-// style used by every other resource transform in this file.
-func transformEnvironmentVariableJobOverrideForGenerate(overrideTyped map[string]any) map[string]any {
-	projectID := overrideTyped["project_id"].(float64)
-	jobDefinitionID := overrideTyped["job_definition_id"].(float64)
-	overrideID := overrideTyped["environment_variable_job_override_id"].(float64)
-	envVarName := overrideTyped["name"].(string)
-
-	overrideTyped["id"] = fmt.Sprintf("%.0f_%.0f_%.0f", projectID, jobDefinitionID, overrideID)
-
-	// mirror the DBT_ENV_SECRET_ externalization pattern used by the
-	// existing dbtcloud_environment_variable case exactly: a secret-named
-	// override's value is registered as a Terraform variable and
-	// substituted with a var.<name> reference instead of being emitted
-	// inline.
-	if strings.HasPrefix(envVarName, "DBT_ENV_SECRET_") {
-		targetURL := fmt.Sprintf("%s/deploy/%s/projects/%.0f/jobs/%.0f/settings/", dbtCloudClient.HostURL[:len(dbtCloudClient.HostURL)-4], dbtCloudClient.AccountID, projectID, jobDefinitionID)
-		varName := fmt.Sprintf("dbtcloud_environment_variable_job_override_%.0f_%.0f_%s", projectID, jobDefinitionID, slug.Make(envVarName))
-		AllTFVars = append(AllTFVars, tfVar{
-			varType:        "string",
-			varName:        varName,
-			varDescription: "The secret env var override for " + envVarName + " on job " + fmt.Sprintf("%.0f", jobDefinitionID) + " in the project " + fmt.Sprintf("%.0f", projectID) + " - " + targetURL,
+	if len(excludeResourceTypes) > 0 {
+		resourceTypes = lo.Filter(resourceTypes, func(resourceType string, _ int) bool {
+			return !lo.Contains(excludeResourceTypes, resourceType)
 		})
-		overrideTyped["raw_value"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
 	}
 
-	if linkResource("dbtcloud_job") {
-		overrideTyped["job_definition_id"] = fmt.Sprintf("%sdbtcloud_job.terraform_managed_resource_%0.f.id", prefixNoQuotes, jobDefinitionID)
-	}
-	if linkResource("dbtcloud_project") {
-		overrideTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
+	handlers, err := selectedHandlers(resourceTypes, "automatic generation")
+	if err != nil {
+		fmt.Fprint(cmd.OutOrStderr(), err)
+		return nil, nil
 	}
 
-	return overrideTyped
-}
-
-func generateResources() func(cmd *cobra.Command, args []string) {
-	return func(cmd *cobra.Command, args []string) {
-		if outputFile != "" {
-			spin := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(cmd.OutOrStderr()))
-			spin.Suffix = " Downloading resources and generating config\n"
-			spin.Color("purple")
-			spin.Start()
-			defer spin.Stop()
-		}
-
-		if len(resourceTypes) == 0 {
-			log.Fatal("you must define at least one --resource-types to generate the config")
-		}
-
-		if len(resourceTypes) == 1 && resourceTypes[0] == "all" {
-			resourceTypes = lo.Keys(resourceImportStringFormats)
-		}
-
-		if len(excludeResourceTypes) > 0 {
-			resourceTypes = lo.Filter(resourceTypes, func(resourceType string, _ int) bool {
-				return !lo.Contains(excludeResourceTypes, resourceType)
-			})
-		}
-
-		listFilterProjects = viper.GetIntSlice("projects")
-
-		var execPath, workingDir string
-		workingDir = viper.GetString("terraform-install-path")
-		execPath = viper.GetString("terraform-binary-path")
-
-		//Download terraform if no existing binary was provided
-		if execPath == "" {
-			tmpDir, err := os.MkdirTemp("", "tfinstall")
-			if err != nil {
-				log.Fatal(err)
-			}
-			defer os.RemoveAll(tmpDir)
-
-			installConstraints, err := version.NewConstraint("~> 1.0")
-			if err != nil {
-				log.Fatal("failed to parse version constraints for installation version")
-			}
+	s, providerVersion, err := loadProviderSchema(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	generatedProviderSchema = s
 
-			installer := &releases.LatestVersion{
-				Product:     product.Terraform,
-				Constraints: installConstraints,
-			}
+	// Create the HCL files for the output
+	files := newHCLFiles()
+	writeRequiredProviders(files.body("providers.tf"), providerVersion)
+	if outputDir != "" {
+		writeProviderConfig(files.body("providers.tf"))
+	}
 
-			execPath, err = installer.Install(context.Background())
-			if err != nil {
-				log.Fatalf("error installing Terraform: %s", err)
-			}
+	handlers = lo.Filter(handlers, func(handler ResourceHandler, _ int) bool {
+		r := s.ResourceSchemas[handler.ResourceType()]
+		if r == nil || r.Block == nil {
+			log.Debugf("skipping %s: resource type not found in provider schema", handler.ResourceType())
+			return false
 		}
+		return true
+	})
+	// the resources are generated after the ones they depend on
+	handlers = sortHandlersByDependencies(handlers)
 
-		// Setup and configure Terraform to operate in the temporary directory where
-		// the provider is already configured.
-		log.Debugf("initializing Terraform in %s", workingDir)
-		tf, err := tfexec.NewTerraform(workingDir, execPath)
-		if err != nil {
-			log.Fatal(err)
-		}
+	account := fetchAccount(ctx, handlers)
+	data, resources := account.data, account.resources
 
-		log.Debug("reading Terraform schema for dbt Cloud provider")
-		ps, err := tf.ProvidersSchema(context.Background())
-		if err != nil {
-			log.Fatal("failed to read provider schema", err)
-		}
-		s := ps.Schemas["registry.terraform.io/dbt-labs/dbtcloud"]
-		if s == nil {
-			log.Fatal("failed to detect provider installation")
-		}
+	// Process each resource and add to the HCL file
+	for _, handler := range handlers {
+		resourceType := handler.ResourceType()
+		r := s.ResourceSchemas[resourceType]
+		log.Debugf("beginning to build %s resources", resourceType)
 
-		// Create a new empty HCL file for the output
-		f := hclwrite.NewEmptyFile()
-		rootBody := f.Body()
+		items := sortItemsByLinks(handler, resources[resourceType], data)
 
-		// PRE-FETCHING RESOURCES
-		// set some variables like jobs and projects oustide of the for loop so that we can reference them in other resources
-		// and make sure we remove jobs/projects that no longer exist but are still associated with other resources
-
-		// we always get all projects
-		prefetchedProjects := dbtCloudClient.GetProjects(listFilterProjects)
-		prefetchedProjectsIDs := lo.Map(prefetchedProjects, func(project any, index int) int {
-			return int(project.(map[string]any)["id"].(float64))
-		})
-
-		// we only get jobs if we need them, there might be a lot of them
-		prefetchedJobs := []any{}
-		resourceNeedingJobs := []string{"dbtcloud_job", "dbtcloud_webhook", "dbtcloud_notification", "dbtcloud_job_completion_trigger", "dbtcloud_environment_variable_job_override"}
-		if len(lo.Intersect(resourceTypes, resourceNeedingJobs)) > 0 {
-			prefetchedJobs = dbtCloudClient.GetJobs(listFilterProjects)
+		// If we don't have any resources to generate, just bail out early.
+		if len(items) == 0 {
+			fmt.Fprintf(cmd.OutOrStderr(), "# no resources of type %q found to generate\n", resourceType)
+			continue
 		}
-		prefetchedJobsIDs := lo.Map(prefetchedJobs, func(job any, index int) int {
-			return int(job.(map[string]any)["id"].(float64))
-		})
 
-		// the dbtcloud_job case below mutates each job's own
-		// job_completion_trigger_condition field in place for its own
-		// purposes (jobs and prefetchedJobs share the same underlying map
-		// objects). We extract the completion-trigger data for
-		// dbtcloud_job_completion_trigger here, before that mutation can
-		// happen, so its output doesn't depend on whether/when "dbtcloud_job"
-		// is processed in the same invocation.
-		prefetchedJobCompletionTriggers := []any{}
-		for _, job := range prefetchedJobs {
-			jobTyped := job.(map[string]any)
-			if triggerData, ok := transformJobCompletionTriggerForGenerate(jobTyped); ok {
-				prefetchedJobCompletionTriggers = append(prefetchedJobCompletionTriggers, triggerData)
-			}
-		}
-		// we need this because our API for webhooks returns job IDs as strings
-		prefetchedJobsIDsString := lo.Map(prefetchedJobsIDs, func(jobID int, index int) string {
-			return fmt.Sprintf("%d", jobID)
-		})
+		for _, item := range items {
+			structData := handler.Transform(item, data)
 
-		resourceNeedingUsers := []string{"dbtcloud_notification", "dbtcloud_user_groups"}
-		prefetchedUsers := []any{}
-		if len(lo.Intersect(resourceTypes, resourceNeedingUsers)) > 0 {
-			prefetchedUsers = dbtCloudClient.GetUsers()
-		}
-		prefetchedMapUserIDsEmails := make(map[float64]string)
-		for _, user := range prefetchedUsers {
-			userTyped := user.(map[string]any)
-			prefetchedMapUserIDsEmails[userTyped["id"].(float64)] = userTyped["email"].(string)
-		}
+			rootBody := files.body(resourceFileName(handler, item))
+			resource := rootBody.AppendNewBlock("resource", []string{resourceType, resourceLabel(handler, item)}).Body()
 
-		// Process each resource and add to the HCL file
-		for _, resourceType := range resourceTypes {
-			r := s.ResourceSchemas[resourceType]
-			if r == nil || r.Block == nil {
-				log.Debugf("skipping %s: resource type not found in provider schema", resourceType)
-				continue
+			sortedBlockAttributes := make([]string, 0, len(r.Block.Attributes))
+			for k := range r.Block.Attributes {
+				sortedBlockAttributes = append(sortedBlockAttributes, k)
 			}
-			log.Debugf("beginning to read and build %s resources", resourceType)
-
-			// Initialise `resourceCount` outside of the switch for supported resources
-			// to allow it to be referenced further down in the loop that outputs the
-			// newly generated resources.
-			resourceCount := 0
-
-			// resourceIDOverride lets a `case` below set the resource label used in
-			// the generated `resource "..." "..."` block directly, instead of it being
-			// derived from `structData["id"]`. This is needed for resources that
-			// aren't keyed by a single numeric/string id - e.g. singletons like
-			// dbtcloud_account_features, which have exactly one instance per account
-			// and no `id` field of their own.
-			resourceIDOverride := ""
-
-			var jsonStructData []any
-
-			switch resourceType {
-			case "dbtcloud_project":
-
-				jsonStructData = prefetchedProjects
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_job":
-
-				jobs := prefetchedJobs
-
-				for _, job := range jobs {
-					jobTyped := job.(map[string]any)
-
-					jobSettings := jobTyped["settings"].(map[string]any)
-					jobTyped["num_threads"] = jobSettings["threads"].(float64)
-					jobTyped["target_name"] = jobSettings["target_name"].(string)
-
-					jobExecution := jobTyped["execution"].(map[string]any)
-					jobTyped["timeout_seconds"] = jobExecution["timeout_seconds"].(float64)
-
-					jobSchedule := jobTyped["schedule"].(map[string]any)
-					jobScheduleDate := jobSchedule["date"].(map[string]any)
-					jobTyped["schedule_type"] = jobScheduleDate["type"].(string)
-
-					if jobTyped["schedule_type"] == "custom_cron" {
-						jobTyped["schedule_cron"] = jobScheduleDate["cron"].(string)
-					}
-					if jobTyped["schedule_type"] == "interval_cron" {
-						jobTyped["schedule_type"] = "custom_cron"
-						jobTyped["schedule_cron"] = jobScheduleDate["cron"].(string)
-					}
-					if jobTyped["schedule_type"] == "days_of_week" {
-						jobTyped["schedule_days"] = jobScheduleDate["days"]
-
-						jobScheduleTime := jobSchedule["time"].(map[string]any)
-						if jobScheduleTime["type"].(string) == "at_exact_hours" {
-							jobTyped["schedule_hours"] = jobScheduleTime["hours"]
-						}
-
-						// TODO: Handle the case when this is every x hours
-					}
-
-					jobTriggers := jobTyped["triggers"].(map[string]any)
-
-					// we allow deactivating jobs based on a local variable
-					var triggers map[string]any
-					if parameterizeJobs {
-						triggers = map[string]any{
-							"github_webhook":       fmt.Sprintf("%slocal.deactivate_jobs_pr ? false : %t", prefixNoQuotes, getBool(jobTriggers["github_webhook"])),
-							"git_provider_webhook": fmt.Sprintf("%slocal.deactivate_jobs_pr ? false : %t", prefixNoQuotes, getBool(jobTriggers["git_provider_webhook"])),
-							"schedule":             fmt.Sprintf("%slocal.deactivate_jobs_schedule ? false : %t", prefixNoQuotes, getBool(jobTriggers["schedule"])),
-							"on_merge":             fmt.Sprintf("%slocal.deactivate_jobs_merge ? false : %t", prefixNoQuotes, getBool(jobTriggers["on_merge"])),
-						}
-					} else {
-						triggers = map[string]any{
-							"github_webhook":       getBool(jobTriggers["github_webhook"]),
-							"git_provider_webhook": getBool(jobTriggers["git_provider_webhook"]),
-							"schedule":             getBool(jobTriggers["schedule"]),
-							"on_merge":             getBool(jobTriggers["on_merge"]),
-						}
-					}
-
-					jobTyped["triggers"] = triggers
-
-					if linkResource("dbtcloud_environment") {
-						environmentID := jobTyped["environment_id"].(float64)
-						jobTyped["environment_id"] = fmt.Sprintf("%sdbtcloud_environment.terraform_managed_resource_%0.f.environment_id", prefixNoQuotes, environmentID)
-
-						// handle the case when deferring_environment_id is not set
-						if deferringEnvironmentID, ok := jobTyped["deferring_environment_id"].(float64); ok {
-							jobTyped["deferring_environment_id"] = fmt.Sprintf("%sdbtcloud_environment.terraform_managed_resource_%0.f.environment_id", prefixNoQuotes, deferringEnvironmentID)
-						}
-					}
-					if linkResource("dbtcloud_project") {
-						projectID := jobTyped["project_id"].(float64)
-						jobTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-
-					jobCompletionTrigger, ok := jobTyped["job_completion_trigger_condition"].(map[string]any)
-					// if it is not null and actually a map
-					if ok {
-						jobCompletionTriggerCondition := jobCompletionTrigger["condition"].(map[string]any)
-
-						projectID := jobCompletionTriggerCondition["project_id"].(float64)
-						jobID := jobCompletionTriggerCondition["job_id"].(float64)
-
-						completionTriggers := map[string]any{
-							"job_id":     jobID,
-							"project_id": projectID,
-							"statuses":   mapJobStatusCodeToText(jobCompletionTriggerCondition["statuses"].([]any)),
-						}
-
-						if linkResource("dbtcloud_job") {
-							completionTriggers["job_id"] = fmt.Sprintf("%sdbtcloud_job.terraform_managed_resource_%0.f.id", prefixNoQuotes, jobID)
-						}
-
-						if linkResource("dbtcloud_project") {
-							completionTriggers["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-						}
-
-						jobTyped["job_completion_trigger_condition"] = completionTriggers
-					}
-
-					jsonStructData = append(jsonStructData, jobTyped)
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_environment":
-
-				listEnvironments := dbtCloudClient.GetEnvironments(listFilterProjects)
-
-				for _, environment := range listEnvironments {
-					environmentsTyped := environment.(map[string]any)
-					jsonStructData = append(jsonStructData, transformEnvironmentForGenerate(environmentsTyped))
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_repository":
-
-				listRepositories := dbtCloudClient.GetRepositories(listFilterProjects)
-
-				for _, repository := range listRepositories {
-					repositoryTyped := repository.(map[string]any)
-
-					projectID := repositoryTyped["project_id"].(float64)
-					if githubInstallationID, ok := repositoryTyped["github_installation_id"].(float64); ok {
-
-						varName := fmt.Sprintf("dbtcloud_repository_github_installation_id_%0.f", githubInstallationID)
-						// we only add the variable if it doesn't already exist
-						allVarNames := lo.Map(AllTFVars, func(i tfVar, _ int) string { return i.varName })
-						if !lo.Contains(allVarNames, varName) {
-							AllTFVars = append(AllTFVars, tfVar{
-								varType:        "number",
-								varName:        varName,
-								varDescription: "The new GitHub installation ID for the existing installation ID " + fmt.Sprintf("%0.f", githubInstallationID),
-							})
-						}
-						repositoryTyped["github_installation_id"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-					}
-					if linkResource("dbtcloud_project") {
-						repositoryTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-					jsonStructData = append(jsonStructData, repositoryTyped)
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_project_repository":
-
-				listProjects := dbtCloudClient.GetProjects(listFilterProjects)
-
-				for _, project := range listProjects {
-					projectTyped := project.(map[string]any)
-					projectID := projectTyped["id"].(float64)
-					projectTyped["project_id"] = projectID
-					repositoryID := projectTyped["repository_id"]
-
-					if linkResource("dbtcloud_project") {
-						projectID := projectTyped["project_id"]
-						projectTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-					if linkResource("dbtcloud_repository") {
-						projectTyped["repository_id"] = fmt.Sprintf("%sdbtcloud_repository.terraform_managed_resource_%0.f.repository_id", prefixNoQuotes, repositoryID)
-					}
-					if repositoryID != nil {
-						jsonStructData = append(jsonStructData, projectTyped)
-					}
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_environment_variable":
-
-				mapEnvVars := dbtCloudClient.GetEnvironmentVariables(listFilterProjects)
-				listEnvVars := []any{}
-
-				cacheEnvs := []any{}
-				// if we want to dynamically link dbtcloud_environment, we need to cache the environments so that we can map them in depends_on
-				if linkResource("dbtcloud_environment") {
-					cacheEnvs = dbtCloudClient.GetEnvironments(listFilterProjects)
-				}
-
-				for projectID, envVars := range mapEnvVars {
-					for envVarName, envVarValues := range envVars.(map[string]any) {
-						envDetails := map[string]any{}
-						envDetails["name"] = envVarName
-						envDetails["id"] = fmt.Sprintf("%d_%s", projectID, envVarName)
-						envDetails["project_id"] = projectID
-
-						if linkResource("dbtcloud_project") {
-							envDetails["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%d.id", prefixNoQuotes, projectID)
-						}
-
-						// we need to make int a map[string]any to work with the matching strategy
-						collectEnvValues := map[string]any{}
-
-						envVarValuesTyped := envVarValues.(map[string]any)
-						listEnvNames := []string{}
-						for envName, envValues := range envVarValuesTyped {
-
-							if envName != "project" {
-								listEnvNames = append(listEnvNames, envName)
-							}
-
-							if envValues != nil {
-								envValuesTyped := envValues.(map[string]any)
-								collectEnvValues[envName] = envValuesTyped["value"].(string)
-
-								targetURL := fmt.Sprintf("%s/deploy/%s/projects/%d/environments/", dbtCloudClient.HostURL[:len(dbtCloudClient.HostURL)-4], dbtCloudClient.AccountID, projectID)
-								if strings.HasPrefix(envVarName, "DBT_ENV_SECRET_") {
-									varName := fmt.Sprintf("dbtcloud_environment_variable_%d_%s_%s", projectID, envVarName, slug.Make(envName))
-									AllTFVars = append(AllTFVars, tfVar{
-										varType:        "string",
-										varName:        varName,
-										varDescription: "The secret env var for " + envVarName + " in the environment " + envName + " in the project " + fmt.Sprintf("%d", projectID) + " - " + targetURL,
-									})
-									collectEnvValues[envName] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-								}
-							}
-						}
-
-						if linkResource("dbtcloud_environment") {
-							matchingEnvs := lo.Filter(cacheEnvs, func(i any, index int) bool {
-								typedEnv := i.(map[string]any)
-								sameProject := typedEnv["project_id"].(float64) == float64(projectID)
-								envNameInList := lo.Contains(listEnvNames, (i.(map[string]any)["name"].(string)))
-								return sameProject && envNameInList
-							})
-
-							listDependsOn := []string{}
-							for _, matchingEnv := range matchingEnvs {
-								listDependsOn = append(listDependsOn, fmt.Sprintf("%sdbtcloud_environment.terraform_managed_resource_%0.f", prefixNoQuotes, matchingEnv.(map[string]any)["id"].(float64)))
-							}
-							envDetails["depends_on"] = listDependsOn
-						}
-						envDetails["environment_values"] = collectEnvValues
-
-						listEnvVars = append(listEnvVars, envDetails)
-					}
-				}
-
-				jsonStructData = listEnvVars
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_snowflake_credential":
-				listCredentials := dbtCloudClient.GetSnowflakeCredentials(listFilterProjects)
-
-				for _, credential := range listCredentials {
-					credentialTyped := credential.(map[string]any)
-
-					projectID := credentialTyped["project_id"].(float64)
-					credentialID := credentialTyped["id"].(float64)
-					environmentID := credentialTyped["environment_id"].(float64)
-					credentialTyped["num_threads"] = credentialTyped["threads"]
-
-					targetURL := fmt.Sprintf("%s/deploy/%s/projects/%0.f/environments/%0.f/settings/", dbtCloudClient.HostURL[:len(dbtCloudClient.HostURL)-4], dbtCloudClient.AccountID, projectID, environmentID)
-					switch credentialTyped["auth_type"] {
-					case "password":
-						varName := fmt.Sprintf("dbtcloud_snowflake_credential_password_%0.f", credentialID)
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varName,
-							varDescription: "The password for the snowflake credential " + fmt.Sprintf("%0.f", credentialID) + " - " + targetURL,
-						})
-						credentialTyped["password"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-					case "keypair":
-						varName := fmt.Sprintf("dbtcloud_snowflake_credential_private_key_%0.f", credentialID)
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varName,
-							varDescription: "The private key for the snowflake credential " + fmt.Sprintf("%0.f", credentialID) + " - " + targetURL,
-						})
-						credentialTyped["private_key"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-						varNamePassphrase := fmt.Sprintf("dbtcloud_snowflake_credential_private_key_passphrase_%0.f", credentialID)
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varNamePassphrase,
-							varDescription: "The passphrase for the snowflake credential " + fmt.Sprintf("%0.f", credentialID) + " - " + targetURL,
-						})
-						credentialTyped["private_key_passphrase"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varNamePassphrase)
-					}
-
-					if linkResource("dbtcloud_project") {
-						credentialTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-					jsonStructData = append(jsonStructData, credentialTyped)
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_databricks_credential":
-				listCredentials := dbtCloudClient.GetDatabricksCredentials(listFilterProjects)
-
-				for _, credential := range listCredentials {
-					credentialTyped := credential.(map[string]any)
-
-					credentialDetails, err := dbtCloudClient.GetCredential(int64(credentialTyped["project_id"].(float64)), int64(credentialTyped["id"].(float64)))
-					if err != nil {
-						log.Fatal(err)
-					}
-					for key, value := range credentialDetails.(map[string]any)["unencrypted_credential_details"].(map[string]any) {
-						credentialTyped[key] = value
-					}
-
-					// we remove the adapter_id as we will use global connections
-					credentialTyped["adapter_id"] = ""
-
-					projectID := credentialTyped["project_id"].(float64)
-					credentialID := credentialTyped["id"].(float64)
-					environmentID := credentialTyped["environment_id"].(float64)
-					credentialTyped["adapter_type"] = "databricks"
-
-					targetURL := fmt.Sprintf("%s/deploy/%s/projects/%0.f/environments/%0.f/settings/", dbtCloudClient.HostURL[:len(dbtCloudClient.HostURL)-4], dbtCloudClient.AccountID, projectID, environmentID)
-					varName := fmt.Sprintf("dbtcloud_databricks_credential_token_%0.f", credentialID)
-					AllTFVars = append(AllTFVars, tfVar{
-						varType:        "string",
-						varName:        varName,
-						varDescription: "The token for the databricks credential " + fmt.Sprintf("%0.f", credentialID) + " - " + targetURL,
-					})
-					credentialTyped["token"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-
-					// the target_name is deprecated at the credentials level
-					delete(credentialTyped, "target_name")
-
-					if linkResource("dbtcloud_project") {
-						credentialTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-
-					jsonStructData = append(jsonStructData, credentialTyped)
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_bigquery_credential":
-				listCredentials := dbtCloudClient.GetBigQueryCredentials(listFilterProjects)
-
-				for _, credential := range listCredentials {
-					credentialTyped := credential.(map[string]any)
-
-					projectID := credentialTyped["project_id"].(float64)
-					credentialTyped["num_threads"] = credentialTyped["threads"]
-					credentialTyped["dataset"] = credentialTyped["schema"]
-
-					if linkResource("dbtcloud_project") {
-						credentialTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-					jsonStructData = append(jsonStructData, credentialTyped)
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_bigquery_connection":
-				bigqueryConnections := dbtCloudClient.GetBigQueryConnections(listFilterProjects)
-				bigqueryConnectionsTyped := []any{}
-
-				for _, connection := range bigqueryConnections {
-					connectionTyped := connection.(map[string]any)
-					projectID := connectionTyped["project_id"].(float64)
-					connectionID := connectionTyped["id"].(float64)
-
-					connectionDetailsTyped := connectionTyped["details"].(map[string]any)
-
-					// we "promote" all details fields one level up like in the Terraform resource
-					for detailKey, detailVal := range connectionDetailsTyped {
-						connectionTyped[detailKey] = detailVal
-					}
-					// we have to put back the ID as it is only set at the top level
-					connectionTyped["id"] = connectionID
-
-					// we set the project IDs to the correct values
-					// unfortunately project ID can mean a dbt Cloud project or a GCP project
-					connectionTyped["project_id"] = projectID
-					connectionTyped["gcp_project_id"] = connectionDetailsTyped["project_id"]
-
-					// we add the secure fields
-					varName := fmt.Sprintf("dbtcloud_bigquery_connection_private_key_%0.f", connectionID)
-					targetURL := fmt.Sprintf("%s/settings/accounts/%s/pages/connections/%0.f/", dbtCloudClient.HostURL[:len(dbtCloudClient.HostURL)-4], dbtCloudClient.AccountID, connectionID)
-					AllTFVars = append(AllTFVars, tfVar{
-						varType:        "string",
-						varName:        varName,
-						varDescription: "The private key for the bigquery connection " + fmt.Sprintf("%0.f", connectionID) + " - " + targetURL,
-					})
-					connectionTyped["private_key"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-
-					if linkResource("dbtcloud_project") {
-						connectionTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-
-					bigqueryConnectionsTyped = append(bigqueryConnectionsTyped, connectionTyped)
-				}
-
-				jsonStructData = bigqueryConnectionsTyped
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_connection":
-				genericConnections := dbtCloudClient.GetGenericConnections(listFilterProjects)
-				genericConnectionsTyped := []any{}
-
-				for _, connection := range genericConnections {
-					connectionTyped := connection.(map[string]any)
-					projectID := connectionTyped["project_id"].(float64)
-					connectionID := connectionTyped["id"].(float64)
-					connectionDetailsTyped := connectionTyped["details"].(map[string]any)
-
-					// we "promote" all details fields one level up like in the Terraform resource
-					for detailKey, detailVal := range connectionDetailsTyped {
-						connectionTyped[detailKey] = detailVal
-					}
-					// we have to put back the ID as it is only set at the top level
-					connectionTyped["id"] = connectionID
-
-					if connectionTyped["type"] == "snowflake" {
-						connectionTyped["oauth_client_id"] = "---TBD if using OAuth, otherwise delete---"
-						connectionTyped["oauth_client_secret"] = "---TBD if using OAuth, otherwise delete---"
-					}
-
-					if connectionTyped["type"] == "redshift" || connectionTyped["type"] == "postgres" {
-						connectionTyped["host_name"] = connectionTyped["hostname"]
-						connectionTyped["database"] = connectionTyped["dbname"]
-					}
-
-					if connectionTyped["type"] == "adapter" {
-
-						detailsTyped := connectionTyped["details"].(map[string]any)
-						connectionDetailsTyped := detailsTyped["connection_details"].(map[string]any)
-						fieldsTyped := connectionDetailsTyped["fields"].(map[string]any)
-						typeTyped := fieldsTyped["type"].(map[string]any)
-						connectionType := fmt.Sprintf("adapter/%s", typeTyped["value"].(string))
-
-						if connectionType == "adapter/databricks" {
-
-							hostnameTyped := fieldsTyped["host"].(map[string]any)
-							hostnameVal := hostnameTyped["value"].(string)
-							httpPathTyped := fieldsTyped["http_path"].(map[string]any)
-							httpPathVal := httpPathTyped["value"].(string)
-							catalogTyped := fieldsTyped["catalog"].(map[string]any)
-							catalogVal := catalogTyped["value"].(string)
-
-							connectionTyped["host_name"] = hostnameVal
-							connectionTyped["http_path"] = httpPathVal
-							connectionTyped["catalog"] = catalogVal
-							connectionTyped["database"] = "<set-empty-string>"
-						}
-
-						// we don't support adapter/spark yet
-
-					}
-
-					if linkResource("dbtcloud_project") {
-						connectionTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-
-					genericConnectionsTyped = append(genericConnectionsTyped, connectionTyped)
-				}
-
-				jsonStructData = genericConnectionsTyped
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_extended_attributes":
-				listExtendedAttributes := dbtCloudClient.GetExtendedAttributes(listFilterProjects)
-
-				for _, extendedAttributes := range listExtendedAttributes {
-					extendedAttributesTyped := extendedAttributes.(map[string]any)
-
-					marshalledExtendedAttributes, err := json.Marshal(extendedAttributesTyped["extended_attributes"].(map[string]any))
-					if err != nil {
-						log.Panicf("Error marshalling extended attributes: %s", err)
-					}
-					jsonValue := string(marshalledExtendedAttributes)
-					extendedAttributesTyped["extended_attributes"] = jsonValue
-
-					projectID := extendedAttributesTyped["project_id"].(float64)
-					extendedAttributesTyped["state"] = ""
-
-					if linkResource("dbtcloud_project") {
-						extendedAttributesTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, projectID)
-					}
-					jsonStructData = append(jsonStructData, extendedAttributesTyped)
-				}
-
-				resourceCount = len(jsonStructData)
-
-			// not limited by project
-			case "dbtcloud_group":
-
-				listGroups := dbtCloudClient.GetGroups()
-
-				for _, group := range listGroups {
-					groupTyped := group.(map[string]any)
-
-					// we check if the group is one of the default ones
-					_, ok := lo.Find(defaultGroups, func(i string) bool {
-						return i == groupTyped["name"].(string)
-					})
-					// remove the default groups
-					if ok {
-						continue
-					}
-
-					if linkResource("dbtcloud_project") {
-
-						groupPermissions, ok := groupTyped["group_permissions"].([]any)
-						if !ok {
-							panic("Could not cast group_permissions to []any")
-						}
-						newGroupPermissionsTyped := []map[string]any{}
-						for _, groupPermission := range groupPermissions {
-							groupPermissionTyped := groupPermission.(map[string]any)
-							if groupPermissionTyped["all_projects"] == false && lo.Contains(prefetchedProjectsIDs, int(groupPermissionTyped["project_id"].(float64))) {
-								groupPermissionTyped["project_id"] = fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%0.f.id", prefixNoQuotes, groupPermissionTyped["project_id"].(float64))
-								newGroupPermissionsTyped = append(newGroupPermissionsTyped, groupPermissionTyped)
-							}
-						}
-						groupTyped["group_permissions"] = newGroupPermissionsTyped
-
-					}
-					jsonStructData = append(jsonStructData, group)
+			sort.Strings(sortedBlockAttributes)
+
+			// Block attributes are for any attributes where assignment is involved.
+			for _, attrName := range sortedBlockAttributes {
+				log.Debugf("checking the attribute %s", attrName)
+				// Don't bother outputting the ID for the resource as that is only for
+				// internal use (such as importing state).
+				if attrName == "id" {
+					continue
 				}
 
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_user_groups":
-				listUsers := dbtCloudClient.GetUsers()
-
-				// we need the group names so we can exclude the built-in
-				// default groups (Owner/Member/Everyone) from group_ids:
-				// those groups are never generated as dbtcloud_group
-				// resources, so referencing them here (or even including
-				// their raw IDs) would either produce dangling resource
-				// references or manage membership that dbt platform itself
-				// controls.
-				listGroups := dbtCloudClient.GetGroups()
-				groupIDToName := buildGroupIDToNameMap(listGroups)
-
-				for _, user := range listUsers {
-					userTyped := user.(map[string]any)
-
-					userTyped["user_id"] = userTyped["id"].(float64)
-					if linkResource("users_by_email") {
-						userID := userTyped["user_id"].(float64)
-						userEmail, ok := prefetchedMapUserIDsEmails[userID]
-						if !ok {
-							log.Warnf("User %0.f not found", userID)
-							continue
-						}
-						userTyped["user_id"] = fmt.Sprintf("%slocal.id_group_%s", prefixNoQuotes, slug.Make(userEmail))
-						userTyped["count"] = fmt.Sprintf("%slocal.count_group_%s", prefixNoQuotes, slug.Make(userEmail))
-
-						AllLocals[fmt.Sprintf("details_group_%s", slug.Make(userEmail))] = fmt.Sprintf(`%s[for user in data.dbtcloud_users.all.users : user if user.email == "%s"]`, prefixNoQuotes, userEmail)
-						AllLocals[fmt.Sprintf("count_group_%s", slug.Make(userEmail))] = fmt.Sprintf("%slength(local.%s)", prefixNoQuotes, fmt.Sprintf("details_group_%s", slug.Make(userEmail)))
-						AllLocals[fmt.Sprintf("id_group_%s", slug.Make(userEmail))] = fmt.Sprintf("%slocal.count_group_%s == 1 ? local.details_group_%s[0].id : 0", prefixNoQuotes, slug.Make(userEmail), slug.Make(userEmail))
-					}
-
-					userPermissionsArray := userTyped["permissions"].([]any)
-					userPermissions := userPermissionsArray[0].(map[string]any)
-					allGroupIDs := []int{}
-
-					for _, group := range userPermissions["groups"].([]any) {
-						groupTyped := group.(map[string]any)
-						allGroupIDs = append(allGroupIDs, int(groupTyped["id"].(float64)))
-					}
-
-					// exclude the built-in default groups: they are never
-					// generated as dbtcloud_group resources, so keeping them
-					// here would produce dangling references (when linked)
-					// or manage membership dbt platform already controls
-					// (when not linked).
-					groupIDs := filterOutDefaultGroupIDs(allGroupIDs, groupIDToName)
-
-					// if the user has no non-default groups left, there is
-					// nothing for this resource to manage, so we skip it
-					// entirely instead of emitting an empty/pointless block.
-					if len(groupIDs) == 0 {
-						continue
-					}
-
-					userTyped["group_ids"] = groupIDs
-
-					if linkResource("dbtcloud_group") {
-						linkedGroupIDs := lo.Map(groupIDs, func(i int, index int) string {
-							return fmt.Sprintf("%sdbtcloud_group.terraform_managed_resource_%d.id", prefixNoQuotes, i)
-						})
-						userTyped["group_ids"] = linkedGroupIDs
-					}
-
-					jsonStructData = append(jsonStructData, userTyped)
-				}
-
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_webhook":
-
-				listWebhooks := dbtCloudClient.GetWebhooks()
-				for _, webhook := range listWebhooks {
-					webhookTyped := webhook.(map[string]any)
-
-					if linkResource("dbtcloud_job") {
-						jobIDs := []string{}
-						for _, jobID := range webhookTyped["job_ids"].([]any) {
-							jobIDTyped := jobID.(string)
-							// we remove jobs that are not relevant to the current project or that have been deleted
-							if lo.Contains(prefetchedJobsIDsString, jobIDTyped) {
-								jobIDs = append(jobIDs, jobIDTyped)
-							}
-						}
-						linkedJobIDs := lo.Map(jobIDs, func(s string, index int) string {
-							return fmt.Sprintf("%sdbtcloud_job.terraform_managed_resource_%s.id", prefixNoQuotes, s)
-						})
-						webhookTyped["job_ids"] = linkedJobIDs
-
-						// if there is no more job to be linked once filtered, but there are some in the config, we skip the resource
-						// because having an empty list of job means "all jobs" from a dbt Cloud API standpoint
-						if len(linkedJobIDs) == 0 && len(webhookTyped["job_ids"].([]string)) > 0 {
-							continue
-						}
-					}
-
-					jsonStructData = append(jsonStructData, webhookTyped)
+				// No need to output computed attributes that are also not
+				// optional.
+				if r.Block.Attributes[attrName].Computed && !r.Block.Attributes[attrName].Optional {
+					continue
 				}
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_notification":
-
-				listNotifications := dbtCloudClient.GetNotifications()
-				for _, notification := range listNotifications {
-					notificationTyped := notification.(map[string]any)
-
-					notificationTyped["notification_type"] = notificationTyped["type"]
-					notificationTyped["state"] = nil
-
-					if notificationTyped["notification_type"].(float64) == 4 && notificationTyped["external_email"] == nil {
-						// for some reason there are external notifications without an email
-						continue
-					}
-
-					if linkResource("dbtcloud_job") {
-						listOns := []string{"on_cancel", "on_failure", "on_success", "on_warning"}
-
-						for _, notifHook := range listOns {
-
-							jobIDs := []float64{}
-
-							for _, jobID := range notificationTyped[notifHook].([]any) {
-								jobIDTyped := jobID.(float64)
-								// we remove jobs that are not relevant to the current project or that have been deleted
-								if lo.Contains(prefetchedJobsIDs, int(jobIDTyped)) {
-									jobIDs = append(jobIDs, jobIDTyped)
-								}
-							}
-							linkedJobIDs := lo.Map(jobIDs, func(f float64, index int) string {
-								return fmt.Sprintf("%sdbtcloud_job.terraform_managed_resource_%.0f.id", prefixNoQuotes, f)
-							})
-							notificationTyped[notifHook] = linkedJobIDs
-						}
-					}
-
-					if linkResource("users_by_email") {
-						userID := notificationTyped["user_id"].(float64)
-						userEmail, ok := prefetchedMapUserIDsEmails[userID]
-						if !ok {
-							log.Warnf("User %0.f not found", userID)
-							continue
-						}
-						notificationTyped["user_id"] = fmt.Sprintf("%slocal.id_%s", prefixNoQuotes, slug.Make(userEmail))
-						notificationTyped["count"] = fmt.Sprintf("%slocal.count_%s", prefixNoQuotes, slug.Make(userEmail))
-
-						AllLocals[fmt.Sprintf("details_%s", slug.Make(userEmail))] = fmt.Sprintf(`%s[for user in data.dbtcloud_users.all.users : user if user.email == "%s"]`, prefixNoQuotes, userEmail)
-						AllLocals[fmt.Sprintf("count_%s", slug.Make(userEmail))] = fmt.Sprintf("%slength(local.%s)", prefixNoQuotes, fmt.Sprintf("details_%s", slug.Make(userEmail)))
-						AllLocals[fmt.Sprintf("id_%s", slug.Make(userEmail))] = fmt.Sprintf("%slocal.count_%s == 1 ? local.details_%s[0].id : 0", prefixNoQuotes, slug.Make(userEmail), slug.Make(userEmail))
-					}
-
-					jsonStructData = append(jsonStructData, notificationTyped)
+				if attrName == "account_id" && accountID != "" {
+					writeAttrLine(attrName, accountID, "", resource)
+					continue
 				}
-				resourceCount = len(jsonStructData)
-
-			case "dbtcloud_service_token":
-
-				listServiceTokens := dbtCloudClient.GetServiceTokens()
-				for _, serviceToken := range listServiceTokens {
-
-					serviceTokenTyped := serviceToken.(map[string]any)
-					serviceTokenTyped["uid"] = nil
-					serviceTokenID := int(serviceTokenTyped["id"].(float64))
-
-					permissions := dbtCloudClient.GetServiceTokenPermissions(serviceTokenID)
-
-					if linkResource("dbtcloud_project") {
-						permissionsFilteredProjects := []any{}
-						for _, permissionsSet := range permissions {
-							permissionsSetTyped := permissionsSet.(map[string]any)
-							if permissionsSetTyped["project_id"] != nil && lo.Contains(prefetchedProjectsIDs, int(permissionsSetTyped["project_id"].(float64))) {
-								projectID := permissionsSetTyped["project_id"].(float64)
-								projectResources := fmt.Sprintf("%sdbtcloud_project.terraform_managed_resource_%.0f.id", prefixNoQuotes, projectID)
-								permissionsSetTyped["project_id"] = projectResources
-								permissionsFilteredProjects = append(permissionsFilteredProjects, permissionsSetTyped)
-							}
-						}
-						permissions = permissionsFilteredProjects
-					}
-
-					serviceTokenTyped["service_token_permissions"] = permissions
 
-					jsonStructData = append(jsonStructData, serviceTokenTyped)
+				// This is to handle Attributes in the Framework
+				if r.Block.Attributes[attrName].AttributeType == cty.NilType {
+					writeAttrLine(attrName, structData[attrName], "", resource)
+					continue
 				}
-				resourceCount = len(jsonStructData)
 
-			case "dbtcloud_global_connection":
-
-				listConnections := dbtCloudClient.GetGlobalConnections()
-
-				for _, connection := range listConnections {
-					connectionTyped := connection.(map[string]any)
-
-					configSection := getAdapterFromAdapterVersion(connectionTyped["adapter_version"].(string))
-
-					configTyped := connectionTyped["config"].(map[string]any)
-					delete(configTyped, "adapter_id")
-					targetURL := fmt.Sprintf("%s/settings/accounts/%s/pages/connections/%0.f/", dbtCloudClient.HostURL[:len(dbtCloudClient.HostURL)-4], dbtCloudClient.AccountID, connectionTyped["id"].(float64))
-
-					// handle the fields that don't come back from the API
-					if _, exists := configTyped["oauth_client_id"]; exists {
-						varName := fmt.Sprintf("dbtcloud_global_connection_oauth_client_id_%0.f", connectionTyped["id"].(float64))
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varName,
-							varDescription: "The OAuth client ID for the global connection " + fmt.Sprintf("%0.f", connectionTyped["id"].(float64)) + " - " + targetURL,
-						})
-						configTyped["oauth_client_id"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-					}
-					if _, exists := configTyped["oauth_client_secret"]; exists {
-						varName := fmt.Sprintf("dbtcloud_global_connection_oauth_client_secret_%0.f", connectionTyped["id"].(float64))
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varName,
-							varDescription: "The OAuth client secret for the global connection " + fmt.Sprintf("%0.f", connectionTyped["id"].(float64)) + " - " + targetURL,
-						})
-						configTyped["oauth_client_secret"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-					}
-					if _, exists := configTyped["private_key"]; exists {
-						varName := fmt.Sprintf("dbtcloud_global_connection_private_key_%0.f", connectionTyped["id"].(float64))
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varName,
-							varDescription: "The private key for the global connection " + fmt.Sprintf("%0.f", connectionTyped["id"].(float64)) + " - " + targetURL,
-						})
-						configTyped["private_key"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-					}
-					if _, exists := configTyped["application_id"]; exists {
-						varName := fmt.Sprintf("dbtcloud_global_connection_application_id_%0.f", connectionTyped["id"].(float64))
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varName,
-							varDescription: "The application ID for the global connection " + fmt.Sprintf("%0.f", connectionTyped["id"].(float64)) + " - " + targetURL,
-						})
-						configTyped["application_id"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-					}
-					if _, exists := configTyped["application_secret"]; exists {
-						varName := fmt.Sprintf("dbtcloud_global_connection_application_secret_%0.f", connectionTyped["id"].(float64))
-						AllTFVars = append(AllTFVars, tfVar{
-							varType:        "string",
-							varName:        varName,
-							varDescription: "The application secret for the global connection " + fmt.Sprintf("%0.f", connectionTyped["id"].(float64)) + " - " + targetURL,
-						})
-						configTyped["application_secret"] = fmt.Sprintf("%svar.%s", prefixNoQuotes, varName)
-					}
-					// For BQ, to handle the renaming of the fields
-					if gcpProjectID, exists := configTyped["project_id"]; exists && configSection == "bigquery" {
-						configTyped["gcp_project_id"] = gcpProjectID
-						delete(configTyped, "project_id")
-					}
-
-					if connectionTyped["private_link_endpoint_id"] != nil {
-						connectionTyped["private_link_endpoint_id"] = fmt.Sprintf("%svar.dbtcloud_global_connection_private_link_endpoint_id_%0.f", prefixNoQuotes, connectionTyped["id"].(float64))
-						varName := fmt.Sprintf("dbtcloud_global_connection_private_link_endpoint_id_%0.f", connectionTyped["id"].(float64))
-						allVarNames := lo.Map(AllTFVars, func(i tfVar, _ int) string { return i.varName })
-						if !lo.Contains(allVarNames, varName) {
-							AllTFVars = append(AllTFVars, tfVar{
-								varType:        "string",
-								varName:        varName,
-								varDescription: "The private link endpoint ID for the global connection " + fmt.Sprintf("%0.f", connectionTyped["id"].(float64)) + " - " + targetURL,
-							})
-						}
-					}
-
-					connectionTyped[configSection] = configTyped
-					jsonStructData = append(jsonStructData, connectionTyped)
-
-				}
-
-				resourceCount = len(jsonStructData)
-
-			// account_features is a singleton: one set of feature flags per account,
-			// not a list of items, so there's no per-item `id` to derive a resource
-			// label from. We label it directly via resourceIDOverride instead.
-			case "dbtcloud_account_features":
-
-				jsonStructData = dbtCloudClient.GetAccountFeatures()
-				resourceCount = len(jsonStructData)
-				resourceIDOverride = "account_features"
-
-			// dbtcloud_profile is project-scoped and its `profile_id` is only
-			// unique within a project, not across the account, so a plain
-			// numeric label (as used by e.g. dbtcloud_environment) could collide
-			// across projects. Rather than generalizing resourceIDOverride to
-			// vary per item (it is a single value shared by every item of a
-			// resource type in the post-switch loop below), we fold project_id
-			// into structData["id"] itself - exactly the approach
-			// dbtcloud_environment_variable already uses for its own composite
-			// id above. computeResourceLabel's existing string-id path then
-			// picks this up unchanged, and the plain numeric profile id is kept
-			// separately as "profile_id" for the resource's own attribute and
-			// for the import composite address.
-			case "dbtcloud_profile":
-
-				listProfiles := dbtCloudClient.GetProfiles(listFilterProjects)
-
-				for _, profile := range listProfiles {
-					profileTyped := profile.(map[string]any)
-					jsonStructData = append(jsonStructData, transformProfileForGenerate(profileTyped))
-				}
-
-				resourceCount = len(jsonStructData)
-
-			// dbtcloud_job_completion_trigger reproduces, as its own
-			// standalone resource, the "run after" relationship that already
-			// rides along on a job's own payload under
-			// job_completion_trigger_condition (see the dbtcloud_job case
-			// above, which already parses this same field for its own
-			// purposes). Per the real provider schema, the standalone
-			// resource's attributes are flat - job_id (the downstream job
-			// this trigger is attached to), trigger_job_id (the upstream job
-			// whose completion fires it), project_id, and statuses - with no
-			// nested "condition" block. Its id is just the downstream job's
-			// plain numeric id (matching the provider's ImportState, which
-			// parses the import id directly as job_id), so no composite-id
-			// folding is needed here, unlike dbtcloud_profile.
-			case "dbtcloud_job_completion_trigger":
-
-				jsonStructData = prefetchedJobCompletionTriggers
-				resourceCount = len(jsonStructData)
-
-			// dbtcloud_environment_variable_job_override's true id
-			// (environment_variable_job_override_id) is only meaningful
-			// together with the project/job scope it lives in, so - exactly
-			// as dbtcloud_profile and dbtcloud_environment_variable already
-			// do above - we fold project_id/job_definition_id/override_id
-			// into structData["id"] for the resource label, while keeping
-			// the plain numeric override id under
-			// "environment_variable_job_override_id" for the import address.
-			case "dbtcloud_environment_variable_job_override":
-
-				listOverrides := dbtCloudClient.GetEnvironmentVariableJobOverrides(listFilterProjects, prefetchedJobs)
-
-				for _, override := range listOverrides {
-					overrideTyped := override.(map[string]any)
-					jsonStructData = append(jsonStructData, transformEnvironmentVariableJobOverrideForGenerate(overrideTyped))
-				}
-
-				resourceCount = len(jsonStructData)
-
-			default:
-				fmt.Fprintf(cmd.OutOrStderr(), "%q is not yet supported for automatic generation", resourceType)
-				return
-			}
-
-			// If we don't have any resources to generate, just bail out early.
-			if resourceCount == 0 {
-				fmt.Fprintf(cmd.OutOrStderr(), "# no resources of type %q found to generate\n", resourceType)
-				continue
-			}
-
-			for i := 0; i < resourceCount; i++ {
-				structData := jsonStructData[i].(map[string]interface{})
-
-				resourceID := ""
-				if os.Getenv("USE_STATIC_RESOURCE_IDS") == "true" {
-					resourceID = "terraform_managed_resource"
-				} else {
-					resourceID = computeResourceLabel(resourceType, structData, resourceIDOverride)
-				}
-				resource := rootBody.AppendNewBlock("resource", []string{resourceType, resourceID}).Body()
-
-				sortedBlockAttributes := make([]string, 0, len(r.Block.Attributes))
-				for k := range r.Block.Attributes {
-					sortedBlockAttributes = append(sortedBlockAttributes, k)
-				}
-				sort.Strings(sortedBlockAttributes)
-
-				// Block attributes are for any attributes where assignment is involved.
-				for _, attrName := range sortedBlockAttributes {
-					log.Debugf("checking the attribute %s", attrName)
-					// Don't bother outputting the ID for the resource as that is only for
-					// internal use (such as importing state).
-					if attrName == "id" {
-						continue
-					}
-
-					// No need to output computed attributes that are also not
-					// optional.
-					if r.Block.Attributes[attrName].Computed && !r.Block.Attributes[attrName].Optional {
-						continue
-					}
-					if attrName == "account_id" && accountID != "" {
-						writeAttrLine(attrName, accountID, "", resource)
-						continue
-					}
-
-					// This is to handle Attributes in the Framework
-					if r.Block.Attributes[attrName].AttributeType == cty.NilType {
+				ty := r.Block.Attributes[attrName].AttributeType
+				switch {
+				case ty.IsPrimitiveType():
+					switch ty {
+					case cty.String, cty.Bool, cty.Number:
 						writeAttrLine(attrName, structData[attrName], "", resource)
-						continue
+						delete(structData, attrName)
+					default:
+						log.Debugf("unexpected primitive type %q", ty.FriendlyName())
 					}
-
-					ty := r.Block.Attributes[attrName].AttributeType
+				case ty.IsCollectionType():
 					switch {
-					case ty.IsPrimitiveType():
-						switch ty {
-						case cty.String, cty.Bool, cty.Number:
-							writeAttrLine(attrName, structData[attrName], "", resource)
-							delete(structData, attrName)
-						default:
-							log.Debugf("unexpected primitive type %q", ty.FriendlyName())
-						}
-					case ty.IsCollectionType():
-						switch {
-						case ty.IsListType(), ty.IsSetType(), ty.IsMapType():
-							writeAttrLine(attrName, structData[attrName], "", resource)
-							delete(structData, attrName)
-						default:
-							log.Debugf("unexpected collection type %q", ty.FriendlyName())
-						}
-					case ty.IsTupleType():
-						fmt.Printf("tuple found. attrName %s\n", attrName)
-					case ty.IsObjectType():
-						fmt.Printf("object found. attrName %s\n", attrName)
+					case ty.IsListType(), ty.IsSetType(), ty.IsMapType():
+						writeAttrLine(attrName, structData[attrName], "", resource)
+						delete(structData, attrName)
 					default:
-						log.Debugf("attribute %q (attribute type of %q) has not been generated", attrName, ty.FriendlyName())
-					}
+						log.Debugf("unexpected collection type %q", ty.FriendlyName())
+					}
+				case ty.IsTupleType():
+					fmt.Printf("tuple found. attrName %s\n", attrName)
+				case ty.IsObjectType():
+					fmt.Printf("object found. attrName %s\n", attrName)
+				default:
+					log.Debugf("attribute %q (attribute type of %q) has not been generated", attrName, ty.FriendlyName())
 				}
-
-				processBlocks(r.Block, jsonStructData[i].(map[string]interface{}), resource, "")
-				rootBody.AppendNewline()
 			}
+
+			processBlocks(r.Block, structData, resource, "")
+			rootBody.AppendNewline()
 		}
+	}
 
-		// Add the variables
-		if len(AllTFVars) > 0 {
-			// Add a comment to the file
-			comment := hclwrite.Tokens{
-				&hclwrite.Token{
-					Type:         hclsyntax.TokenComment,
-					Bytes:        []byte("# The variables defined for fields we couldn't retrieve\n\n"),
-					SpacesBefore: 0,
-				},
-			}
-			rootBody.AppendUnstructuredTokens(comment)
+	// Add the variables
+	if len(AllTFVars) > 0 {
+		rootBody := files.body("variables.tf")
+		// Add a comment to the file
+		comment := hclwrite.Tokens{
+			&hclwrite.Token{
+				Type:         hclsyntax.TokenComment,
+				Bytes:        []byte("# The variables defined for fields we couldn't retrieve\n\n"),
+				SpacesBefore: 0,
+			},
+		}
+		rootBody.AppendUnstructuredTokens(comment)
 
-			for _, tfVar := range AllTFVars {
-				variablesBlock := rootBody.AppendNewBlock("variable", []string{tfVar.varName}).Body()
-				hclTokens := []*hclwrite.Token{{Type: hclsyntax.TokenIdent, Bytes: []byte(tfVar.varType)}}
-				variablesBlock.SetAttributeRaw("type", hclTokens)
-				variablesBlock.SetAttributeValue("description", cty.StringVal(tfVar.varDescription))
-				rootBody.AppendNewline()
-			}
+		for _, tfVar := range AllTFVars {
+			variablesBlock := rootBody.AppendNewBlock("variable", []string{tfVar.varName}).Body()
+			hclTokens := []*hclwrite.Token{{Type: hclsyntax.TokenIdent, Bytes: []byte(tfVar.varType)}}
+			variablesBlock.SetAttributeRaw("type", hclTokens)
+			variablesBlock.SetAttributeValue("description", cty.StringVal(tfVar.varDescription))
+			rootBody.AppendNewline()
 		}
+	}
 
-		// Add locals block if parameterizeJobs is true
-		if parameterizeJobs {
+	// Add locals block if parameterizeJobs is true
+	if parameterizeJobs {
+		rootBody := files.body("locals.tf")
 
-			comment := hclwrite.Tokens{
-				&hclwrite.Token{
-					Type:         hclsyntax.TokenComment,
-					Bytes:        []byte("# The locals used to activate/deactivate jobs\n\n"),
-					SpacesBefore: 0,
-				},
-			}
-			rootBody.AppendUnstructuredTokens(comment)
-			localsBlock := rootBody.AppendNewBlock("locals", nil).Body()
-			localsBlock.SetAttributeValue("deactivate_jobs_pr", cty.BoolVal(false))
-			localsBlock.SetAttributeValue("deactivate_jobs_schedule", cty.BoolVal(false))
-			localsBlock.SetAttributeValue("deactivate_jobs_merge", cty.BoolVal(false))
-			rootBody.AppendNewline()
+		comment := hclwrite.Tokens{
+			&hclwrite.Token{
+				Type:         hclsyntax.TokenComment,
+				Bytes:        []byte("# The locals used to activate/deactivate jobs\n\n"),
+				SpacesBefore: 0,
+			},
 		}
+		rootBody.AppendUnstructuredTokens(comment)
+		localsBlock := rootBody.AppendNewBlock("locals", nil).Body()
+		localsBlock.SetAttributeValue("deactivate_jobs_pr", cty.BoolVal(false))
+		localsBlock.SetAttributeValue("deactivate_jobs_schedule", cty.BoolVal(false))
+		localsBlock.SetAttributeValue("deactivate_jobs_merge", cty.BoolVal(false))
+		rootBody.AppendNewline()
+	}
 
-		// Add template for the variable values
-		if len(AllTFVars) > 0 {
-			// Add a comment to the file
+	// Add template for the variable values
+	if len(AllTFVars) > 0 && outputDir != "" {
+		tfvarsBody := files.body("terraform.tfvars.example")
+		for _, tfVar := range AllTFVars {
+			tfvarsBody.SetAttributeValue(tfVar.varName, cty.StringVal(""))
+		}
+	} else if len(AllTFVars) > 0 {
+		rootBody := files.body("")
+		// Add a comment to the file
+		comment := hclwrite.Tokens{
+			&hclwrite.Token{
+				Type:         hclsyntax.TokenComment,
+				Bytes:        []byte("# Copy past the following lines in terraform.tfvars\n\n"),
+				SpacesBefore: 0,
+			},
+		}
+		rootBody.AppendUnstructuredTokens(comment)
+
+		for _, tfVar := range AllTFVars {
 			comment := hclwrite.Tokens{
 				&hclwrite.Token{
 					Type:         hclsyntax.TokenComment,
-					Bytes:        []byte("# Copy past the following lines in terraform.tfvars\n\n"),
+					Bytes:        []byte(fmt.Sprintf("# %s = \"\"", tfVar.varName)),
 					SpacesBefore: 0,
 				},
 			}
 			rootBody.AppendUnstructuredTokens(comment)
-
-			for _, tfVar := range AllTFVars {
-				comment := hclwrite.Tokens{
-					&hclwrite.Token{
-						Type:         hclsyntax.TokenComment,
-						Bytes:        []byte(fmt.Sprintf("# %s = \"\"", tfVar.varName)),
-						SpacesBefore: 0,
-					},
-				}
-				rootBody.AppendUnstructuredTokens(comment)
-				rootBody.AppendNewline()
-			}
-			rootBody.AppendNewline()
 			rootBody.AppendNewline()
 		}
+		rootBody.AppendNewline()
+		rootBody.AppendNewline()
+	}
 
-		if len(AllLocals) > 0 {
+	if len(AllLocals) > 0 {
+		rootBody := files.body("locals.tf")
 
-			dataBlock := rootBody.AppendNewBlock("data", []string{"dbtcloud_users", "all"}).Body()
-			dataBlock.AppendNewline()
-			dataBlock.AppendNewline()
+		dataBlock := rootBody.AppendNewBlock("data", []string{"dbtcloud_users", "all"}).Body()
+		dataBlock.AppendNewline()
+		dataBlock.AppendNewline()
 
-			comment := hclwrite.Tokens{
-				&hclwrite.Token{
-					Type:         hclsyntax.TokenComment,
-					Bytes:        []byte("# The locals used for linking users\n"),
-					SpacesBefore: 0,
-				},
-			}
-			rootBody.AppendUnstructuredTokens(comment)
-			localsBlock := rootBody.AppendNewBlock("locals", nil).Body()
+		comment := hclwrite.Tokens{
+			&hclwrite.Token{
+				Type:         hclsyntax.TokenComment,
+				Bytes:        []byte("# The locals used for linking users\n"),
+				SpacesBefore: 0,
+			},
+		}
+		rootBody.AppendUnstructuredTokens(comment)
+		localsBlock := rootBody.AppendNewBlock("locals", nil).Body()
 
-			for key, value := range AllLocals {
-				writeAttrLine(key, value, "", localsBlock)
-			}
-			rootBody.AppendNewline()
+		for key, value := range AllLocals {
+			writeAttrLine(key, value, "", localsBlock)
 		}
+		rootBody.AppendNewline()
+	}
 
-		// Format the output
-		output := string(hclwrite.Format(f.Bytes()))
+	// Format the output
+	return files.output(), account
+}
 
-		// Write the formatted output
-		if err := writeString(output); err != nil {
-			log.Fatalf("failed to write output: %v", err)
-		}
+// writeRequiredProviders writes the terraform block requiring the dbt Cloud
+// provider, with the version the provider schema is for when it is known.
+func writeRequiredProviders(body *hclwrite.Body, providerVersion string) {
+	requirement := map[string]cty.Value{
+		"source": cty.StringVal("dbt-labs/dbtcloud"),
 	}
+	if providerVersion != "" {
+		requirement["version"] = cty.StringVal(providerVersion)
+	}
+	requiredProviders := body.AppendNewBlock("terraform", nil).Body().AppendNewBlock("required_providers", nil).Body()
+	requiredProviders.SetAttributeValue("dbtcloud", cty.ObjectVal(requirement))
+	body.AppendNewline()
+}
+
+// writeProviderConfig writes the provider configuration of the providers.tf
+// file of the --output-dir layout. The API token is left to the
+// DBT_CLOUD_TOKEN environment variable so that it doesn't end up in the code.
+func writeProviderConfig(body *hclwrite.Body) {
+	provider := body.AppendNewBlock("provider", []string{"dbtcloud"}).Body()
+	writeAttrLine("account_id", accountID, "", provider)
+	provider.SetAttributeValue("host_url", cty.StringVal(hostURL))
+	body.AppendNewline()
 }
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"regexp"
@@ -18,6 +20,7 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -66,6 +69,39 @@ func TestGenerate_writeAttrLine(t *testing.T) {
 		"value is list of strings":  {key: "a", value: listOfString, want: multilineListOfStrings},
 		"value is block of strings": {key: "a", value: configBlockOfStrings, want: multilineBlock},
 		"value is nil":              {key: "a", value: nil, want: ""},
+		"value is reference":        {key: "a", value: hclReference("dbtcloud_project.terraform_managed_resource_1.id"), want: "a = dbtcloud_project.terraform_managed_resource_1.id\n"},
+		"value is expression":       {key: "a", value: hclRaw("local.deactivate_jobs_pr ? false : true"), want: "a = local.deactivate_jobs_pr ? false : true\n"},
+		"value is mixed list": {
+			key:   "a",
+			value: []any{hclReference("dbtcloud_job.terraform_managed_resource_1.id"), 2},
+			want:  "a = [dbtcloud_job.terraform_managed_resource_1.id, 2]\n",
+		},
+		"value is nested map with references": {
+			key: "a",
+			value: map[string]any{
+				"Prod env": hclReference("var.secret"),
+				"project":  "b",
+				"nested":   map[string]any{"ids": []hclExpression{hclReference("dbtcloud_job.terraform_managed_resource_1.id")}},
+			},
+			want: heredoc.Doc(`
+				a = {
+				  "Prod env" = var.secret
+				  nested = {
+				    ids = [dbtcloud_job.terraform_managed_resource_1.id]
+				  }
+				  project = "b"
+				}
+			`),
+		},
+		// only the attributes known to hold JSON documents are written with
+		// jsonencode, by their handlers
+		"value is JSON document": {
+			key:   "a",
+			value: `{"type": "databricks",  "threads": 8}`,
+			want:  `a = "{\"type\": \"databricks\",  \"threads\": 8}"` + "\n",
+		},
+		"value is JSON scalar":                       {key: "a", value: "8", want: "a = \"8\"\n"},
+		"value is string looking like an expression": {key: "a", value: "~no-quotes~var.b", want: "a = \"~no-quotes~var.b\"\n"},
 	}
 
 	for name, tc := range tests {
@@ -107,7 +143,7 @@ func TestResourceGeneration(t *testing.T) {
 		//   OVERWRITE_VCR_CASSETTES=true go test ./internal/app/dbtcloud-terraforming/cmd/... \
 		//     -run TestResourceGeneration/dbt_Cloud_account_features -v
 		// Until that cassette exists, this row is scaffolded but will fail if run; see
-		// TestGenerate_ComputeResourceLabel and TestGenerate_AccountFeaturesHCLEmission for
+		// TestGenerate_ResourceLabel and TestGenerate_AccountFeaturesHCLEmission for
 		// standalone unit coverage of the singleton ID handling and attribute emission in
 		// the meantime.
 		"dbt Cloud account features": {identifierType: "account", resourceType: "dbtcloud_account_features", testdataFilename: "dbtcloud_account_features"},
@@ -219,13 +255,13 @@ func TestResourceGeneration(t *testing.T) {
 // fabricatedGroupsPayload mimics the shape returned by
 // dbtCloudClient.GetGroups(): a mix of built-in default groups
 // (Owner/Member/Everyone) and custom, user-managed groups.
-func fabricatedGroupsPayload() []any {
-	return []any{
-		map[string]any{"id": float64(1), "name": "Owner"},
-		map[string]any{"id": float64(2), "name": "Member"},
-		map[string]any{"id": float64(3), "name": "Everyone"},
-		map[string]any{"id": float64(10), "name": "Analysts"},
-		map[string]any{"id": float64(11), "name": "Admins"},
+func fabricatedGroupsPayload() []dbtcloud.Group {
+	return []dbtcloud.Group{
+		{ID: 1, Name: "Owner"},
+		{ID: 2, Name: "Member"},
+		{ID: 3, Name: "Everyone"},
+		{ID: 10, Name: "Analysts"},
+		{ID: 11, Name: "Admins"},
 	}
 }
 
@@ -258,47 +294,53 @@ func TestGenerate_FilterOutDefaultGroupIDs(t *testing.T) {
 		"unknown group id (not in the account's groups) is kept": {
 			groupIDs: []int{999},
 			want:     []int{999},
-// TestGenerate_ComputeResourceLabel covers the generalized ID-derivation logic
-// used to label generated `resource "..." "..."` blocks: the existing
-// numeric/string id-based behavior for list-based resources must stay
-// byte-identical, while a non-empty resourceIDOverride must let a resource
-// (e.g. the dbtcloud_account_features singleton) opt out of id-based
-// labelling entirely.
-func TestGenerate_ComputeResourceLabel(t *testing.T) {
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got := filterOutDefaultGroupIDs(tc.groupIDs, groupIDToName)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+// TestGenerate_ResourceLabel covers the labels of the generated
+// `resource "..." "..."` blocks, which are also the addresses the import
+// blocks point to: list-based resources are labelled with their id (composite
+// for the ones keyed by project), while the dbtcloud_account_features
+// singleton has a fixed label.
+func TestGenerate_ResourceLabel(t *testing.T) {
 	tests := map[string]struct {
-		resourceType       string
-		structData         map[string]interface{}
-		resourceIDOverride string
-		want               string
+		resourceType string
+		item         any
+		want         string
 	}{
-		"numeric id, no override (existing behavior, e.g. dbtcloud_project)": {
+		"numeric id (e.g. dbtcloud_project)": {
 			resourceType: "dbtcloud_project",
-			structData:   map[string]interface{}{"id": float64(123)},
+			item:         dbtcloud.Project{ID: 123},
 			want:         "terraform_managed_resource_123",
 		},
-		"string id, no override (existing behavior, e.g. dbtcloud_environment_variable)": {
+		"composite id (e.g. dbtcloud_environment_variable)": {
 			resourceType: "dbtcloud_environment_variable",
-			structData:   map[string]interface{}{"id": "71_DBT_ENV"},
+			item:         environmentVariable{EnvironmentVariable: dbtcloud.EnvironmentVariable{Name: "DBT_ENV"}, projectID: 71},
 			want:         "terraform_managed_resource_71_DBT_ENV",
 		},
-		"singleton override takes precedence over structData id": {
-			resourceType:       "dbtcloud_account_features",
-			structData:         map[string]interface{}{"id": "1234"},
-			resourceIDOverride: "account_features",
-			want:               "terraform_managed_resource_account_features",
+		"singleton with an id": {
+			resourceType: "dbtcloud_account_features",
+			item:         map[string]any{"id": "1234"},
+			want:         "terraform_managed_resource_account_features",
 		},
-		"singleton override with no id in structData at all": {
-			resourceType:       "dbtcloud_account_features",
-			structData:         map[string]interface{}{},
-			resourceIDOverride: "account_features",
-			want:               "terraform_managed_resource_account_features",
+		"singleton with no id at all": {
+			resourceType: "dbtcloud_account_features",
+			item:         map[string]any{},
+			want:         "terraform_managed_resource_account_features",
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got := filterOutDefaultGroupIDs(tc.groupIDs, groupIDToName)
-			got := computeResourceLabel(tc.resourceType, tc.structData, tc.resourceIDOverride)
+			got := resourceLabel(resourceHandlers[tc.resourceType], tc.item)
 			assert.Equal(t, tc.want, got)
 		})
 	}
@@ -347,21 +389,16 @@ func TestGenerate_UserGroupsHCLExcludesDefaultGroups(t *testing.T) {
 
 	var renderedBlocks []string
 
-	for _, user := range fabricatedUsers {
-		userTyped := user.(map[string]any)
+	for _, userPayload := range fabricatedUsers {
+		user, err := dbtcloud.Decode[dbtcloud.User](userPayload)
+		require.NoError(t, err)
 
-		userPermissionsArray := userTyped["permissions"].([]any)
-		userPermissions := userPermissionsArray[0].(map[string]any)
-		allGroupIDs := []int{}
-		for _, group := range userPermissions["groups"].([]any) {
-			groupTyped := group.(map[string]any)
-			allGroupIDs = append(allGroupIDs, int(groupTyped["id"].(float64)))
-		}
+		allGroupIDs := userGroupIDs(user)
 
 		groupIDs := filterOutDefaultGroupIDs(allGroupIDs, groupIDToName)
 
 		// mirrors the "omit the resource entirely if nothing is left to
-		// manage" behavior in the generate.go "dbtcloud_user_groups" case.
+		// manage" behavior of fetchUserGroups.
 		if len(groupIDs) == 0 {
 			continue
 		}
@@ -384,14 +421,14 @@ func TestGenerate_UserGroupsHCLExcludesDefaultGroups(t *testing.T) {
 	assert.NotContains(t, fullOutput, ", 2]")
 	assert.NotContains(t, fullOutput, "= [3,")
 	assert.NotContains(t, fullOutput, ", 3]")
-// TestGenerate_ComputeResourceLabelPanicsOnMissingID locks in the existing
-// panic behavior for resources with no id and no override - this is the
-// pre-existing guard against silently generating an unlabelled resource
-// block, and must keep working for every currently-supported id-keyed
-// resource type.
-func TestGenerate_ComputeResourceLabelPanicsOnMissingID(t *testing.T) {
+}
+
+// TestGenerate_RegisterResourcePanicsOnDuplicate guards the registry against
+// two files registering the same resource type, the second one would
+// silently replace the first.
+func TestGenerate_RegisterResourcePanicsOnDuplicate(t *testing.T) {
 	assert.Panics(t, func() {
-		computeResourceLabel("dbtcloud_project", map[string]interface{}{}, "")
+		registerResource(resourceHandlers["dbtcloud_project"])
 	})
 }
 
@@ -415,7 +452,7 @@ func fabricatedAccountFeaturesPayload() map[string]interface{} {
 
 // TestGenerate_AccountFeaturesHCLEmission feeds a fabricated account features
 // payload through the same label-derivation and attribute-emission
-// primitives (computeResourceLabel, writeAttrLine) that generate.go's
+// primitives (resourceLabel, writeAttrLine) that generate.go's
 // schema-driven loop uses for every resource, and asserts that the resulting
 // HCL carries the singleton resource label and the expected boolean
 // attributes. This does not require a live account or the Terraform provider
@@ -425,11 +462,11 @@ func fabricatedAccountFeaturesPayload() map[string]interface{} {
 func TestGenerate_AccountFeaturesHCLEmission(t *testing.T) {
 	features := fabricatedAccountFeaturesPayload()
 
-	resourceLabel := computeResourceLabel("dbtcloud_account_features", features, "account_features")
-	assert.Equal(t, "terraform_managed_resource_account_features", resourceLabel)
+	label := resourceLabel(resourceHandlers["dbtcloud_account_features"], features)
+	assert.Equal(t, "terraform_managed_resource_account_features", label)
 
 	f := hclwrite.NewEmptyFile()
-	resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_account_features", resourceLabel}).Body()
+	resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_account_features", label}).Body()
 
 	attrNames := make([]string, 0, len(features))
 	for k := range features {
@@ -492,6 +529,12 @@ func fabricatedProfilePayload() map[string]any {
 	}
 }
 
+func decodeProfile(t *testing.T, payload map[string]any) dbtcloud.Profile {
+	profile, err := dbtcloud.Decode[dbtcloud.Profile](payload)
+	require.NoError(t, err)
+	return profile
+}
+
 // TestGenerate_TransformProfileForGenerate covers the dbtcloud_profile
 // generate-time transform: the composite id folding (project_id into "id",
 // plain id preserved as "profile_id") must happen regardless of linking, and
@@ -500,11 +543,11 @@ func fabricatedProfilePayload() map[string]any {
 // --linked-resource-types.
 func TestGenerate_TransformProfileForGenerate(t *testing.T) {
 	t.Run("no linking: ids stay numeric except the folded composite id", func(t *testing.T) {
-		profile := fabricatedProfilePayload()
+		profile := decodeProfile(t, fabricatedProfilePayload())
 		got := transformProfileForGenerate(profile)
 
 		assert.Equal(t, "5_10", got["id"])
-		assert.Equal(t, float64(10), got["profile_id"])
+		assert.Equal(t, 10, got["profile_id"])
 		assert.Equal(t, float64(5), got["project_id"])
 		assert.Equal(t, float64(20), got["connection_id"])
 		assert.Equal(t, float64(30), got["credentials_id"])
@@ -513,18 +556,18 @@ func TestGenerate_TransformProfileForGenerate(t *testing.T) {
 	t.Run("linking dbtcloud_project and dbtcloud_global_connection rewrites references", func(t *testing.T) {
 		withLinkedResources(t, []string{"dbtcloud_project", "dbtcloud_global_connection"})
 
-		profile := fabricatedProfilePayload()
+		profile := decodeProfile(t, fabricatedProfilePayload())
 		got := transformProfileForGenerate(profile)
 
 		assert.Equal(t, "5_10", got["id"], "the composite id must still be derived from the original numeric project_id")
-		assert.Contains(t, got["project_id"], "dbtcloud_project.terraform_managed_resource_5.id")
-		assert.Contains(t, got["connection_id"], "dbtcloud_global_connection.terraform_managed_resource_20.id")
+		assert.Contains(t, fmt.Sprint(got["project_id"]), "dbtcloud_project.terraform_managed_resource_5.id")
+		assert.Contains(t, fmt.Sprint(got["connection_id"]), "dbtcloud_global_connection.terraform_managed_resource_20.id")
 	})
 
 	t.Run("linking dbtcloud_snowflake_credential without embedded credentials type falls back to TBD", func(t *testing.T) {
 		withLinkedResources(t, []string{"dbtcloud_snowflake_credential"})
 
-		profile := fabricatedProfilePayload()
+		profile := decodeProfile(t, fabricatedProfilePayload())
 		got := transformProfileForGenerate(profile)
 
 		assert.Equal(t, "---TBD---", got["credentials_id"])
@@ -533,27 +576,28 @@ func TestGenerate_TransformProfileForGenerate(t *testing.T) {
 	t.Run("linking dbtcloud_snowflake_credential with embedded credentials type resolves the reference", func(t *testing.T) {
 		withLinkedResources(t, []string{"dbtcloud_snowflake_credential"})
 
-		profile := fabricatedProfilePayload()
-		profile["credentials"] = map[string]any{"type": "snowflake", "adapter_version": ""}
-		got := transformProfileForGenerate(profile)
+		payload := fabricatedProfilePayload()
+		payload["credentials"] = map[string]any{"type": "snowflake", "adapter_version": ""}
+		got := transformProfileForGenerate(decodeProfile(t, payload))
 
-		assert.Contains(t, got["credentials_id"], "dbtcloud_snowflake_credential.terraform_managed_resource_30.credential_id")
+		assert.Contains(t, fmt.Sprint(got["credentials_id"]), "dbtcloud_snowflake_credential.terraform_managed_resource_30.credential_id")
 	})
 }
 
 // TestGenerate_ProfileHCLEmission feeds a fabricated profile payload through
 // the same label-derivation and attribute-emission primitives
-// (computeResourceLabel, writeAttrLine) that generate.go's schema-driven
+// (resourceLabel, writeAttrLine) that generate.go's schema-driven
 // loop uses for every resource, asserting the resulting HCL carries the
 // project-scoped composite label and the confirmed attribute names.
 func TestGenerate_ProfileHCLEmission(t *testing.T) {
-	profile := transformProfileForGenerate(fabricatedProfilePayload())
+	decodedProfile := decodeProfile(t, fabricatedProfilePayload())
+	profile := transformProfileForGenerate(decodedProfile)
 
-	resourceLabel := computeResourceLabel("dbtcloud_profile", profile, "")
-	assert.Equal(t, "terraform_managed_resource_5_10", resourceLabel)
+	label := resourceLabel(resourceHandlers["dbtcloud_profile"], decodedProfile)
+	assert.Equal(t, "terraform_managed_resource_5_10", label)
 
 	f := hclwrite.NewEmptyFile()
-	resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_profile", resourceLabel}).Body()
+	resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_profile", label}).Body()
 
 	// profile_id is a computed-only attribute in the real provider schema
 	// (like credential_id on the credential resources), so it - like id -
@@ -577,7 +621,7 @@ func TestGenerate_ProfileHCLEmission(t *testing.T) {
 // environment carries a primary_profile_id (profile-based account) or the
 // legacy connection_id/credentials_id/extended_attributes_id trio
 // (non-profile account) - never both, matching what the real API returns.
-func fabricatedEnvironmentPayload(withProfile bool) map[string]any {
+func fabricatedEnvironmentPayload(t *testing.T, withProfile bool) dbtcloud.Environment {
 	env := map[string]any{
 		"id":         float64(456),
 		"project_id": float64(71),
@@ -590,7 +634,9 @@ func fabricatedEnvironmentPayload(withProfile bool) map[string]any {
 		env["credentials_id"] = float64(30)
 		env["extended_attributes_id"] = float64(40)
 	}
-	return env
+	environment, err := dbtcloud.Decode[dbtcloud.Environment](env)
+	require.NoError(t, err)
+	return environment
 }
 
 // TestGenerate_TransformEnvironmentForGenerate_EitherOr is the core
@@ -601,7 +647,7 @@ func fabricatedEnvironmentPayload(withProfile bool) map[string]any {
 // legacy trio present at once.
 func TestGenerate_TransformEnvironmentForGenerate_EitherOr(t *testing.T) {
 	t.Run("profile-bound environment: primary_profile_id present, legacy trio absent", func(t *testing.T) {
-		env := fabricatedEnvironmentPayload(true)
+		env := fabricatedEnvironmentPayload(t, true)
 		got := transformEnvironmentForGenerate(env)
 
 		assert.Contains(t, got, "primary_profile_id")
@@ -615,22 +661,22 @@ func TestGenerate_TransformEnvironmentForGenerate_EitherOr(t *testing.T) {
 	t.Run("profile-bound environment with linking: primary_profile_id resolves to the profile resource, legacy trio still absent", func(t *testing.T) {
 		withLinkedResources(t, []string{"dbtcloud_profile"})
 
-		env := fabricatedEnvironmentPayload(true)
+		env := fabricatedEnvironmentPayload(t, true)
 		got := transformEnvironmentForGenerate(env)
 
-		assert.Contains(t, got["primary_profile_id"], "dbtcloud_profile.terraform_managed_resource_71_10.profile_id")
+		assert.Contains(t, fmt.Sprint(got["primary_profile_id"]), "dbtcloud_profile.terraform_managed_resource_71_10.profile_id")
 		for _, legacyField := range []string{"connection_id", "credential_id", "credentials_id", "extended_attributes_id"} {
 			assert.NotContains(t, got, legacyField)
 		}
 	})
 
 	t.Run("non-profile environment: legacy trio unchanged, primary_profile_id absent", func(t *testing.T) {
-		env := fabricatedEnvironmentPayload(false)
+		env := fabricatedEnvironmentPayload(t, false)
 		got := transformEnvironmentForGenerate(env)
 
 		assert.NotContains(t, got, "primary_profile_id")
 		assert.Equal(t, float64(20), got["connection_id"])
-		assert.Equal(t, float64(30), got["credential_id"], "credentials_id is renamed to credential_id, matching pre-existing behavior")
+		assert.Equal(t, 30, got["credential_id"], "credentials_id is renamed to credential_id, matching pre-existing behavior")
 		assert.Equal(t, float64(40), got["extended_attributes_id"])
 	})
 }
@@ -642,7 +688,7 @@ func TestGenerate_TransformEnvironmentForGenerate_EitherOr(t *testing.T) {
 // relationship dbtcloud_job_completion_trigger reproduces as a standalone
 // resource. withCondition controls whether the condition is present at all,
 // matching jobs that have no completion trigger configured.
-func fabricatedJobWithCompletionTriggerPayload(withCondition bool) map[string]any {
+func fabricatedJobWithCompletionTriggerPayload(t *testing.T, withCondition bool) dbtcloud.Job {
 	job := map[string]any{
 		"id":         float64(456),
 		"project_id": float64(71),
@@ -656,7 +702,9 @@ func fabricatedJobWithCompletionTriggerPayload(withCondition bool) map[string]an
 			},
 		}
 	}
-	return job
+	decodedJob, err := dbtcloud.Decode[dbtcloud.Job](job)
+	require.NoError(t, err)
+	return decodedJob
 }
 
 // TestGenerate_TransformJobCompletionTriggerForGenerate covers the
@@ -668,39 +716,39 @@ func fabricatedJobWithCompletionTriggerPayload(withCondition bool) map[string]an
 // corresponding resource type is in --linked-resource-types.
 func TestGenerate_TransformJobCompletionTriggerForGenerate(t *testing.T) {
 	t.Run("job with no completion trigger condition is skipped", func(t *testing.T) {
-		job := fabricatedJobWithCompletionTriggerPayload(false)
+		job := fabricatedJobWithCompletionTriggerPayload(t, false)
 		_, ok := transformJobCompletionTriggerForGenerate(job)
 		assert.False(t, ok)
 	})
 
 	t.Run("no linking: statuses are mapped to text, ids stay numeric", func(t *testing.T) {
-		job := fabricatedJobWithCompletionTriggerPayload(true)
+		job := fabricatedJobWithCompletionTriggerPayload(t, true)
 		got, ok := transformJobCompletionTriggerForGenerate(job)
 		assert.True(t, ok)
 
-		assert.Equal(t, float64(456), got["id"], "id is the downstream job's own plain numeric id")
-		assert.Equal(t, float64(456), got["job_id"])
-		assert.Equal(t, float64(123), got["trigger_job_id"])
-		assert.Equal(t, float64(99), got["project_id"])
+		assert.Equal(t, 456, got["id"], "id is the downstream job's own plain numeric id")
+		assert.Equal(t, 456, got["job_id"])
+		assert.Equal(t, 123, got["trigger_job_id"])
+		assert.Equal(t, 99, got["project_id"])
 		assert.Equal(t, []string{"success", "error"}, got["statuses"], "numeric status codes must be mapped to text, not left raw")
 	})
 
 	t.Run("linking dbtcloud_job and dbtcloud_project rewrites references", func(t *testing.T) {
 		withLinkedResources(t, []string{"dbtcloud_job", "dbtcloud_project"})
 
-		job := fabricatedJobWithCompletionTriggerPayload(true)
+		job := fabricatedJobWithCompletionTriggerPayload(t, true)
 		got, ok := transformJobCompletionTriggerForGenerate(job)
 		assert.True(t, ok)
 
-		assert.Contains(t, got["job_id"], "dbtcloud_job.terraform_managed_resource_456.id", "job_id links to the downstream job")
-		assert.Contains(t, got["trigger_job_id"], "dbtcloud_job.terraform_managed_resource_123.id", "trigger_job_id links to the upstream job")
-		assert.Contains(t, got["project_id"], "dbtcloud_project.terraform_managed_resource_99.id")
+		assert.Contains(t, fmt.Sprint(got["job_id"]), "dbtcloud_job.terraform_managed_resource_456.id", "job_id links to the downstream job")
+		assert.Contains(t, fmt.Sprint(got["trigger_job_id"]), "dbtcloud_job.terraform_managed_resource_123.id", "trigger_job_id links to the upstream job")
+		assert.Contains(t, fmt.Sprint(got["project_id"]), "dbtcloud_project.terraform_managed_resource_99.id")
 	})
 }
 
 // TestGenerate_JobCompletionTriggerHCLEmission feeds a fabricated
 // job-with-trigger payload through the same label-derivation and
-// attribute-emission primitives (computeResourceLabel, writeAttrLine) that
+// attribute-emission primitives (resourceLabel, writeAttrLine) that
 // generate.go's schema-driven loop uses for every resource, and asserts that
 // the resulting HCL carries the flat job_id/trigger_job_id/project_id/
 // statuses attributes (mapped to text status strings, not numeric codes)
@@ -709,15 +757,15 @@ func TestGenerate_TransformJobCompletionTriggerForGenerate(t *testing.T) {
 func TestGenerate_JobCompletionTriggerHCLEmission(t *testing.T) {
 	withLinkedResources(t, []string{"dbtcloud_job"})
 
-	job := fabricatedJobWithCompletionTriggerPayload(true)
+	job := fabricatedJobWithCompletionTriggerPayload(t, true)
 	trigger, ok := transformJobCompletionTriggerForGenerate(job)
 	assert.True(t, ok)
 
-	resourceLabel := computeResourceLabel("dbtcloud_job_completion_trigger", trigger, "")
-	assert.Equal(t, "terraform_managed_resource_456", resourceLabel)
+	label := resourceLabel(resourceHandlers["dbtcloud_job_completion_trigger"], job)
+	assert.Equal(t, "terraform_managed_resource_456", label)
 
 	f := hclwrite.NewEmptyFile()
-	resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_job_completion_trigger", resourceLabel}).Body()
+	resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_job_completion_trigger", label}).Body()
 
 	for _, attrName := range []string{"job_id", "trigger_job_id", "project_id", "statuses"} {
 		writeAttrLine(attrName, trigger[attrName], "", resource)
@@ -739,14 +787,16 @@ func TestGenerate_JobCompletionTriggerHCLEmission(t *testing.T) {
 // job-scoped environment variable override. name controls whether the
 // override matches the DBT_ENV_SECRET_ convention that must externalize its
 // value to a Terraform variable.
-func fabricatedEnvVarJobOverridePayload(name string) map[string]any {
-	return map[string]any{
+func fabricatedEnvVarJobOverridePayload(t *testing.T, name string) dbtcloud.EnvironmentVariableJobOverride {
+	override, err := dbtcloud.Decode[dbtcloud.EnvironmentVariableJobOverride](map[string]any{
 		"name":                                 name,
 		"project_id":                           float64(71),
 		"job_definition_id":                    float64(456),
 		"environment_variable_job_override_id": float64(789),
 		"raw_value":                            "my-value",
-	}
+	})
+	require.NoError(t, err)
+	return override
 }
 
 // TestGenerate_TransformEnvironmentVariableJobOverrideForGenerate covers the
@@ -773,7 +823,7 @@ func TestGenerate_TransformEnvironmentVariableJobOverrideForGenerate(t *testing.
 
 	t.Run("no linking, non-secret name: id is folded, raw_value stays literal", func(t *testing.T) {
 		AllTFVars = []tfVar{}
-		override := fabricatedEnvVarJobOverridePayload("MY_PLAIN_VAR")
+		override := fabricatedEnvVarJobOverridePayload(t, "MY_PLAIN_VAR")
 		got := transformEnvironmentVariableJobOverrideForGenerate(override)
 
 		assert.Equal(t, "71_456_789", got["id"])
@@ -785,32 +835,32 @@ func TestGenerate_TransformEnvironmentVariableJobOverrideForGenerate(t *testing.
 
 	t.Run("secret name: raw_value is externalized to a var.* reference", func(t *testing.T) {
 		AllTFVars = []tfVar{}
-		override := fabricatedEnvVarJobOverridePayload("DBT_ENV_SECRET_TOKEN")
+		override := fabricatedEnvVarJobOverridePayload(t, "DBT_ENV_SECRET_TOKEN")
 		got := transformEnvironmentVariableJobOverrideForGenerate(override)
 
 		assert.Equal(t, "71_456_789", got["id"], "the composite id must still be folded for a secret-named override")
-		assert.Contains(t, got["raw_value"], "var.", "a secret-named override's value must be externalized")
-		assert.NotContains(t, got["raw_value"], "my-value", "the literal secret value must not leak into the output")
+		assert.Contains(t, fmt.Sprint(got["raw_value"]), "var.", "a secret-named override's value must be externalized")
+		assert.NotContains(t, fmt.Sprint(got["raw_value"]), "my-value", "the literal secret value must not leak into the output")
 		assert.Len(t, AllTFVars, 1, "exactly one Terraform variable must be registered for the secret override")
-		assert.Contains(t, got["raw_value"], AllTFVars[0].varName)
+		assert.Contains(t, fmt.Sprint(got["raw_value"]), AllTFVars[0].varName)
 	})
 
 	t.Run("linking dbtcloud_job and dbtcloud_project rewrites references", func(t *testing.T) {
 		AllTFVars = []tfVar{}
 		withLinkedResources(t, []string{"dbtcloud_job", "dbtcloud_project"})
 
-		override := fabricatedEnvVarJobOverridePayload("MY_PLAIN_VAR")
+		override := fabricatedEnvVarJobOverridePayload(t, "MY_PLAIN_VAR")
 		got := transformEnvironmentVariableJobOverrideForGenerate(override)
 
 		assert.Equal(t, "71_456_789", got["id"], "the composite id must still be derived from the original numeric ids")
-		assert.Contains(t, got["job_definition_id"], "dbtcloud_job.terraform_managed_resource_456.id")
-		assert.Contains(t, got["project_id"], "dbtcloud_project.terraform_managed_resource_71.id")
+		assert.Contains(t, fmt.Sprint(got["job_definition_id"]), "dbtcloud_job.terraform_managed_resource_456.id")
+		assert.Contains(t, fmt.Sprint(got["project_id"]), "dbtcloud_project.terraform_managed_resource_71.id")
 	})
 }
 
 // TestGenerate_EnvironmentVariableJobOverrideHCLEmission feeds fabricated
 // override payloads through the same label-derivation and attribute-emission
-// primitives (computeResourceLabel, writeAttrLine) that generate.go's
+// primitives (resourceLabel, writeAttrLine) that generate.go's
 // schema-driven loop uses for every resource, and asserts the resulting HCL
 // carries the project-scoped composite label, a linked job_definition_id,
 // and - critically - that a secret-named override's raw_value is a var.*
@@ -829,13 +879,14 @@ func TestGenerate_EnvironmentVariableJobOverrideHCLEmission(t *testing.T) {
 		AllTFVars = []tfVar{}
 		withLinkedResources(t, []string{"dbtcloud_job"})
 
-		override := transformEnvironmentVariableJobOverrideForGenerate(fabricatedEnvVarJobOverridePayload("MY_PLAIN_VAR"))
+		decodedOverride := fabricatedEnvVarJobOverridePayload(t, "MY_PLAIN_VAR")
+		override := transformEnvironmentVariableJobOverrideForGenerate(decodedOverride)
 
-		resourceLabel := computeResourceLabel("dbtcloud_environment_variable_job_override", override, "")
-		assert.Equal(t, "terraform_managed_resource_71_456_789", resourceLabel)
+		label := resourceLabel(resourceHandlers["dbtcloud_environment_variable_job_override"], decodedOverride)
+		assert.Equal(t, "terraform_managed_resource_71_456_789", label)
 
 		f := hclwrite.NewEmptyFile()
-		resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_environment_variable_job_override", resourceLabel}).Body()
+		resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_environment_variable_job_override", label}).Body()
 		for _, attrName := range []string{"name", "project_id", "job_definition_id", "raw_value"} {
 			writeAttrLine(attrName, override[attrName], "", resource)
 		}
@@ -850,12 +901,13 @@ func TestGenerate_EnvironmentVariableJobOverrideHCLEmission(t *testing.T) {
 		AllTFVars = []tfVar{}
 		withLinkedResources(t, []string{"dbtcloud_job"})
 
-		override := transformEnvironmentVariableJobOverrideForGenerate(fabricatedEnvVarJobOverridePayload("DBT_ENV_SECRET_TOKEN"))
+		decodedOverride := fabricatedEnvVarJobOverridePayload(t, "DBT_ENV_SECRET_TOKEN")
+		override := transformEnvironmentVariableJobOverrideForGenerate(decodedOverride)
 
-		resourceLabel := computeResourceLabel("dbtcloud_environment_variable_job_override", override, "")
+		label := resourceLabel(resourceHandlers["dbtcloud_environment_variable_job_override"], decodedOverride)
 
 		f := hclwrite.NewEmptyFile()
-		resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_environment_variable_job_override", resourceLabel}).Body()
+		resource := f.Body().AppendNewBlock("resource", []string{"dbtcloud_environment_variable_job_override", label}).Body()
 		for _, attrName := range []string{"name", "project_id", "job_definition_id", "raw_value"} {
 			writeAttrLine(attrName, override[attrName], "", resource)
 		}
@@ -865,3 +917,79 @@ func TestGenerate_EnvironmentVariableJobOverrideHCLEmission(t *testing.T) {
 		assert.NotContains(t, output, `"my-value"`, "the literal secret value must never be emitted")
 	})
 }
+
+func TestGenerate_PrintErrorSummary(t *testing.T) {
+	originalRunErrors := runErrors
+	defer func() { runErrors = originalRunErrors }()
+	runErrors = nil
+
+	var buf strings.Builder
+	assert.Equal(t, 0, printErrorSummary(&buf))
+	assert.Empty(t, buf.String())
+
+	assert.False(t, recordError("dbtcloud_group", nil))
+	assert.True(t, recordError("dbtcloud_webhook", fmt.Errorf("403 Forbidden")))
+	// per project errors are joined by the client and listed one per line
+	recordError("dbtcloud_snowflake_credential", errors.Join(fmt.Errorf("project 1: 403 Forbidden"), fmt.Errorf("project 2: 500 Internal Server Error")))
+	// genimport records the same error in generate and in import
+	recordError("dbtcloud_webhook", fmt.Errorf("403 Forbidden"))
+
+	assert.Equal(t, 3, printErrorSummary(&buf))
+	assert.Equal(t, heredoc.Doc(`
+
+		3 error(s) while fetching data from dbt Cloud, the resources below are missing from the output:
+		  - dbtcloud_webhook: 403 Forbidden
+		  - dbtcloud_snowflake_credential: project 1: 403 Forbidden
+		  - dbtcloud_snowflake_credential: project 2: 500 Internal Server Error
+	`), buf.String())
+}
+
+// TestGenerate_TransformExtendedAttributes checks that the extended
+// attributes are written as an HCL object in jsonencode instead of an escaped
+// JSON string.
+func TestGenerate_TransformExtendedAttributes(t *testing.T) {
+	extendedAttributes := dbtcloud.ExtendedAttributes{
+		ID:        7,
+		ProjectID: 1,
+		ExtendedAttributes: map[string]any{
+			"type":    "databricks",
+			"catalog": "main",
+			"http_headers": map[string]any{
+				"X-Team": "analytics",
+			},
+		},
+	}
+	extendedAttributes.Raw = map[string]any{"id": 7, "project_id": 1}
+
+	got := resourceHandlers["dbtcloud_extended_attributes"].Transform(extendedAttributes, &accountData{})
+
+	f := hclwrite.NewEmptyFile()
+	writeAttrLine("extended_attributes", got["extended_attributes"], "", f.Body())
+	assert.Equal(t, heredoc.Doc(`
+		extended_attributes = jsonencode({
+		  catalog = "main"
+		  http_headers = {
+		    X-Team = "analytics"
+		  }
+		  type = "databricks"
+		})
+	`), string(hclwrite.Format(f.Bytes())))
+}
+
+// TestGenerate_TransformInvalidExtendedAttributes checks that the extended
+// attributes that can't be converted are recorded as errors instead of
+// stopping the run.
+func TestGenerate_TransformInvalidExtendedAttributes(t *testing.T) {
+	originalRunErrors := runErrors
+	defer func() { runErrors = originalRunErrors }()
+	runErrors = nil
+
+	extendedAttributes := dbtcloud.ExtendedAttributes{ID: 7, ProjectID: 1, ExtendedAttributes: map[string]any{"threads": math.Inf(1)}}
+	extendedAttributes.Raw = map[string]any{"id": 7, "project_id": 1}
+
+	got := resourceHandlers["dbtcloud_extended_attributes"].Transform(extendedAttributes, &accountData{})
+	assert.Nil(t, got["extended_attributes"])
+	require.Len(t, runErrors, 1)
+	assert.Equal(t, "dbtcloud_extended_attributes", runErrors[0].resourceType)
+	assert.ErrorContains(t, runErrors[0].err, "extended attributes 7")
+}
@@ -2,9 +2,12 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"time"
+	"sort"
+	"strings"
 
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
 )
 
@@ -15,54 +18,145 @@ func init() {
 var genimportCmd = &cobra.Command{
 	Use:    "genimport",
 	Short:  "Generate Terraform resources configuration and import commands for dbt Cloud resources",
-	Long:   "Combines the functionality of 'generate' and 'import' commands to create Terraform configuration and corresponding import commands in one step",
+	Long:   "Combines the functionality of 'generate' and 'import' commands to create Terraform configuration and corresponding import commands in one step. The addresses imported are checked against the resources generated and the command fails if any of them doesn't match",
 	Run:    runGenImport(),
 	PreRun: sharedPreRun,
 }
 
 func runGenImport() func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
-		// Temporarily redirect output file for generate step
-		originalOutputFile := outputFile
-		if outputFile != "" {
-			// Use a temporary file for generate output
-			outputFile = outputFile + fmt.Sprintf(".%d.temp", time.Now().UnixNano())
+		output := genImportConfig(cmd)
+		if output == nil {
+			return
 		}
 
-		// Run generate
-		generateResources()(cmd, args)
-
-		// Store generate output if writing to file
-		var generateOutput string
-		if originalOutputFile != "" {
-			data, err := os.ReadFile(outputFile)
+		if converge {
+			drifts, err := convergeConfig(cmd.Context(), output)
 			if err != nil {
-				log.Fatal(err)
+				log.Fatalf("failed to converge the generated config: %v", err)
+			}
+			if len(drifts) > 0 {
+				printDrift(cmd.OutOrStderr(), drifts)
+				log.Warnf("the plan still changes %d resources after --converge, they need to be edited", len(drifts))
 			}
-			generateOutput = string(data)
-			// Clean up temp file
-			os.Remove(outputFile)
-			// Restore original output file path
-			outputFile = originalOutputFile
 		}
 
-		// Run import
-		runImport()(cmd, args)
+		output, err := formatConfig(output)
+		if err != nil {
+			log.Fatalf("failed to write output: %v", err)
+		}
+		if err := output.write(); err != nil {
+			log.Fatalf("failed to write output: %v", err)
+		}
+	}
+}
 
-		// If we're writing to a file, append generate output before import output
-		if outputFile != "" {
-			data, err := os.ReadFile(outputFile)
-			if err != nil {
-				log.Fatal(err)
-			}
-			importOutput := string(data)
+// genImportConfig returns the generated config followed by the imports, after
+// checking that they match, or nil if the resource types selected can't be
+// generated or imported.
+func genImportConfig(cmd *cobra.Command) *outputFiles {
+	generated, account := generateConfig(cmd)
+	if generated == nil {
+		return nil
+	}
 
-			// Combine outputs and write to file
-			combinedOutput := generateOutput + "\n" + importOutput
-			err = os.WriteFile(outputFile, []byte(combinedOutput), 0644)
-			if err != nil {
-				log.Fatal(err)
-			}
+	// the imports of the items generated, without fetching them again
+	imports := writeImports(account)
+
+	if err := checkImportAddresses(generated.bytes(), imports.bytes()); err != nil {
+		log.Fatal(err)
+	}
+
+	generated.append(imports)
+	return generated
+}
+
+// checkImportAddresses cross-references the addresses of the resources in the
+// generated config with the ones the imports point to, either in import
+// blocks or in `terraform import` commands, and returns an error listing the
+// resources without import and the imports without resource.
+func checkImportAddresses(generated, imports []byte) error {
+	resourceAddresses, err := generatedResourceAddresses(generated)
+	if err != nil {
+		return err
+	}
+
+	var importAddresses []string
+	if useModernImportBlock {
+		importAddresses, err = importBlockAddresses(imports)
+		if err != nil {
+			return err
+		}
+	} else {
+		importAddresses = importCommandAddresses(imports)
+	}
+
+	notImported, notGenerated := lo.Difference(lo.Uniq(resourceAddresses), lo.Uniq(importAddresses))
+	if len(notImported) == 0 && len(notGenerated) == 0 {
+		return nil
+	}
+	sort.Strings(notImported)
+	sort.Strings(notGenerated)
+
+	var msg strings.Builder
+	msg.WriteString("the generated resources and the imports don't match")
+	for _, address := range notImported {
+		fmt.Fprintf(&msg, "\n  - %s is generated but not imported", address)
+	}
+	for _, address := range notGenerated {
+		fmt.Fprintf(&msg, "\n  - %s is imported but not generated", address)
+	}
+	return fmt.Errorf("%s", msg.String())
+}
+
+// generatedResourceAddresses returns the addresses of the resource blocks in
+// the generated config.
+func generatedResourceAddresses(generated []byte) ([]string, error) {
+	file, diags := hclsyntax.ParseConfig(generated, "generated.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse the generated config: %s", diags.Error())
+	}
+
+	addresses := []string{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type == "resource" && len(block.Labels) == 2 {
+			addresses = append(addresses, fmt.Sprintf("%s.%s", block.Labels[0], block.Labels[1]))
+		}
+	}
+	return addresses, nil
+}
+
+// importBlockAddresses returns the `to` addresses of the import blocks.
+func importBlockAddresses(imports []byte) ([]string, error) {
+	file, diags := hclsyntax.ParseConfig(imports, "imports.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse the import blocks: %s", diags.Error())
+	}
+
+	addresses := []string{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "import" {
+			continue
+		}
+		to, ok := block.Body.Attributes["to"]
+		if !ok {
+			return nil, fmt.Errorf("import block without \"to\" at %s", block.DefRange())
+		}
+		addresses = append(addresses, strings.TrimSpace(string(to.Expr.Range().SliceBytes(imports))))
+	}
+	return addresses, nil
+}
+
+// importCommandAddresses returns the addresses of the `terraform import`
+// commands, one per line.
+func importCommandAddresses(imports []byte) []string {
+	addresses := []string{}
+	for _, line := range strings.Split(string(imports), "\n") {
+		fields := strings.Fields(strings.TrimPrefix(line, terraformImportCmdPrefix))
+		if !strings.HasPrefix(line, terraformImportCmdPrefix) || len(fields) == 0 {
+			continue
 		}
+		addresses = append(addresses, fields[0])
 	}
+	return addresses
 }
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const genimportTestConfig = `
+resource "dbtcloud_project" "terraform_managed_resource_1" {
+  name = "Analytics"
+}
+
+resource "dbtcloud_account_features" "terraform_managed_resource_account_features" {
+  advanced_ci = true
+}
+
+data "dbtcloud_users" "all" {
+}
+`
+
+func TestGenImport_CheckImportAddresses(t *testing.T) {
+	defer func(modern bool) { useModernImportBlock = modern }(useModernImportBlock)
+
+	cases := map[string]struct {
+		modern  bool
+		imports string
+		errs    []string
+	}{
+		"matching import blocks": {
+			modern: true,
+			imports: `
+import {
+  to = dbtcloud_project.terraform_managed_resource_1
+  id = "1"
+}
+
+import {
+  to = dbtcloud_account_features.terraform_managed_resource_account_features
+  id = "12345"
+}
+`,
+		},
+		"matching import commands": {
+			modern: false,
+			imports: "terraform import dbtcloud_project.terraform_managed_resource_1 1\n" +
+				"terraform import dbtcloud_account_features.terraform_managed_resource_account_features 12345\n",
+		},
+		"orphans in both directions": {
+			modern: true,
+			imports: `
+import {
+  to = dbtcloud_project.terraform_managed_resource_1
+  id = "1"
+}
+
+import {
+  to = dbtcloud_account_features.terraform_managed_resource_12345
+  id = "12345"
+}
+`,
+			errs: []string{
+				"dbtcloud_account_features.terraform_managed_resource_account_features is generated but not imported",
+				"dbtcloud_account_features.terraform_managed_resource_12345 is imported but not generated",
+			},
+		},
+		"orphan import command": {
+			modern: false,
+			imports: "terraform import dbtcloud_project.terraform_managed_resource_1 1\n" +
+				"terraform import dbtcloud_account_features.terraform_managed_resource_account_features 12345\n" +
+				"terraform import dbtcloud_user_groups.terraform_managed_resource_42 42\n",
+			errs: []string{
+				"dbtcloud_user_groups.terraform_managed_resource_42 is imported but not generated",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			useModernImportBlock = tc.modern
+
+			err := checkImportAddresses([]byte(genimportTestConfig), []byte(tc.imports))
+			if len(tc.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, msg := range tc.errs {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+// TestGenImport_AccountFeaturesAddress checks that the account features are
+// imported to the address they are generated at, which doesn't depend on the
+// account ID.
+func TestGenImport_AccountFeaturesAddress(t *testing.T) {
+	handler := resourceHandlers["dbtcloud_account_features"]
+	features := map[string]any{"id": float64(12345), "advanced_ci": true}
+
+	assert.Equal(t, "dbtcloud_account_features.terraform_managed_resource_account_features", resourceAddress(handler, features))
+	assert.Equal(t, "12345", handler.ImportID(features))
+}
+
+// TestGenImport_FetchOnce checks that genimport sends the requests of a single
+// fetch of the account, the config and the imports being written from the
+// same data.
+func TestGenImport_FetchOnce(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, listResponse(`{"id": 10, "name": "Analytics"}`))
+	}))
+	defer server.Close()
+	origClient := dbtCloudClient
+	defer func() { dbtCloudClient = origClient }()
+	dbtCloudClient = dbtcloud.NewDbtCloudHTTPClient(server.URL, "token", "9999", nil)
+	// every request reaches the API
+	dbtCloudClient.Cache = nil
+
+	defer func(types []string, schemaFile string, modern bool) {
+		resourceTypes = types
+		providerSchemaFile = schemaFile
+		useModernImportBlock = modern
+	}(resourceTypes, providerSchemaFile, useModernImportBlock)
+	resourceTypes = []string{"dbtcloud_project"}
+	providerSchemaFile = filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(providerSchemaFile, []byte(schemaTestProvidersSchema), 0644))
+	useModernImportBlock = true
+
+	fetchAccount(context.Background(), []ResourceHandler{resourceHandlers["dbtcloud_project"]})
+	fetchRequests := requests
+
+	requests = 0
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetErr(&bytes.Buffer{})
+	output := genImportConfig(cmd)
+	require.NotNil(t, output)
+	assert.Contains(t, output.file("").String(), "to = dbtcloud_project.terraform_managed_resource_10")
+	assert.Equal(t, fetchRequests, requests)
+}
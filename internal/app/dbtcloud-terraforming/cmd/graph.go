@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+}
+
+var graphFormat string
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Output the links between the resources as a Graphviz DOT, Mermaid or JSON graph",
+	Long: `Fetches the selected resource types (all of them if --resource-types is not set) and outputs the links between
+their resources, e.g. from a job to its environment, in the --graph-format format. The edges go from a resource to
+the ones it depends on, like in terraform graph. The dangling edges, to resources that are not generated (e.g. the
+deleted jobs of a webhook or the jobs of the projects not selected with --projects), are highlighted.`,
+	Run:    runGraph(),
+	PreRun: sharedPreRun,
+}
+
+// graphNode is a resource of the graph. The missing ones are linked to but
+// are not generated.
+type graphNode struct {
+	Address      string `json:"address"`
+	ResourceType string `json:"resource_type"`
+	Name         string `json:"name,omitempty"`
+	Missing      bool   `json:"missing,omitempty"`
+}
+
+// graphEdge is the link of the resource From, through its Attribute, to the
+// resource To.
+type graphEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Attribute string `json:"attribute"`
+	Dangling  bool   `json:"dangling,omitempty"`
+}
+
+type resourceGraph struct {
+	Nodes []graphNode `json:"nodes"`
+	Edges []graphEdge `json:"edges"`
+}
+
+func runGraph() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if outputDir != "" {
+			log.Fatal("--output-dir can't be used with graph, use --output")
+		}
+		if !lo.Contains([]string{"dot", "mermaid", "json"}, graphFormat) {
+			log.Fatalf("--graph-format must be either dot, mermaid or json, not %q", graphFormat)
+		}
+
+		if len(resourceTypes) == 0 || (len(resourceTypes) == 1 && resourceTypes[0] == "all") {
+			resourceTypes = resourceTypeNames()
+		}
+
+		if len(excludeResourceTypes) > 0 {
+			resourceTypes = lo.Filter(resourceTypes, func(resourceType string, _ int) bool {
+				return !lo.Contains(excludeResourceTypes, resourceType)
+			})
+		}
+
+		listFilterProjects = viper.GetIntSlice("projects")
+
+		handlers, err := selectedHandlers(resourceTypes, "graph")
+		if err != nil {
+			log.Fatal(err)
+		}
+		handlers = sortHandlersByDependencies(handlers)
+
+		ctx := cmd.Context()
+		data := prefetchAccountData(ctx, handlers)
+		resources := fetchResources(ctx, handlers, data)
+
+		graph := buildGraph(handlers, resources, data)
+		if dangling := lo.CountBy(graph.Edges, func(edge graphEdge) bool { return edge.Dangling }); dangling > 0 {
+			log.Warnf("%d links point to objects that don't exist or are not generated", dangling)
+		}
+
+		writer, closer, err := getOutputWriter()
+		if err != nil {
+			log.Fatalf("failed to write graph: %v", err)
+		}
+		defer closer()
+
+		if err := writeGraph(writer, graph, graphFormat); err != nil {
+			log.Fatalf("failed to write graph: %v", err)
+		}
+	}
+}
+
+// buildGraph returns the graph of the links of the items of the handlers. A
+// link is dangling when the item it points to is not already managed and is
+// not among the ones fetched, when its resource type is selected, or among the
+// objects of the account data otherwise, e.g. a webhook of a deleted job. The
+// links to the resource types that are neither selected nor in the account
+// data can't be checked.
+func buildGraph(handlers []ResourceHandler, resources map[string][]any, data *accountData) resourceGraph {
+	graph := resourceGraph{Nodes: []graphNode{}, Edges: []graphEdge{}}
+	prefetched := prefetchedKeys(handlers, data)
+
+	fetched := map[string]map[string]bool{}
+	for _, handler := range handlers {
+		fetched[handler.ResourceType()] = map[string]bool{}
+		for _, item := range resources[handler.ResourceType()] {
+			fetched[handler.ResourceType()][handler.Label(item)] = true
+			graph.Nodes = append(graph.Nodes, graphNode{
+				Address:      resourceAddress(handler, item),
+				ResourceType: handler.ResourceType(),
+				Name:         handler.Name(item),
+			})
+		}
+	}
+
+	nodes := lo.SliceToMap(graph.Nodes, func(node graphNode) (string, bool) {
+		return node.Address, true
+	})
+	for _, handler := range handlers {
+		for _, item := range resources[handler.ResourceType()] {
+			for _, link := range handler.Links(item, data) {
+				address := fmt.Sprintf("%s.%s", link.resourceType, labelOf(link.resourceType, link.key))
+				managedAddress, managed := managedLabels[link.resourceType][link.key]
+				if managed {
+					address = managedAddress
+				}
+				existing, known := fetched[link.resourceType]
+				if !known {
+					existing, known = prefetched[link.resourceType]
+				}
+				dangling := known && !existing[link.key] && !managed
+
+				if !nodes[address] {
+					nodes[address] = true
+					graph.Nodes = append(graph.Nodes, graphNode{
+						Address:      address,
+						ResourceType: link.resourceType,
+						Missing:      dangling,
+					})
+				}
+				graph.Edges = append(graph.Edges, graphEdge{
+					From:      resourceAddress(handler, item),
+					To:        address,
+					Attribute: link.attribute,
+					Dangling:  dangling,
+				})
+			}
+		}
+	}
+	return graph
+}
+
+// prefetchedKeys returns the labels of the objects of the account data by
+// resource type, for the resource types prefetchAccountData fetched for the
+// handlers.
+func prefetchedKeys(handlers []ResourceHandler, data *accountData) map[string]map[string]bool {
+	dependencies := lo.FlatMap(handlers, func(handler ResourceHandler, _ int) []string {
+		return handler.Dependencies()
+	})
+	keys := func(ids []int) map[string]bool {
+		return lo.SliceToMap(ids, func(id int) (string, bool) { return fmt.Sprint(id), true })
+	}
+
+	// the projects are always fetched
+	prefetched := map[string]map[string]bool{"dbtcloud_project": keys(data.projectIDs())}
+	if lo.Contains(dependencies, "dbtcloud_job") {
+		prefetched["dbtcloud_job"] = keys(data.jobIDs())
+	}
+	if lo.Contains(dependencies, "dbtcloud_environment") {
+		prefetched["dbtcloud_environment"] = keys(lo.Map(data.environments, func(environment dbtcloud.Environment, _ int) int {
+			return environment.ID
+		}))
+	}
+	if lo.Contains(dependencies, "dbtcloud_group") {
+		prefetched["dbtcloud_group"] = keys(lo.Map(data.groups, func(group dbtcloud.Group, _ int) int {
+			return group.ID
+		}))
+	}
+	return prefetched
+}
+
+// writeGraph writes the graph in format, either dot, mermaid or json.
+func writeGraph(w io.Writer, graph resourceGraph, format string) error {
+	switch format {
+	case "dot":
+		return writeDOT(w, graph)
+	case "mermaid":
+		return writeMermaid(w, graph)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	}
+	return fmt.Errorf("--graph-format must be either dot, mermaid or json, not %q", format)
+}
+
+// writeDOT writes the graph for Graphviz, the dangling edges and the missing
+// resources are red and dashed.
+func writeDOT(w io.Writer, graph resourceGraph) error {
+	var b strings.Builder
+	b.WriteString("digraph dbtcloud {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, node := range graph.Nodes {
+		label := dotEscape(node.Address)
+		if node.Name != "" {
+			label += `\n` + dotEscape(node.Name)
+		}
+		attributes := fmt.Sprintf(`label="%s"`, label)
+		if node.Missing {
+			attributes += ", color=red, style=dashed"
+		}
+		fmt.Fprintf(&b, "  \"%s\" [%s];\n", dotEscape(node.Address), attributes)
+	}
+	for _, edge := range graph.Edges {
+		attributes := fmt.Sprintf(`label="%s"`, dotEscape(edge.Attribute))
+		if edge.Dangling {
+			attributes += ", color=red, style=dashed"
+		}
+		fmt.Fprintf(&b, "  \"%s\" -> \"%s\" [%s];\n", dotEscape(edge.From), dotEscape(edge.To), attributes)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
+
+// writeMermaid writes the graph as a Mermaid flowchart, the dangling edges
+// and the missing resources are red and dashed. The nodes are numbered as
+// the addresses are not valid Mermaid IDs.
+func writeMermaid(w io.Writer, graph resourceGraph) error {
+	ids := map[string]string{}
+	missing := []string{}
+
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, node := range graph.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.Address] = id
+		label := mermaidEscape(node.Address)
+		if node.Name != "" {
+			label += "<br/>" + mermaidEscape(node.Name)
+		}
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", id, label)
+		if node.Missing {
+			missing = append(missing, id)
+		}
+	}
+
+	dangling := []string{}
+	for i, edge := range graph.Edges {
+		arrow := "-->"
+		if edge.Dangling {
+			arrow = "-.->"
+			dangling = append(dangling, fmt.Sprint(i))
+		}
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", ids[edge.From], arrow, mermaidEscape(edge.Attribute), ids[edge.To])
+	}
+
+	if len(missing) > 0 {
+		b.WriteString("  classDef missing stroke:#d00,stroke-dasharray:5 5\n")
+		fmt.Fprintf(&b, "  class %s missing\n", strings.Join(missing, ","))
+	}
+	if len(dangling) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#d00\n", strings.Join(dangling, ","))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "|", "#124;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
+
+// sortHandlersByDependencies returns the handlers with the ones of the
+// resource types others depend on first, so that the resources are generated
+// before the ones linking to them. The order of the handlers is kept
+// otherwise.
+func sortHandlersByDependencies(handlers []ResourceHandler) []ResourceHandler {
+	byType := lo.KeyBy(handlers, func(handler ResourceHandler) string {
+		return handler.ResourceType()
+	})
+
+	sorted := []ResourceHandler{}
+	visited := map[string]bool{}
+	var visit func(handler ResourceHandler)
+	visit = func(handler ResourceHandler) {
+		if visited[handler.ResourceType()] {
+			return
+		}
+		visited[handler.ResourceType()] = true
+		for _, dependency := range handler.Dependencies() {
+			if dependencyHandler, ok := byType[dependency]; ok {
+				visit(dependencyHandler)
+			}
+		}
+		sorted = append(sorted, handler)
+	}
+	for _, handler := range handlers {
+		visit(handler)
+	}
+	return sorted
+}
+
+// sortItemsByLinks returns the items of handler with the ones linked to by
+// other items of the same resource type first, e.g. the jobs triggering
+// other jobs on completion. The order of the items is kept otherwise.
+func sortItemsByLinks(handler ResourceHandler, items []any, data *accountData) []any {
+	byKey := lo.KeyBy(items, handler.Label)
+
+	sorted := []any{}
+	visited := map[string]bool{}
+	var visit func(item any)
+	visit = func(item any) {
+		if visited[handler.Label(item)] {
+			return
+		}
+		visited[handler.Label(item)] = true
+		for _, link := range handler.Links(item, data) {
+			if linked, ok := byKey[link.key]; ok && link.resourceType == handler.ResourceType() {
+				visit(linked)
+			}
+		}
+		sorted = append(sorted, item)
+	}
+	for _, item := range items {
+		visit(item)
+	}
+	return sorted
+}
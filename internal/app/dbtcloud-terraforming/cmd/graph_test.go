@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGraph_Build checks that the links to the items of the resource types
+// selected but not fetched are dangling, unless they are already managed,
+// unlike the ones to the resource types neither selected nor in the account
+// data.
+func TestGraph_Build(t *testing.T) {
+	data := fabricatedAccountData()
+	handlers := []ResourceHandler{resourceHandlers["dbtcloud_job"], resourceHandlers["dbtcloud_webhook"]}
+	resources := map[string][]any{
+		"dbtcloud_job": {data.jobs[0]},
+		// job 2 was deleted
+		"dbtcloud_webhook": {dbtcloud.Webhook{ID: "wh_1", Name: "Slack", JobIDs: []string{"1", "2"}}},
+	}
+
+	tests := []struct {
+		name          string
+		managedLabels map[string]map[string]string
+		wantLast      graphNode
+		wantLastEdge  graphEdge
+	}{
+		{
+			name:         "deleted job",
+			wantLast:     graphNode{Address: "dbtcloud_job.terraform_managed_resource_2", ResourceType: "dbtcloud_job", Missing: true},
+			wantLastEdge: graphEdge{From: "dbtcloud_webhook.terraform_managed_resource_wh_1", To: "dbtcloud_job.terraform_managed_resource_2", Attribute: "job_ids", Dangling: true},
+		},
+		{
+			name:          "managed job",
+			managedLabels: map[string]map[string]string{"dbtcloud_job": {"2": "dbtcloud_job.legacy"}},
+			wantLast:      graphNode{Address: "dbtcloud_job.legacy", ResourceType: "dbtcloud_job"},
+			wantLastEdge:  graphEdge{From: "dbtcloud_webhook.terraform_managed_resource_wh_1", To: "dbtcloud_job.legacy", Attribute: "job_ids"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { managedLabels = nil }()
+			managedLabels = tt.managedLabels
+
+			graph := buildGraph(handlers, resources, data)
+
+			assert.Equal(t, []graphNode{
+				{Address: "dbtcloud_job.terraform_managed_resource_1", ResourceType: "dbtcloud_job", Name: "Nightly"},
+				{Address: "dbtcloud_webhook.terraform_managed_resource_wh_1", ResourceType: "dbtcloud_webhook", Name: "Slack"},
+				{Address: "dbtcloud_project.terraform_managed_resource_10", ResourceType: "dbtcloud_project"},
+				{Address: "dbtcloud_environment.terraform_managed_resource_100", ResourceType: "dbtcloud_environment"},
+				{Address: "dbtcloud_environment.terraform_managed_resource_101", ResourceType: "dbtcloud_environment"},
+				tt.wantLast,
+			}, graph.Nodes)
+			assert.Equal(t, []graphEdge{
+				{From: "dbtcloud_job.terraform_managed_resource_1", To: "dbtcloud_project.terraform_managed_resource_10", Attribute: "project_id"},
+				{From: "dbtcloud_job.terraform_managed_resource_1", To: "dbtcloud_environment.terraform_managed_resource_100", Attribute: "environment_id"},
+				{From: "dbtcloud_job.terraform_managed_resource_1", To: "dbtcloud_environment.terraform_managed_resource_101", Attribute: "deferring_environment_id"},
+				{From: "dbtcloud_webhook.terraform_managed_resource_wh_1", To: "dbtcloud_job.terraform_managed_resource_1", Attribute: "job_ids"},
+				tt.wantLastEdge,
+			}, graph.Edges)
+		})
+	}
+}
+
+// TestGraph_BuildTargetNotSelected checks that the links to the jobs are
+// checked against the jobs of the account data when dbtcloud_job is not
+// selected.
+func TestGraph_BuildTargetNotSelected(t *testing.T) {
+	handlers := []ResourceHandler{resourceHandlers["dbtcloud_webhook"], resourceHandlers["dbtcloud_notification"]}
+	resources := map[string][]any{
+		"dbtcloud_webhook":      {dbtcloud.Webhook{ID: "wh_1", Name: "Slack", JobIDs: []string{"1", "2"}}},
+		"dbtcloud_notification": {dbtcloud.Notification{ID: 5, OnFailure: []int{3}}},
+	}
+	graph := buildGraph(handlers, resources, fabricatedAccountData())
+
+	assert.Equal(t, []bool{false, true, true}, lo.Map(graph.Edges, func(edge graphEdge, _ int) bool { return edge.Dangling }))
+	assert.Equal(t, "dbtcloud_job.terraform_managed_resource_3", graph.Edges[2].To)
+}
+
+func TestGraph_Write(t *testing.T) {
+	graph := resourceGraph{
+		Nodes: []graphNode{
+			{Address: "dbtcloud_job.terraform_managed_resource_1", ResourceType: "dbtcloud_job", Name: "Nightly"},
+			{Address: "dbtcloud_webhook.terraform_managed_resource_wh_1", ResourceType: "dbtcloud_webhook", Name: "Slack"},
+			{Address: "dbtcloud_job.terraform_managed_resource_2", ResourceType: "dbtcloud_job", Missing: true},
+		},
+		Edges: []graphEdge{
+			{From: "dbtcloud_webhook.terraform_managed_resource_wh_1", To: "dbtcloud_job.terraform_managed_resource_1", Attribute: "job_ids"},
+			{From: "dbtcloud_webhook.terraform_managed_resource_wh_1", To: "dbtcloud_job.terraform_managed_resource_2", Attribute: "job_ids", Dangling: true},
+		},
+	}
+
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{
+			format: "dot",
+			want: []string{
+				`"dbtcloud_job.terraform_managed_resource_1" [label="dbtcloud_job.terraform_managed_resource_1\nNightly"];`,
+				`"dbtcloud_job.terraform_managed_resource_2" [label="dbtcloud_job.terraform_managed_resource_2", color=red, style=dashed];`,
+				`"dbtcloud_webhook.terraform_managed_resource_wh_1" -> "dbtcloud_job.terraform_managed_resource_2" [label="job_ids", color=red, style=dashed];`,
+			},
+		},
+		{
+			format: "mermaid",
+			want: []string{
+				"  n0[\"dbtcloud_job.terraform_managed_resource_1<br/>Nightly\"]\n",
+				"  n1 -->|job_ids| n0\n",
+				"  n1 -.->|job_ids| n2\n",
+				"  class n2 missing\n",
+				"  linkStyle 1 stroke:#d00\n",
+			},
+		},
+		{
+			format: "json",
+			want:   []string{"\"to\": \"dbtcloud_job.terraform_managed_resource_2\",\n      \"attribute\": \"job_ids\",\n      \"dangling\": true"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, writeGraph(&out, graph, tt.format))
+			for _, want := range tt.want {
+				assert.Contains(t, out.String(), want)
+			}
+		})
+	}
+
+	assert.Error(t, writeGraph(&bytes.Buffer{}, graph, "svg"))
+}
+
+func TestGraph_SortHandlersByDependencies(t *testing.T) {
+	handlers := []ResourceHandler{
+		resourceHandlers["dbtcloud_webhook"],
+		resourceHandlers["dbtcloud_job"],
+		resourceHandlers["dbtcloud_project"],
+		resourceHandlers["dbtcloud_environment"],
+	}
+
+	sorted := lo.Map(sortHandlersByDependencies(handlers), func(handler ResourceHandler, _ int) string {
+		return handler.ResourceType()
+	})
+	assert.Equal(t, []string{"dbtcloud_project", "dbtcloud_environment", "dbtcloud_job", "dbtcloud_webhook"}, sorted)
+}
+
+// TestGraph_SortItemsByLinks checks that the jobs triggered on the
+// completion of other jobs come after them.
+func TestGraph_SortItemsByLinks(t *testing.T) {
+	runAfter := func(jobID int) *dbtcloud.JobCompletionTriggerCondition {
+		return &dbtcloud.JobCompletionTriggerCondition{Condition: &dbtcloud.JobCompletionCondition{JobID: jobID}}
+	}
+	items := lo.ToAnySlice([]dbtcloud.Job{
+		{ID: 3, JobCompletionTriggerCondition: runAfter(2)},
+		{ID: 2, JobCompletionTriggerCondition: runAfter(1)},
+		{ID: 1},
+		{ID: 4},
+	})
+
+	sorted := lo.Map(sortItemsByLinks(resourceHandlers["dbtcloud_job"], items, &accountData{}), func(item any, _ int) int {
+		return item.(dbtcloud.Job).ID
+	})
+	assert.Equal(t, []int{1, 2, 3, 4}, sorted)
+}
@@ -2,64 +2,15 @@ package cmd
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/samber/lo"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/zclconf/go-cty/cty"
 )
 
-// resourceImportStringFormats contains a mapping of the resource type to the
-// composite ID that is compatible with performing an import.
-var resourceImportStringFormats = map[string]string{
-	"dbtcloud_project":               ":id",
-	"dbtcloud_repository":            ":project_id::id",
-	"dbtcloud_project_repository":    ":id::repository_id",
-	"dbtcloud_job":                   ":id",
-	"dbtcloud_environment":           ":project_id::id",
-	"dbtcloud_environment_variable":  ":project_id::name",
-	"dbtcloud_group":                 ":id",
-	"dbtcloud_snowflake_credential":  ":project_id::id",
-	"dbtcloud_databricks_credential": ":project_id::id",
-	"dbtcloud_bigquery_credential":   ":project_id::id",
-	"dbtcloud_bigquery_connection":   ":project_id::id",
-	"dbtcloud_connection":            ":project_id::id",
-	"dbtcloud_extended_attributes":   ":project_id::id",
-	"dbtcloud_user_groups":           ":user_id",
-	"dbtcloud_webhook":               ":id",
-	"dbtcloud_notification":          ":id",
-	"dbtcloud_service_token":         ":id",
-	"dbtcloud_global_connection":     ":id",
-	// account_features is a singleton: there's exactly one instance per account,
-	// keyed by the account id rather than a per-item id.
-	"dbtcloud_account_features": ":id",
-	// dbtcloud_profile's composite id is project_id:profile_id. Unlike
-	// dbtcloud_environment's ":project_id::id" (whose ":id" resolves against
-	// the plain resource id passed into buildRawImportAddress), the profile's
-	// resource id is itself a composite label (project_id folded in, see the
-	// dbtcloud_profile case in generate.go and GetProfiles in api.go), so we
-	// resolve against the separate numeric "profile_id" field instead.
-	"dbtcloud_profile": ":project_id::profile_id",
-	// dbtcloud_job_completion_trigger's own id is just the plain numeric id
-	// of the downstream job it's attached to (matching the provider's
-	// ImportState, which parses the import id directly as job_id) - no
-	// composite folding needed, unlike dbtcloud_profile.
-	"dbtcloud_job_completion_trigger": ":id",
-	// dbtcloud_environment_variable_job_override's real import id is
-	// project_id:job_definition_id:environment_variable_job_override_id (per
-	// the provider's ImportState). Its resource id is folded (project_id,
-	// job_definition_id, and the override id all baked into "id", see the
-	// dbtcloud_environment_variable_job_override case in generate.go), so -
-	// exactly like dbtcloud_profile's ":profile_id" - we resolve the last
-	// segment against the separate numeric
-	// "environment_variable_job_override_id" field instead of ":id".
-	"dbtcloud_environment_variable_job_override": ":project_id::job_definition_id::environment_variable_job_override_id",
-}
-
 func init() {
 	rootCmd.AddCommand(importCommand)
 }
@@ -73,341 +24,95 @@ var importCommand = &cobra.Command{
 
 func runImport() func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
-		if outputFile != "" {
-			spin := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(cmd.OutOrStderr()))
-			spin.Suffix = " Downloading resources and generating import statements\n"
-			spin.Start()
-			defer spin.Stop()
+		files := importConfig(cmd)
+		if files == nil {
+			return
 		}
 
-		if len(resourceTypes) == 0 {
-			log.Fatal("you must define at least one --resource-types to generate the import commands/code")
+		files, err := formatConfig(files)
+		if err != nil {
+			log.Fatalf("failed to write import blocks: %v", err)
 		}
-		var jsonStructData []interface{}
-
-		accountID = viper.GetString("account")
-		apiToken = viper.GetString("token")
-		hostURL = viper.GetString("host-url")
-		if hostURL == "" {
-			hostURL = "https://cloud.getdbt.com/api"
-		}
-
-		if len(resourceTypes) == 1 && resourceTypes[0] == "all" {
-			resourceTypes = lo.Keys(resourceImportStringFormats)
-		}
-
-		if len(excludeResourceTypes) > 0 {
-			resourceTypes = lo.Filter(resourceTypes, func(resourceType string, _ int) bool {
-				return !lo.Contains(excludeResourceTypes, resourceType)
-			})
-		}
-
-		importFile := hclwrite.NewEmptyFile()
-		importBody := importFile.Body()
-
-		prefetchedJobs := []any{}
-		resourceNeedingJobs := []string{"dbtcloud_job", "dbtcloud_webhook", "dbtcloud_job_completion_trigger", "dbtcloud_environment_variable_job_override"}
-		if len(lo.Intersect(resourceTypes, resourceNeedingJobs)) > 0 {
-			prefetchedJobs = dbtCloudClient.GetJobs(listFilterProjects)
-		}
-		prefetchedJobsIDsAny := lo.Map(prefetchedJobs, func(job any, index int) any {
-			return job.(map[string]any)["id"]
-		})
-
-		for _, resourceType := range resourceTypes {
-
-			switch resourceType {
-
-			case "dbtcloud_project":
-				jsonStructData = dbtCloudClient.GetProjects(listFilterProjects)
-
-			case "dbtcloud_project_repository":
-				allProjectsRepositories := dbtCloudClient.GetProjects(listFilterProjects)
-				jsonStructData = lo.Filter(allProjectsRepositories, func(project any, idx int) bool {
-					projectTyped := project.(map[string]any)
-					return projectTyped["repository_id"] != nil
-				})
-
-			case "dbtcloud_job":
-				jsonStructData = prefetchedJobs
-
-			case "dbtcloud_environment":
-				jsonStructData = dbtCloudClient.GetEnvironments(listFilterProjects)
-
-			case "dbtcloud_environment_variable":
-				mapEnvVars := dbtCloudClient.GetEnvironmentVariables(listFilterProjects)
-
-				listEnvVars := []any{}
-				for projectID, envVars := range mapEnvVars {
-					for envVarName := range envVars.(map[string]any) {
-						envDetails := map[string]any{}
-						envDetails["name"] = envVarName
-						envDetails["project_id"] = float64(projectID)
-						envDetails["id"] = fmt.Sprintf("%d_%s", projectID, envVarName)
-						listEnvVars = append(listEnvVars, envDetails)
-					}
-				}
-				jsonStructData = listEnvVars
-
-			case "dbtcloud_group":
-				// TODO add removal of default groups to the API call side
-				allGroups := dbtCloudClient.GetGroups()
-
-				listGroups := []any{}
-				for _, group := range allGroups {
-					groupTyped := group.(map[string]any)
-
-					defaultGroups := []string{"Owner", "Member", "Everyone"}
-
-					// we check if the group is one of the default ones
-					_, found := lo.Find(defaultGroups, func(i string) bool {
-						return i == groupTyped["name"].(string)
-					})
-					// only add if the current group doesn't match with the default ones
-					if !found {
-						listGroups = append(listGroups, group)
-					}
-				}
-				jsonStructData = listGroups
-
-			case "dbtcloud_snowflake_credential":
-				jsonStructData = dbtCloudClient.GetSnowflakeCredentials(listFilterProjects)
-
-			case "dbtcloud_databricks_credential":
-				jsonStructData = dbtCloudClient.GetDatabricksCredentials(listFilterProjects)
-
-			case "dbtcloud_bigquery_credential":
-				jsonStructData = dbtCloudClient.GetBigQueryCredentials(listFilterProjects)
-
-			case "dbtcloud_repository":
-				jsonStructData = dbtCloudClient.GetRepositories(listFilterProjects)
-
-			case "dbtcloud_bigquery_connection":
-				jsonStructData = dbtCloudClient.GetBigQueryConnections(listFilterProjects)
-
-			case "dbtcloud_connection":
-				jsonStructData = dbtCloudClient.GetGenericConnections(listFilterProjects)
-
-			case "dbtcloud_extended_attributes":
-				jsonStructData = dbtCloudClient.GetExtendedAttributes(listFilterProjects)
-
-			case "dbtcloud_user_groups":
-				jsonStructData = dbtCloudClient.GetUsers()
-
-			case "dbtcloud_webhook":
-				allWebHooks := dbtCloudClient.GetWebhooks()
-				jsonStructData = lo.Filter(allWebHooks, func(webhook any, idx int) bool {
-					webhookTyped := webhook.(map[string]any)
-					listJobIDs := webhookTyped["job_ids"].([]any)
-					if len(listJobIDs) == 0 {
-						// if there is no job defined, then the webhook is for all jobs
-						return true
-					}
-					return len(lo.Intersect(listJobIDs, prefetchedJobsIDsAny)) > 0
-				})
-
-			case "dbtcloud_notification":
-				allNotifications := dbtCloudClient.GetNotifications()
-				jsonStructData = lo.Filter(allNotifications, func(notif any, idx int) bool {
-					notifTyped := notif.(map[string]any)
-					return !(notifTyped["type"].(float64) == 4 && notifTyped["external_email"] == nil)
-
-				})
-			case "dbtcloud_service_token":
-				jsonStructData = dbtCloudClient.GetServiceTokens()
-
-			case "dbtcloud_global_connection":
-				jsonStructData = dbtCloudClient.GetGlobalConnectionsSummary()
-
-			case "dbtcloud_account_features":
-				jsonStructData = dbtCloudClient.GetAccountFeatures()
-
-			case "dbtcloud_profile":
-				listProfiles := dbtCloudClient.GetProfiles(listFilterProjects)
-
-				listImportProfiles := []any{}
-				for _, profile := range listProfiles {
-					profileTyped := profile.(map[string]any)
-					projectID := profileTyped["project_id"].(float64)
-					profileID := profileTyped["id"].(float64)
-
-					// profile_id is only unique within a project, not across the
-					// account, so - exactly as generate.go's dbtcloud_profile case
-					// does - we fold project_id into "id" to get a resource address
-					// that matches the label `generate` produces for the same
-					// profile, and keep the plain numeric id separately as
-					// "profile_id" for the :profile_id placeholder used by the
-					// composite import address template above.
-					profileTyped["profile_id"] = profileID
-					profileTyped["id"] = fmt.Sprintf("%.0f_%.0f", projectID, profileID)
-
-					listImportProfiles = append(listImportProfiles, profileTyped)
-				}
-				jsonStructData = listImportProfiles
-
-			case "dbtcloud_job_completion_trigger":
-				listImportTriggers := []any{}
-				for _, job := range prefetchedJobs {
-					jobTyped := job.(map[string]any)
-					if _, ok := jobTyped["job_completion_trigger_condition"].(map[string]any); !ok {
-						continue
-					}
-					// the resource's own id is just the downstream job's
-					// plain numeric id - see the format entry above.
-					listImportTriggers = append(listImportTriggers, map[string]any{
-						"id": jobTyped["id"],
-					})
-				}
-				jsonStructData = listImportTriggers
-
-			case "dbtcloud_environment_variable_job_override":
-				listOverrides := dbtCloudClient.GetEnvironmentVariableJobOverrides(listFilterProjects, prefetchedJobs)
-
-				listImportOverrides := []any{}
-				for _, override := range listOverrides {
-					overrideTyped := override.(map[string]any)
-
-					projectID := overrideTyped["project_id"].(float64)
-					jobDefinitionID := overrideTyped["job_definition_id"].(float64)
-					overrideID := overrideTyped["environment_variable_job_override_id"].(float64)
-
-					// fold project_id/job_definition_id/override_id into "id"
-					// to get a resource address matching the label generate.go
-					// produces for the same override - exactly as
-					// dbtcloud_profile's import case does above - while
-					// keeping the plain numeric override id available for the
-					// ":environment_variable_job_override_id" placeholder used
-					// by the composite import address template above.
-					overrideTyped["id"] = fmt.Sprintf("%.0f_%.0f_%.0f", projectID, jobDefinitionID, overrideID)
-
-					listImportOverrides = append(listImportOverrides, overrideTyped)
-				}
-				jsonStructData = listImportOverrides
-
-			default:
-				fmt.Fprintf(cmd.OutOrStderr(), "%q is not yet supported for state import", resourceType)
-				return
-			}
-
-			for _, data := range jsonStructData {
-				var idStr string
-				switch id := data.(map[string]interface{})["id"].(type) {
-				case float64:
-					idStr = fmt.Sprintf("%.0f", id)
-				case string:
-					idStr = id
-				default:
-					// Handle other unexpected types
-				}
-
-				if useModernImportBlock {
-					idvalue := buildRawImportAddress(resourceType, idStr, data)
-					imp := importBody.AppendNewBlock("import", []string{}).Body()
-					imp.SetAttributeRaw("to", hclwrite.TokensForIdentifier(fmt.Sprintf("%s.%s", resourceType, fmt.Sprintf("%s_%s", terraformResourceNamePrefix, idStr))))
-					imp.SetAttributeValue("id", cty.StringVal(idvalue))
-					importFile.Body().AppendNewline()
-				} else {
-					if err := writeString(buildTerraformImportCommand(resourceType, idStr, data)); err != nil {
-						log.Fatalf("failed to write import command: %v", err)
-					}
-				}
-			}
-
-		}
-		if useModernImportBlock {
-			if err := writeOutput(importFile); err != nil {
-				log.Fatalf("failed to write import blocks: %v", err)
-			}
+		if err := files.write(); err != nil {
+			log.Fatalf("failed to write import blocks: %v", err)
 		}
 	}
 }
 
-// buildTerraformImportCommand takes the resourceType and resourceID in order to
-// lookup the resource type import string and then return a suitable composite
-// value that is compatible with `terraform import`.
-func buildTerraformImportCommand(resourceType, resourceID string, data interface{}) string {
-	resourceImportAddress := buildRawImportAddress(resourceType, resourceID, data)
-	return fmt.Sprintf("%s %s.%s_%s %s\n", terraformImportCmdPrefix, resourceType, terraformResourceNamePrefix, resourceID, resourceImportAddress)
-}
+// importConfig returns the import blocks or the `terraform import` commands
+// of the resource types selected, or nil if they can't be imported.
+func importConfig(cmd *cobra.Command) *outputFiles {
+	ctx := cmd.Context()
 
-// resolveImportField looks up key in data and formats it the way import string
-// templates expect: numeric ids without a decimal point, strings as-is. If the
-// key is missing, nil, or of an unexpected type, fallback is returned instead -
-// this keeps a broken/missing field visible in the generated import address
-// rather than silently producing an incomplete one.
-//
-// This is the shared resolver behind every non-`:id` composite placeholder
-// (e.g. `:project_id`, `:connection_id`, `:repository_id`, `:name`) used by
-// resourceImportStringFormats, so a template can resolve any field present on
-// the resource's data - whether that's a single numeric id, part of a
-// composite key such as `:project_id::id`, or (via the `:id` placeholder
-// itself, substituted separately in buildRawImportAddress) a singleton keyed
-// by the account id.
-func resolveImportField(data map[string]any, key, fallback string) string {
-	if data == nil {
-		return fallback
+	if outputFile != "" || outputDir != "" {
+		spin := spinner.New(spinner.CharSets[14], 100*time.Millisecond, spinner.WithWriter(cmd.OutOrStderr()))
+		spin.Suffix = " Downloading resources and generating import statements\n"
+		spin.Start()
+		defer spin.Stop()
 	}
 
-	raw, ok := data[key]
-	if !ok || raw == nil {
-		return fallback
+	if len(resourceTypes) == 0 {
+		log.Fatal("you must define at least one --resource-types to generate the import commands/code")
 	}
 
-	switch v := raw.(type) {
-	case float64:
-		return fmt.Sprintf("%0.f", v)
-	case string:
-		return v
-	default:
-		return fallback
+	if len(resourceTypes) == 1 && resourceTypes[0] == "all" {
+		resourceTypes = resourceTypeNames()
 	}
-}
 
-// buildRawImportAddress takes the resourceType and resourceID in order to lookup the
-// resource type import string and then return a suitable composite value that
-// is compatible with `terraform import`.
-func buildRawImportAddress(resourceType, resourceID string, data any) string {
-	if _, ok := resourceImportStringFormats[resourceType]; !ok {
-		log.Fatalf("%s does not have an import format defined", resourceType)
+	if len(excludeResourceTypes) > 0 {
+		resourceTypes = lo.Filter(resourceTypes, func(resourceType string, _ int) bool {
+			return !lo.Contains(excludeResourceTypes, resourceType)
+		})
 	}
 
-	identifierType := "account"
-	identifierValue := accountID
+	handlers, err := selectedHandlers(resourceTypes, "state import")
+	if err != nil {
+		fmt.Fprint(cmd.OutOrStderr(), err)
+		return nil
+	}
 
-	dataTyped, _ := data.(map[string]any)
+	// the same data and items as in generate, so that the resources
+	// imported are the ones generated
+	return writeImports(fetchAccount(ctx, handlers))
+}
 
-	connectionID := resolveImportField(dataTyped, "connection_id", "no-connection_id")
-	repositoryID := resolveImportField(dataTyped, "repository_id", "no-repository_id")
-	projectID := resolveImportField(dataTyped, "project_id", "no-project_id")
-	profileID := resolveImportField(dataTyped, "profile_id", "no-profile_id")
-	name := resolveImportField(dataTyped, "name", "no-name")
-	jobDefinitionID := resolveImportField(dataTyped, "job_definition_id", "no-job_definition_id")
-	environmentVariableJobOverrideID := resolveImportField(dataTyped, "environment_variable_job_override_id", "no-environment_variable_job_override_id")
+// writeImports returns the import blocks or the `terraform import` commands
+// of the items of account.
+func writeImports(account *fetchedAccount) *outputFiles {
+	importFiles := newHCLFiles()
+	commands := newOutputFiles()
+
+	for _, handler := range account.handlers {
+		for _, item := range account.resources[handler.ResourceType()] {
+			address := resourceAddress(handler, item)
+			importID := handler.ImportID(item)
+
+			if useModernImportBlock {
+				writeImportBlock(importFiles.body("imports.tf"), address, importID)
+			} else {
+				commands.file("imports.sh").WriteString(buildTerraformImportCommand(address, importID))
+			}
+		}
 
-	var userID string
-	if resourceType == "dbtcloud_user_groups" {
-		// for dbtcloud_user_groups, the composite id template's :user_id
-		// placeholder resolves against the top-level `id` field (the user id),
-		// not a `user_id` field.
-		userID = resolveImportField(dataTyped, "id", "no-userid")
 	}
+	if useModernImportBlock {
+		return importFiles.output()
+	}
+	return commands
+}
 
-	s := resourceImportStringFormats[resourceType]
-	replacer := strings.NewReplacer(
-		":identifier_type", identifierType,
-		":identifier_value", identifierValue,
-		":zone_id", zoneID,
-		":account_id", accountID,
-		":id", resourceID,
-		":connection_id", connectionID,
-		":repository_id", repositoryID,
-		":project_id", projectID,
-		":profile_id", profileID,
-		":name", name,
-		":user_id", userID,
-		":job_definition_id", jobDefinitionID,
-		":environment_variable_job_override_id", environmentVariableJobOverrideID,
-	)
+// writeImportBlock writes the import block of the resource at address with
+// the ID used by the provider to import it. The syntax is the same for
+// Terraform (1.5+) and OpenTofu (1.6+).
+func writeImportBlock(body *hclwrite.Body, address, importID string) {
+	imp := body.AppendNewBlock("import", []string{}).Body()
+	imp.SetAttributeRaw("to", hclwrite.TokensForIdentifier(address))
+	imp.SetAttributeValue("id", cty.StringVal(importID))
+	body.AppendNewline()
+}
 
-	return replacer.Replace(s)
+// buildTerraformImportCommand returns the `terraform import` command of the
+// resource at address with the ID used by the provider to import it.
+func buildTerraformImportCommand(address, importID string) string {
+	return fmt.Sprintf("%s %s %s\n", terraformImportCmdPrefix, address, importID)
 }
@@ -10,111 +10,85 @@ import (
 
 	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
 	"github.com/go-test/deep"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
 	"github.com/hashicorp/terraform-exec/tfexec"
 	"github.com/samber/lo"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
 )
 
-// TestImport_BuildRawImportAddress locks the generalized import-string
-// builder (resourceImportStringFormats + buildRawImportAddress /
-// resolveImportField) against regressions. It covers:
-//   - (a) an existing numeric-id resource's format is unchanged
-//     (dbtcloud_project, ":id").
-//   - (b) a composite-id template resolves correctly
-//     (dbtcloud_environment, ":project_id::id").
-//   - (c) the new singleton format resolves correctly
-//     (dbtcloud_account_features, ":id" resolved against the account id).
-func TestImport_BuildRawImportAddress(t *testing.T) {
-	origAccountID := accountID
-	defer func() { accountID = origAccountID }()
-	accountID = "9999"
-
+// TestImport_ImportID locks the IDs used by the provider to import each
+// kind of resource against regressions:
+//   - (a) a numeric-id resource (dbtcloud_project).
+//   - (b) a composite id keyed by project (dbtcloud_environment).
+//   - (c) the singleton keyed by the account id (dbtcloud_account_features).
+//   - (d) the 3-part composite id of dbtcloud_environment_variable_job_override.
+func TestImport_ImportID(t *testing.T) {
 	tests := map[string]struct {
 		resourceType string
-		resourceID   string
-		data         map[string]any
+		item         any
 		want         string
 	}{
-		"existing numeric-id resource (dbtcloud_project) is unchanged": {
+		"numeric-id resource (dbtcloud_project)": {
 			resourceType: "dbtcloud_project",
-			resourceID:   "123",
-			data:         map[string]any{"id": float64(123)},
+			item:         dbtcloud.Project{ID: 123},
 			want:         "123",
 		},
-		"existing composite-id resource (dbtcloud_environment) resolves :project_id::id": {
+		"composite-id resource (dbtcloud_environment) is project_id:id": {
 			resourceType: "dbtcloud_environment",
-			resourceID:   "456",
-			data:         map[string]any{"id": float64(456), "project_id": float64(71)},
+			item:         dbtcloud.Environment{ID: 456, ProjectID: 71},
 			want:         "71:456",
 		},
-		"new singleton resource (dbtcloud_account_features) resolves :id to the account id": {
+		"project repository (dbtcloud_project_repository) is id:repository_id": {
+			resourceType: "dbtcloud_project_repository",
+			item:         dbtcloud.Project{ID: 43, RepositoryID: lo.ToPtr(12)},
+			want:         "43:12",
+		},
+		"environment variable (dbtcloud_environment_variable) is project_id:name": {
+			resourceType: "dbtcloud_environment_variable",
+			item:         environmentVariable{EnvironmentVariable: dbtcloud.EnvironmentVariable{Name: "DBT_ENV"}, projectID: 71},
+			want:         "71:DBT_ENV",
+		},
+		"singleton resource (dbtcloud_account_features) is the account id": {
 			resourceType: "dbtcloud_account_features",
-			resourceID:   "9999",
-			data:         map[string]any{"id": "9999"},
+			item:         map[string]any{"id": "9999"},
 			want:         "9999",
 		},
-		"new project-scoped composite resource (dbtcloud_profile) resolves :project_id::profile_id": {
+		"project-scoped composite resource (dbtcloud_profile) is project_id:profile_id": {
 			resourceType: "dbtcloud_profile",
-			resourceID:   "5_10",
-			data:         map[string]any{"id": "5_10", "project_id": float64(5), "profile_id": float64(10)},
+			item:         dbtcloud.Profile{ID: 10, ProjectID: 5},
 			want:         "5:10",
 		},
-		"new job-id-only resource (dbtcloud_job_completion_trigger) resolves :id to the downstream job id": {
+		"job-id-only resource (dbtcloud_job_completion_trigger) is the downstream job id": {
 			resourceType: "dbtcloud_job_completion_trigger",
-			resourceID:   "456",
-			data:         map[string]any{"id": float64(456)},
+			item:         dbtcloud.Job{ID: 456},
 			want:         "456",
 		},
-		"new 3-part composite resource (dbtcloud_environment_variable_job_override) resolves :project_id::job_definition_id::environment_variable_job_override_id": {
+		"3-part composite resource (dbtcloud_environment_variable_job_override) is project_id:job_definition_id:environment_variable_job_override_id": {
 			resourceType: "dbtcloud_environment_variable_job_override",
-			resourceID:   "71_456_789",
-			data: map[string]any{
-				"id":                                   "71_456_789",
-				"project_id":                           float64(71),
-				"job_definition_id":                    float64(456),
-				"environment_variable_job_override_id": float64(789),
-			},
-			want: "71:456:789",
+			item:         dbtcloud.EnvironmentVariableJobOverride{ID: 789, ProjectID: 71, JobDefinitionID: 456},
+			want:         "71:456:789",
 		},
 	}
 
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
-			got := buildRawImportAddress(tc.resourceType, tc.resourceID, tc.data)
+			got := resourceHandlers[tc.resourceType].ImportID(tc.item)
 			assert.Equal(t, tc.want, got)
 		})
 	}
 }
 
-// TestImport_ResolveImportField covers the shared field resolver extracted
-// from buildRawImportAddress, including the fallback strings that must stay
-// byte-identical to what the previous per-field hardcoded logic produced.
-func TestImport_ResolveImportField(t *testing.T) {
-	tests := map[string]struct {
-		data     map[string]any
-		key      string
-		fallback string
-		want     string
-	}{
-		"numeric field present":           {data: map[string]any{"project_id": float64(71)}, key: "project_id", fallback: "no-project_id", want: "71"},
-		"string field present":            {data: map[string]any{"name": "my-resource"}, key: "name", fallback: "no-name", want: "my-resource"},
-		"field absent":                    {data: map[string]any{}, key: "connection_id", fallback: "no-connection_id", want: "no-connection_id"},
-		"field nil":                       {data: map[string]any{"connection_id": nil}, key: "connection_id", fallback: "no-connection_id", want: "no-connection_id"},
-		"field unexpected type":           {data: map[string]any{"repository_id": true}, key: "repository_id", fallback: "no-repository_id", want: "no-repository_id"},
-		"nil data map":                    {data: nil, key: "name", fallback: "no-name", want: "no-name"},
-		"user_groups id-as-user_id":       {data: map[string]any{"id": float64(42)}, key: "id", fallback: "no-userid", want: "42"},
-		"profile_id field present":        {data: map[string]any{"profile_id": float64(10)}, key: "profile_id", fallback: "no-profile_id", want: "10"},
-		"job_definition_id field present": {data: map[string]any{"job_definition_id": float64(456)}, key: "job_definition_id", fallback: "no-job_definition_id", want: "456"},
-		"environment_variable_job_override_id field present": {data: map[string]any{"environment_variable_job_override_id": float64(789)}, key: "environment_variable_job_override_id", fallback: "no-environment_variable_job_override_id", want: "789"},
-	}
+func TestImport_BuildTerraformImportCommand(t *testing.T) {
+	handler := resourceHandlers["dbtcloud_environment"]
+	environment := dbtcloud.Environment{ID: 456, ProjectID: 71}
 
-	for name, tc := range tests {
-		t.Run(name, func(t *testing.T) {
-			got := resolveImportField(tc.data, tc.key, tc.fallback)
-			assert.Equal(t, tc.want, got)
-		})
-	}
+	got := buildTerraformImportCommand(resourceAddress(handler, environment), handler.ImportID(environment))
+	assert.Equal(t, "terraform import dbtcloud_environment.terraform_managed_resource_456 71:456\n", got)
 }
 
 func TestResourceImport(t *testing.T) {
@@ -338,3 +312,38 @@ func TestResourceImport(t *testing.T) {
 		})
 	}
 }
+
+// TestImport_OpenTofu checks the import commands of OpenTofu and that the
+// import blocks only use the syntax OpenTofu supports: a `to` resource address
+// and a string `id`.
+func TestImport_OpenTofu(t *testing.T) {
+	defer func(prefix string) { terraformImportCmdPrefix = prefix }(terraformImportCmdPrefix)
+	terraformImportCmdPrefix = "tofu import"
+
+	handler := resourceHandlers["dbtcloud_environment"]
+	environment := dbtcloud.Environment{ID: 456, ProjectID: 71}
+	address := resourceAddress(handler, environment)
+
+	assert.Equal(t, "tofu import dbtcloud_environment.terraform_managed_resource_456 71:456\n", buildTerraformImportCommand(address, handler.ImportID(environment)))
+	assert.Equal(t, []string{address}, importCommandAddresses([]byte(buildTerraformImportCommand(address, handler.ImportID(environment)))))
+
+	f := hclwrite.NewEmptyFile()
+	writeImportBlock(f.Body(), address, handler.ImportID(environment))
+
+	file, diags := hclsyntax.ParseConfig(f.Bytes(), "imports.tf", hcl.InitialPos)
+	require.False(t, diags.HasErrors(), diags.Error())
+	blocks := file.Body.(*hclsyntax.Body).Blocks
+	require.Len(t, blocks, 1)
+	assert.Equal(t, "import", blocks[0].Type)
+	assert.Empty(t, blocks[0].Labels)
+	assert.ElementsMatch(t, []string{"to", "id"}, lo.Keys(blocks[0].Body.Attributes))
+
+	to, diags := hcl.AbsTraversalForExpr(blocks[0].Body.Attributes["to"].Expr)
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Equal(t, "dbtcloud_environment", to.RootName())
+	assert.Len(t, to, 2)
+
+	id, diags := blocks[0].Body.Attributes["id"].Expr.Value(nil)
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.Equal(t, cty.StringVal("71:456"), id)
+}
@@ -3,7 +3,6 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"sort"
 	"strings"
 
 	"github.com/charmbracelet/huh"
@@ -82,24 +81,22 @@ func runInteractive(cmd *cobra.Command, args []string) {
 	}
 
 	// Get all available resource types
-	availableResources := make([]string, 0, len(resourceImportStringFormats))
-	for resource := range resourceImportStringFormats {
-		availableResources = append(availableResources, resource)
-	}
-	sort.Strings(availableResources)
+	availableResources := resourceTypeNames()
 
 	// Build the main form groups
 	var groups []*huh.Group
 
 	// Get available projects and add project selection
-	projects := dbtCloudClient.GetProjects([]int{})
+	projects, err := dbtCloudClient.GetProjects(cmd.Context(), []int{})
+	if err != nil {
+		log.Fatal(err)
+	}
 	projectOptions := make([]huh.Option[int], 0, len(projects))
-	for _, p := range projects {
-		project := p.(map[string]interface{})
+	for _, project := range projects {
 		projectOptions = append(projectOptions,
 			huh.NewOption(
-				fmt.Sprintf("%v - %s", int(project["id"].(float64)), project["name"].(string)),
-				int(project["id"].(float64)),
+				fmt.Sprintf("%v - %s", project.ID, project.Name),
+				project.ID,
 			),
 		)
 	}
@@ -0,0 +1,287 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+}
+
+var inventoryFormat string
+
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Output a table of the objects of the account per resource type, as CSV, JSON or Markdown",
+	Long: `Fetches the selected resource types (all of them if --resource-types is not set) and outputs a flat table per
+resource type in the --inventory-format format, with the ID, the name, the project and the details of each object,
+e.g. the schedule and the triggers of the jobs, and the URL of its page in the dbt Cloud UI.
+With --output-dir, each table is written to its own file, e.g. job.csv.`,
+	Run:    runInventory(),
+	PreRun: sharedPreRun,
+}
+
+// inventoryColumn is a column of the inventory of an item, see
+// inventoryValue for how the value is written.
+type inventoryColumn struct {
+	name  string
+	value any
+}
+
+func column(name string, value any) inventoryColumn {
+	return inventoryColumn{name: name, value: value}
+}
+
+// inventoryTable is the inventory of a resource type, with a row per item.
+type inventoryTable struct {
+	resourceType string
+	columns      []string
+	rows         []map[string]string
+}
+
+// inventoryFileExtensions are the extensions of the files of the tables, by
+// --inventory-format
+var inventoryFileExtensions = map[string]string{
+	"csv":      "csv",
+	"json":     "json",
+	"markdown": "md",
+}
+
+func runInventory() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if _, ok := inventoryFileExtensions[inventoryFormat]; !ok {
+			log.Fatalf("--inventory-format must be either csv, json or markdown, not %q", inventoryFormat)
+		}
+
+		if len(resourceTypes) == 0 || (len(resourceTypes) == 1 && resourceTypes[0] == "all") {
+			resourceTypes = resourceTypeNames()
+		}
+
+		if len(excludeResourceTypes) > 0 {
+			resourceTypes = lo.Filter(resourceTypes, func(resourceType string, _ int) bool {
+				return !lo.Contains(excludeResourceTypes, resourceType)
+			})
+		}
+
+		listFilterProjects = viper.GetIntSlice("projects")
+
+		handlers, err := selectedHandlers(resourceTypes, "inventory")
+		if err != nil {
+			log.Fatal(err)
+		}
+		handlers = sortHandlersByDependencies(handlers)
+
+		ctx := cmd.Context()
+		data := prefetchAccountData(ctx, handlers)
+		resources := listResources(ctx, handlers, data)
+
+		files, err := inventoryFiles(buildInventory(handlers, resources, data), inventoryFormat)
+		if err != nil {
+			log.Fatalf("failed to write inventory: %v", err)
+		}
+		if err := files.write(); err != nil {
+			log.Fatalf("failed to write inventory: %v", err)
+		}
+	}
+}
+
+// listResources lists all the items of the handlers for the inventory, keyed
+// by resource type. Unlike fetchResources, the items that generate leaves
+// out, e.g. the ones already in the state or in --existing-config-dir, are
+// kept.
+func listResources(ctx context.Context, handlers []ResourceHandler, data *accountData) map[string][]any {
+	items := map[string][]any{}
+	for _, handler := range handlers {
+		resourceType := handler.ResourceType()
+		log.Debugf("listing %s", resourceType)
+
+		var err error
+		items[resourceType], err = handler.List(ctx, data)
+		recordError(resourceType, err)
+	}
+	return items
+}
+
+// buildInventory returns the tables of the items of the handlers. The
+// resource types without items have no table.
+func buildInventory(handlers []ResourceHandler, resources map[string][]any, data *accountData) []inventoryTable {
+	tables := []inventoryTable{}
+	for _, handler := range handlers {
+		items := resources[handler.ResourceType()]
+		if len(items) == 0 {
+			log.Debugf("no %s to list in the inventory", handler.ResourceType())
+			continue
+		}
+
+		table := inventoryTable{resourceType: handler.ResourceType()}
+		for _, item := range items {
+			columns := []inventoryColumn{column("id", handler.Label(item)), column("name", handler.Name(item))}
+			// the projects are identified by their id already
+			if handler.Scope() == "Project" && handler.ResourceType() != "dbtcloud_project" {
+				columns = append(columns, column("project_id", handler.ProjectID(item)))
+			}
+			columns = append(columns, handler.Inventory(item, data)...)
+			columns = append(columns, column("url", handler.URL(item)))
+
+			row := map[string]string{}
+			for _, c := range columns {
+				if !lo.Contains(table.columns, c.name) {
+					table.columns = append(table.columns, c.name)
+				}
+				row[c.name] = inventoryValue(c.value)
+			}
+			table.rows = append(table.rows, row)
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// inventoryValue returns the value of a column as a string: the nil values
+// are empty and the lists are separated by commas.
+func inventoryValue(value any) string {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return ""
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return ""
+		}
+		return inventoryValue(v.Elem().Interface())
+	case reflect.Slice:
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, inventoryValue(v.Index(i).Interface()))
+		}
+		return strings.Join(values, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// inventoryFiles returns the tables in format, one file per resource type
+// with --output-dir, e.g. job.csv. In the single file, the CSV tables are
+// separated by an empty line, the JSON rows are keyed by resource type and
+// the Markdown tables have a heading each.
+func inventoryFiles(tables []inventoryTable, format string) (*outputFiles, error) {
+	files := newOutputFiles()
+
+	if format == "json" && outputDir == "" {
+		rows := map[string][]map[string]string{}
+		for _, table := range tables {
+			rows[table.resourceType] = table.rows
+		}
+		content, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files.file("").Write(append(content, '\n'))
+		return files, nil
+	}
+
+	for _, table := range tables {
+		file := files.file(strings.TrimPrefix(table.resourceType, "dbtcloud_") + "." + inventoryFileExtensions[format])
+		if file.Len() > 0 {
+			file.WriteString("\n")
+		}
+
+		switch format {
+		case "csv":
+			// the resource type identifies the rows of the tables of the
+			// single file
+			writer := csv.NewWriter(file)
+			if err := writer.Write(append([]string{"resource_type"}, table.columns...)); err != nil {
+				return nil, err
+			}
+			for _, row := range table.rows {
+				values := lo.Map(table.columns, func(name string, _ int) string {
+					return row[name]
+				})
+				if err := writer.Write(append([]string{table.resourceType}, values...)); err != nil {
+					return nil, err
+				}
+			}
+			writer.Flush()
+			if err := writer.Error(); err != nil {
+				return nil, err
+			}
+		case "json":
+			content, err := json.MarshalIndent(table.rows, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			file.Write(append(content, '\n'))
+		case "markdown":
+			fmt.Fprintf(file, "## %s\n\n", table.resourceType)
+			fmt.Fprintf(file, "| %s |\n", strings.Join(table.columns, " | "))
+			fmt.Fprintf(file, "|%s\n", strings.Repeat(" --- |", len(table.columns)))
+			for _, row := range table.rows {
+				values := lo.Map(table.columns, func(name string, _ int) string {
+					return markdownEscape(row[name])
+				})
+				fmt.Fprintf(file, "| %s |\n", strings.Join(values, " | "))
+			}
+		default:
+			return nil, fmt.Errorf("--inventory-format must be either csv, json or markdown, not %q", format)
+		}
+	}
+	return files, nil
+}
+
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ").Replace(s)
+}
+
+// jobSchedule returns the inventory columns of the schedule and the triggers
+// of a job, the triggers being the ones enabled.
+func jobSchedule(job dbtcloud.Job) []inventoryColumn {
+	triggers := []string{}
+	for trigger, enabled := range map[string]bool{
+		"schedule":             job.Triggers.Schedule,
+		"github_webhook":       job.Triggers.GithubWebhook,
+		"git_provider_webhook": job.Triggers.GitProviderWebhook,
+		"on_merge":             job.Triggers.OnMerge,
+	} {
+		if enabled {
+			triggers = append(triggers, trigger)
+		}
+	}
+	var triggerJobID *int
+	if job.JobCompletionTriggerCondition != nil && job.JobCompletionTriggerCondition.Condition != nil {
+		triggers = append(triggers, "job_completion")
+		triggerJobID = &job.JobCompletionTriggerCondition.Condition.JobID
+	}
+	sort.Strings(triggers)
+
+	return []inventoryColumn{
+		column("schedule_type", job.Schedule.Date.Type),
+		column("schedule_cron", job.Schedule.Date.Cron),
+		column("schedule_days", job.Schedule.Date.Days),
+		column("schedule_hours", job.Schedule.Time.Hours),
+		column("triggers", triggers),
+		column("trigger_job_id", triggerJobID),
+	}
+}
+
+// permissionSets returns the permission sets of a group or a service token,
+// followed by the project they are limited to, e.g. developer:123, or all.
+func permissionSets(permissions []dbtcloud.Permission) []string {
+	return lo.Map(permissions, func(permission dbtcloud.Permission, _ int) string {
+		if permission.AllProjects || permission.ProjectID == nil {
+			return permission.PermissionSet + ":all"
+		}
+		return fmt.Sprintf("%s:%d", permission.PermissionSet, *permission.ProjectID)
+	})
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInventory_Build(t *testing.T) {
+	withTestClient(t)
+	data := fabricatedAccountData()
+	job := data.jobs[0]
+	job.Schedule.Date = dbtcloud.JobScheduleDate{Type: "custom_cron", Cron: "0 2 * * *"}
+	job.Triggers = dbtcloud.JobTriggers{Schedule: true, OnMerge: true}
+	handlers := []ResourceHandler{resourceHandlers["dbtcloud_job"], resourceHandlers["dbtcloud_webhook"], resourceHandlers["dbtcloud_group"]}
+	resources := map[string][]any{
+		"dbtcloud_job":     {job},
+		"dbtcloud_webhook": {dbtcloud.Webhook{ID: "wh_1", Name: "Slack", JobIDs: []string{"1", "2"}, Active: true}},
+	}
+	tables := buildInventory(handlers, resources, data)
+
+	// the groups have no table as there are none
+	require.Len(t, tables, 2)
+	assert.Equal(t, "dbtcloud_job", tables[0].resourceType)
+	assert.Equal(t, []string{
+		"id", "name", "project_id", "environment_id", "deferring_environment_id", "job_type",
+		"schedule_type", "schedule_cron", "schedule_days", "schedule_hours", "triggers", "trigger_job_id", "url",
+	}, tables[0].columns)
+	assert.Equal(t, map[string]string{
+		"id":                       "1",
+		"name":                     "Nightly",
+		"project_id":               "10",
+		"environment_id":           "100",
+		"deferring_environment_id": "101",
+		"job_type":                 "scheduled",
+		"schedule_type":            "custom_cron",
+		"schedule_cron":            "0 2 * * *",
+		"schedule_days":            "",
+		"schedule_hours":           "2,14",
+		"triggers":                 "on_merge,schedule",
+		"trigger_job_id":           "",
+		"url":                      "https://cloud.getdbt.com/deploy/9999/projects/10/jobs/1/settings/",
+	}, tables[0].rows[0])
+
+	assert.Equal(t, "1,2", tables[1].rows[0]["job_ids"])
+	assert.Equal(t, "https://cloud.getdbt.com/settings/accounts/9999/pages/webhooks/wh_1/", tables[1].rows[0]["url"])
+}
+
+func TestInventory_Value(t *testing.T) {
+	assert.Equal(t, "", inventoryValue(nil))
+	assert.Equal(t, "", inventoryValue((*int)(nil)))
+	assert.Equal(t, "3", inventoryValue(lo.ToPtr(3)))
+	assert.Equal(t, "1,2", inventoryValue([]int{1, 2}))
+	assert.Equal(t, "", inventoryValue([]string{}))
+	assert.Equal(t, "true", inventoryValue(true))
+}
+
+// TestInventory_Files checks the formats of the tables, with the CSV and
+// Markdown separators in the values.
+func TestInventory_Files(t *testing.T) {
+	tables := []inventoryTable{
+		{
+			resourceType: "dbtcloud_job",
+			columns:      []string{"id", "name", "url"},
+			rows:         []map[string]string{{"id": "1", "name": "Nightly, full", "url": "https://cloud.getdbt.com/deploy/9999/projects/10/jobs/1/settings/"}},
+		},
+		{
+			resourceType: "dbtcloud_webhook",
+			columns:      []string{"id", "name", "active"},
+			rows:         []map[string]string{{"id": "wh_1", "name": "Slack | alerts", "active": "true"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		format    string
+		outputDir string
+		wantNames []string
+		want      []string
+	}{
+		{
+			name:      "csv",
+			format:    "csv",
+			wantNames: []string{""},
+			want: []string{
+				"resource_type,id,name,url\ndbtcloud_job,1,\"Nightly, full\",",
+				// the tables are separated by an empty line
+				"/settings/\n\nresource_type,id,name,active\n",
+			},
+		},
+		{
+			name:      "markdown",
+			format:    "markdown",
+			wantNames: []string{""},
+			want: []string{
+				"## dbtcloud_webhook\n\n| id | name | active |\n| --- | --- | --- |\n",
+				"| wh_1 | Slack \\| alerts | true |",
+			},
+		},
+		{
+			name:      "json",
+			format:    "json",
+			wantNames: []string{""},
+			want:      []string{"\"dbtcloud_webhook\": [\n    {\n      \"active\": \"true\","},
+		},
+		{
+			name:      "json with output dir",
+			format:    "json",
+			outputDir: "inventory",
+			wantNames: []string{"job.json", "webhook.json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { outputDir = "" }()
+			outputDir = tt.outputDir
+
+			files, err := inventoryFiles(tables, tt.format)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantNames, files.names)
+			for _, want := range tt.want {
+				assert.Contains(t, files.file("").String(), want)
+			}
+		})
+	}
+}
+
+// fakeAPI points the client to a fake dbt Cloud API answering the paths of
+// responses with their body, and 404 for the other paths.
+func fakeAPI(t *testing.T, responses map[string]string) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, body)
+	}))
+	origClient := dbtCloudClient
+	dbtCloudClient = dbtcloud.NewDbtCloudHTTPClient(server.URL, "token", "9999", nil)
+	t.Cleanup(func() {
+		dbtCloudClient = origClient
+		server.Close()
+	})
+}
+
+// listResponse returns the body of a response of a list endpoint with the
+// JSON objects items.
+func listResponse(items ...string) string {
+	return fmt.Sprintf(`{"data": [%s], "extra": {"pagination": {"count": %d, "total_count": %d}}}`, strings.Join(items, ","), len(items), len(items))
+}
+
+// TestInventory_List checks that the inventory lists the objects that
+// generate leaves out: the default groups and the users only in them, the
+// credentials no environment uses and the items already in the state.
+func TestInventory_List(t *testing.T) {
+	fakeAPI(t, map[string]string{
+		"/v2/accounts/9999/projects/":                listResponse(`{"id": 10, "name": "Analytics"}`),
+		"/v3/accounts/9999/projects/10/credentials/": listResponse(`{"id": 5, "project_id": 10, "type": "snowflake", "auth_type": "password"}`),
+		"/v3/accounts/9999/groups/":                  listResponse(`{"id": 1, "name": "Everyone"}`),
+		"/v3/accounts/9999/users/":                   listResponse(`{"id": 7, "email": "a@example.com", "permissions": [{"groups": [{"id": 1, "name": "Everyone"}]}]}`),
+	})
+	origManagedResources := managedResources
+	t.Cleanup(func() { managedResources = origManagedResources })
+	managedResources = map[string]map[string]string{"dbtcloud_group": {"1": "dbtcloud_group.everyone"}}
+
+	handlers := []ResourceHandler{resourceHandlers["dbtcloud_snowflake_credential"], resourceHandlers["dbtcloud_group"], resourceHandlers["dbtcloud_user_groups"]}
+	data := prefetchAccountData(context.Background(), handlers)
+	tables := buildInventory(handlers, listResources(context.Background(), handlers, data), data)
+
+	require.Len(t, tables, 3)
+	assert.Equal(t, "5", tables[0].rows[0]["id"])
+	assert.Equal(t, "", tables[0].rows[0]["environment_id"])
+	assert.Equal(t, "Everyone", tables[1].rows[0]["name"])
+	assert.Equal(t, "7", tables[2].rows[0]["id"])
+	assert.Equal(t, "Everyone", tables[2].rows[0]["groups"])
+}
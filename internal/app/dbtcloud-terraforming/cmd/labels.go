@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/gosimple/slug"
+	"github.com/samber/lo"
+)
+
+var labelStrategy string
+
+// resourceLabels are the labels assigned with --label-strategy name, by
+// resource type and Label of the item. The resources missing from it keep the
+// labels built from their IDs, e.g. terraform_managed_resource_123.
+var resourceLabels = map[string]map[string]string{}
+
+// labelOf returns the label of the resource of resourceType whose item has
+// the Label key.
+func labelOf(resourceType, key string) string {
+	if label, ok := resourceLabels[resourceType][key]; ok {
+		return label
+	}
+	return fmt.Sprintf("%s_%s", terraformResourceNamePrefix, key)
+}
+
+// resourceReference returns the reference to the attribute of the resource
+// of resourceType whose item has the Label key, e.g.
+// dbtcloud_project.terraform_managed_resource_123.id, to link resources. The
+// resources already in the state are referenced at their address there.
+// Without attribute, it references the resource itself, e.g. for depends_on.
+func resourceReference(resourceType string, key any, attribute string) hclExpression {
+	address := fmt.Sprintf("%s.%s", resourceType, labelOf(resourceType, fmt.Sprint(key)))
+	if managedAddress, ok := managedLabels[resourceType][fmt.Sprint(key)]; ok {
+		// the resource is already in the state
+		if strings.HasPrefix(managedAddress, "module.") {
+			log.Warnf("%s is in a module, the reference to it must be replaced with an output of the module", managedAddress)
+		}
+		address = managedAddress
+	}
+	if attribute != "" {
+		address += "." + attribute
+	}
+	return hclReference(address)
+}
+
+// assignResourceLabels assigns the labels of the items of handler with
+// --label-strategy name: the slugged name of the item, prefixed by the one of
+// its project for the resource types whose names can repeat across projects,
+// e.g. analytics__nightly_full_refresh. When several items get the same
+// label, the one with the lowest ID keeps it and the others get their ID as a
+// suffix, so that the labels don't change when resources are added. The
+// suffix is repeated until the label isn't the one of another item, and the
+// items named like the label of an item without a name are all suffixed.
+func assignResourceLabels(handler ResourceHandler, items []any, data *accountData) {
+	if labelStrategy != "name" {
+		return
+	}
+
+	projectNames := lo.SliceToMap(data.projects, func(project dbtcloud.Project) (int, string) {
+		return project.ID, project.Name
+	})
+	// the projects and their repository links are named after the project
+	prefixProject := !lo.Contains([]string{"dbtcloud_project", "dbtcloud_project_repository"}, handler.ResourceType())
+
+	keysByLabel := map[string][]string{}
+	for _, item := range items {
+		label := labelName(handler.Name(item))
+		if label == "" {
+			continue
+		}
+		if projectID := handler.ProjectID(item); prefixProject && projectID != 0 {
+			if projectLabel := labelName(projectNames[projectID]); projectLabel != "" {
+				label = projectLabel + "__" + strings.TrimPrefix(label, "_")
+			}
+		}
+		keysByLabel[label] = append(keysByLabel[label], handler.Label(item))
+	}
+
+	// the labels built from the IDs of the items without a name are taken,
+	// and so are the ones kept by the items before suffixing the others
+	usedLabels := map[string]bool{}
+	for _, item := range items {
+		if labelName(handler.Name(item)) == "" {
+			usedLabels[labelOf(handler.ResourceType(), handler.Label(item))] = true
+		}
+	}
+	labels := map[string]string{}
+	sortedLabels := lo.Keys(keysByLabel)
+	sort.Strings(sortedLabels)
+	for _, label := range sortedLabels {
+		keys := keysByLabel[label]
+		sort.Slice(keys, func(i, j int) bool {
+			// the IDs are compared as numbers
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) < len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+		if !usedLabels[label] {
+			labels[keys[0]] = label
+			usedLabels[label] = true
+		}
+	}
+	for _, label := range sortedLabels {
+		for _, key := range keysByLabel[label] {
+			if _, ok := labels[key]; ok {
+				continue
+			}
+			// the suffixed label can be the one of another item, e.g. a
+			// group named "Analysts 12" when the group 12 is a second
+			// "Analysts"
+			suffixedLabel := fmt.Sprintf("%s_%s", label, key)
+			for usedLabels[suffixedLabel] {
+				suffixedLabel += "_" + key
+			}
+			usedLabels[suffixedLabel] = true
+			labels[key] = suffixedLabel
+		}
+	}
+	resourceLabels[handler.ResourceType()] = labels
+}
+
+// labelName turns a name into a valid Terraform label, e.g. "Nightly full
+// refresh" into nightly_full_refresh.
+func labelName(name string) string {
+	label := strings.ReplaceAll(slug.Make(name), "-", "_")
+	if label != "" && !unicode.IsLetter(rune(label[0])) {
+		label = "_" + label
+	}
+	return label
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLabels_LabelName(t *testing.T) {
+	cases := map[string]string{
+		"Nightly full refresh":  "nightly_full_refresh",
+		"Analytics (prod) - CI": "analytics_prod_ci",
+		"2024 backfill":         "_2024_backfill",
+		"DBT_ENV_VAR":           "dbt_env_var",
+		"":                      "",
+	}
+	for name, want := range cases {
+		assert.Equal(t, want, labelName(name), name)
+	}
+}
+
+func TestLabels_RepositoryName(t *testing.T) {
+	assert.Equal(t, "jaffle_shop", repositoryName("git@github.com:dbt-labs/jaffle_shop.git"))
+	assert.Equal(t, "jaffle_shop", repositoryName("https://github.com/dbt-labs/jaffle_shop/"))
+}
+
+// TestLabels_NameStrategy checks the labels with --label-strategy name and
+// that the references to the resources, the resource blocks and the import
+// blocks use the same ones.
+func TestLabels_NameStrategy(t *testing.T) {
+	defer func(strategy string) {
+		labelStrategy = strategy
+		resourceLabels = map[string]map[string]string{}
+	}(labelStrategy)
+	labelStrategy = "name"
+	resourceLabels = map[string]map[string]string{}
+
+	data := &accountData{projects: []dbtcloud.Project{
+		{ID: 1, Name: "Analytics"},
+		{ID: 2, Name: "Marketing"},
+	}}
+	projects := resourceHandlers["dbtcloud_project"]
+	jobs := resourceHandlers["dbtcloud_job"]
+	groups := resourceHandlers["dbtcloud_group"]
+	extendedAttributes := resourceHandlers["dbtcloud_extended_attributes"]
+
+	jobItems := []dbtcloud.Job{
+		{ID: 48213, ProjectID: 1, Name: "Nightly full refresh"},
+		{ID: 900, ProjectID: 2, Name: "Nightly full refresh"},
+		{ID: 1200, ProjectID: 1, Name: "nightly-full-refresh"},
+		{ID: 1100, ProjectID: 1, Name: "Nightly Full Refresh"},
+	}
+	groupItems := []dbtcloud.Group{{ID: 5, Name: "Analysts"}}
+	extendedAttributesItems := []dbtcloud.ExtendedAttributes{{ID: 7, ProjectID: 1}}
+
+	assignResourceLabels(projects, lo.ToAnySlice(data.projects), data)
+	assignResourceLabels(jobs, lo.ToAnySlice(jobItems), data)
+	assignResourceLabels(groups, lo.ToAnySlice(groupItems), data)
+	assignResourceLabels(extendedAttributes, lo.ToAnySlice(extendedAttributesItems), data)
+
+	assert.Equal(t, "dbtcloud_project.analytics", resourceAddress(projects, data.projects[0]))
+	assert.Equal(t, "dbtcloud_group.analysts", resourceAddress(groups, groupItems[0]))
+	// names repeating in a project are suffixed by ID, the lowest ID keeping the name
+	assert.Equal(t, "dbtcloud_job.marketing__nightly_full_refresh", resourceAddress(jobs, jobItems[1]))
+	assert.Equal(t, "dbtcloud_job.analytics__nightly_full_refresh", resourceAddress(jobs, jobItems[3]))
+	assert.Equal(t, "dbtcloud_job.analytics__nightly_full_refresh_1200", resourceAddress(jobs, jobItems[2]))
+	assert.Equal(t, "dbtcloud_job.analytics__nightly_full_refresh_48213", resourceAddress(jobs, jobItems[0]))
+	// the items without a name keep the labels built from their IDs
+	assert.Equal(t, "dbtcloud_extended_attributes.terraform_managed_resource_7", resourceAddress(extendedAttributes, extendedAttributesItems[0]))
+
+	assert.Equal(t, "dbtcloud_job.marketing__nightly_full_refresh.id", resourceReference("dbtcloud_job", 900, "id").String())
+	assert.Equal(t, "dbtcloud_project.analytics", resourceReference("dbtcloud_project", 1, "").String())
+	// the references to resources not generated keep the labels built from their IDs
+	assert.Equal(t, "dbtcloud_environment.terraform_managed_resource_3.environment_id", resourceReference("dbtcloud_environment", 3, "environment_id").String())
+
+	imports := buildTerraformImportCommand(resourceAddress(jobs, jobItems[1]), jobs.ImportID(jobItems[1]))
+	assert.Equal(t, "terraform import dbtcloud_job.marketing__nightly_full_refresh 900\n", imports)
+}
+
+// TestLabels_NameStrategyCollision checks that the labels suffixed by ID
+// don't take the label of another item.
+func TestLabels_NameStrategyCollision(t *testing.T) {
+	defer func(strategy string) {
+		labelStrategy = strategy
+		resourceLabels = map[string]map[string]string{}
+	}(labelStrategy)
+	labelStrategy = "name"
+	resourceLabels = map[string]map[string]string{}
+
+	groups := resourceHandlers["dbtcloud_group"]
+	groupItems := []dbtcloud.Group{
+		{ID: 5, Name: "Analysts"},
+		{ID: 12, Name: "Analysts"},
+		{ID: 30, Name: "Analysts 12"},
+		{ID: 40, Name: "Analysts 12 12"},
+		{ID: 7, Name: ""},
+		{ID: 8, Name: "terraform managed resource 7"},
+		{ID: 9, Name: "terraform managed resource 7"},
+	}
+	assignResourceLabels(groups, lo.ToAnySlice(groupItems), &accountData{})
+
+	assert.Equal(t, "dbtcloud_group.analysts", resourceAddress(groups, groupItems[0]))
+	assert.Equal(t, "dbtcloud_group.analysts_12_12_12", resourceAddress(groups, groupItems[1]))
+	assert.Equal(t, "dbtcloud_group.analysts_12", resourceAddress(groups, groupItems[2]))
+	assert.Equal(t, "dbtcloud_group.analysts_12_12", resourceAddress(groups, groupItems[3]))
+	assert.Equal(t, "dbtcloud_group.terraform_managed_resource_7", resourceAddress(groups, groupItems[4]))
+	// the label of the item without a name is reserved as well
+	assert.Equal(t, "dbtcloud_group.terraform_managed_resource_7_8", resourceAddress(groups, groupItems[5]))
+	assert.Equal(t, "dbtcloud_group.terraform_managed_resource_7_9", resourceAddress(groups, groupItems[6]))
+}
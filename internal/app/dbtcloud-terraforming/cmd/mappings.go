@@ -6,15 +6,15 @@ import (
 	"github.com/samber/lo"
 )
 
-func mapJobStatusCodeToText(status []any) []string {
+func mapJobStatusCodeToText(status []int) []string {
 
-	JobCompletionTriggerConditionsMappingCodeHuman := map[float64]string{
+	JobCompletionTriggerConditionsMappingCodeHuman := map[int]string{
 		10: "success",
 		20: "error",
 		30: "canceled",
 	}
-	return lo.Map(status, func(s any, _ int) string {
-		return JobCompletionTriggerConditionsMappingCodeHuman[s.(float64)]
+	return lo.Map(status, func(s int, _ int) string {
+		return JobCompletionTriggerConditionsMappingCodeHuman[s]
 	})
 }
 
@@ -1,13 +1,23 @@
 package cmd
 
 import (
+	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/samber/lo"
 )
 
-var outputFile string
+var outputFile, outputDir, splitBy string
+
+// projectFileName matches the names of the files of the projects with
+// --split-by project, without extension.
+var projectFileName = regexp.MustCompile(`^project_\d+$`)
 
 // getOutputWriter returns an io.Writer based on the --output flag
 // If no output file is specified, returns os.Stdout
@@ -26,26 +36,174 @@ func getOutputWriter() (io.Writer, func() error, error) {
 	return f, f.Close, nil
 }
 
-// writeOutput writes the given HCL file to the configured output destination
-func writeOutput(f *hclwrite.File) error {
+// writeString writes the given string to the configured output destination
+func writeString(s string) error {
 	writer, closer, err := getOutputWriter()
 	if err != nil {
 		return err
 	}
 	defer closer()
 
-	_, err = writer.Write(f.Bytes())
+	_, err = writer.Write([]byte(s))
 	return err
 }
 
-// writeString writes the given string to the configured output destination
-func writeString(s string) error {
-	writer, closer, err := getOutputWriter()
+// outputFileName returns the name of the file of the --output-dir layout the
+// output goes to. Without --output-dir, all the output goes to a single file
+// with an empty name, written to --output or stdout.
+func outputFileName(name string) string {
+	if outputDir == "" {
+		return ""
+	}
+	return name
+}
+
+// resourceFileName returns the file of the --output-dir layout the resource
+// of an item goes to: one per resource type, e.g. job.tf, or with
+// --split-by project one per project, e.g. project_123.tf, and account.tf for
+// the resources of the account.
+func resourceFileName(handler ResourceHandler, item any) string {
+	if splitBy == "project" {
+		if projectID := handler.ProjectID(item); projectID != 0 {
+			return fmt.Sprintf("project_%d.tf", projectID)
+		}
+		return "account.tf"
+	}
+	return strings.TrimPrefix(handler.ResourceType(), "dbtcloud_") + ".tf"
+}
+
+// outputFiles is the output of a command, by file name (see outputFileName),
+// in the order the files are created.
+type outputFiles struct {
+	names    []string
+	contents map[string]*bytes.Buffer
+}
+
+func newOutputFiles() *outputFiles {
+	return &outputFiles{contents: map[string]*bytes.Buffer{}}
+}
+
+// file returns the content of the file name, adding it if needed.
+func (o *outputFiles) file(name string) *bytes.Buffer {
+	name = outputFileName(name)
+	if _, ok := o.contents[name]; !ok {
+		o.names = append(o.names, name)
+		o.contents[name] = &bytes.Buffer{}
+	}
+	return o.contents[name]
+}
+
+// append adds the files of other to o. In the single file, the content of
+// other comes after an empty line.
+func (o *outputFiles) append(other *outputFiles) {
+	for _, name := range other.names {
+		content := o.file(name)
+		if content.Len() > 0 {
+			content.WriteString("\n")
+		}
+		content.Write(other.contents[name].Bytes())
+	}
+}
+
+// bytes returns the content of all the files.
+func (o *outputFiles) bytes() []byte {
+	var all bytes.Buffer
+	for _, name := range o.names {
+		all.Write(o.contents[name].Bytes())
+		all.WriteString("\n")
+	}
+	return all.Bytes()
+}
+
+// write writes the files to --output-dir, or the single file to --output or
+// stdout.
+func (o *outputFiles) write() error {
+	if outputDir == "" {
+		return writeString(o.file("").String())
+	}
+	return o.writeDir(outputDir)
+}
+
+// writeDir writes the files to dir, the single file being written to main.tf.
+func (o *outputFiles) writeDir(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	for _, name := range o.names {
+		fileName := name
+		if fileName == "" {
+			fileName = "main.tf"
+		}
+		if err := os.WriteFile(filepath.Join(dir, fileName), o.contents[name].Bytes(), 0644); err != nil {
+			return err
+		}
+	}
+	return o.warnStaleResourceFiles(dir)
+}
+
+// warnStaleResourceFiles warns about the resource files of dir (see
+// resourceFileName) that were not written, e.g. job.tf after removing
+// dbtcloud_job from --resource-types or after changing --split-by. They are
+// left in place as they may have been edited, but Terraform reads them along
+// with the new ones. The outputs without resources, e.g. the ones of import,
+// don't replace the resource files.
+func (o *outputFiles) warnStaleResourceFiles(dir string) error {
+	if !lo.SomeBy(o.names, isResourceFileName) {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
 	if err != nil {
 		return err
 	}
-	defer closer()
+	for _, entry := range entries {
+		if entry.IsDir() || !isResourceFileName(entry.Name()) || lo.Contains(o.names, entry.Name()) {
+			continue
+		}
+		log.Warnf("%s was not written by this run, remove it if its resources are not generated anymore", filepath.Join(dir, entry.Name()))
+	}
+	return nil
+}
 
-	_, err = writer.Write([]byte(s))
-	return err
+// isResourceFileName returns whether name is a file resourceFileName
+// returns, in HCL or JSON syntax.
+func isResourceFileName(name string) bool {
+	name, ok := strings.CutSuffix(strings.TrimSuffix(name, ".json"), ".tf")
+	if !ok {
+		return false
+	}
+	if name == "account" || projectFileName.MatchString(name) {
+		return true
+	}
+	_, ok = resourceHandlers["dbtcloud_"+name]
+	return ok
+}
+
+// hclFiles builds the HCL files of the output, by file name (see
+// outputFileName).
+type hclFiles struct {
+	names []string
+	files map[string]*hclwrite.File
+}
+
+func newHCLFiles() *hclFiles {
+	return &hclFiles{files: map[string]*hclwrite.File{}}
+}
+
+// body returns the body of the file name, adding it if needed.
+func (h *hclFiles) body(name string) *hclwrite.Body {
+	name = outputFileName(name)
+	if _, ok := h.files[name]; !ok {
+		h.names = append(h.names, name)
+		h.files[name] = hclwrite.NewEmptyFile()
+	}
+	return h.files[name].Body()
+}
+
+// output returns the formatted content of the files.
+func (h *hclFiles) output() *outputFiles {
+	output := newOutputFiles()
+	for _, name := range h.names {
+		output.file(name).Write(hclwrite.Format(h.files[name].Bytes()))
+	}
+	return output
 }
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestOutput_ResourceFileName(t *testing.T) {
+	defer func(split string) { splitBy = split }(splitBy)
+
+	job := dbtcloud.Job{ID: 3, ProjectID: 71}
+	group := dbtcloud.Group{ID: 5, Name: "Analysts"}
+
+	splitBy = "resource-type"
+	assert.Equal(t, "job.tf", resourceFileName(resourceHandlers["dbtcloud_job"], job))
+	assert.Equal(t, "group.tf", resourceFileName(resourceHandlers["dbtcloud_group"], group))
+
+	splitBy = "project"
+	assert.Equal(t, "project_71.tf", resourceFileName(resourceHandlers["dbtcloud_job"], job))
+	assert.Equal(t, "account.tf", resourceFileName(resourceHandlers["dbtcloud_group"], group))
+}
+
+func TestOutput_SingleFile(t *testing.T) {
+	defer func(dir string) { outputDir = dir }(outputDir)
+	outputDir = ""
+
+	files := newHCLFiles()
+	files.body("project.tf").SetAttributeValue("a", cty.StringVal("1"))
+	files.body("variables.tf").SetAttributeValue("b", cty.StringVal("2"))
+	output := files.output()
+
+	imports := newOutputFiles()
+	imports.file("imports.sh").WriteString("terraform import dbtcloud_project.terraform_managed_resource_1 1\n")
+	output.append(imports)
+
+	assert.Equal(t, []string{""}, output.names)
+	assert.Equal(t, "a = \"1\"\nb = \"2\"\n\nterraform import dbtcloud_project.terraform_managed_resource_1 1\n", output.file("").String())
+}
+
+func TestOutput_OutputDir(t *testing.T) {
+	defer func(dir string) { outputDir = dir }(outputDir)
+	outputDir = filepath.Join(t.TempDir(), "config")
+
+	files := newHCLFiles()
+	files.body("project.tf").SetAttributeValue("a", cty.StringVal("1"))
+	files.body("variables.tf").SetAttributeValue("b", cty.StringVal("2"))
+	files.body("project.tf").SetAttributeValue("c", cty.StringVal("3"))
+	output := files.output()
+
+	imports := newHCLFiles()
+	imports.body("imports.tf").SetAttributeValue("d", cty.StringVal("4"))
+	output.append(imports.output())
+
+	require.NoError(t, output.write())
+	assert.Equal(t, []string{"project.tf", "variables.tf", "imports.tf"}, output.names)
+
+	for name, want := range map[string]string{
+		"project.tf":   "a = \"1\"\nc = \"3\"\n",
+		"variables.tf": "b = \"2\"\n",
+		"imports.tf":   "d = \"4\"\n",
+	} {
+		got, err := os.ReadFile(filepath.Join(outputDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got), name)
+	}
+}
+
+// TestOutput_StaleResourceFiles checks that the resource files left by a
+// previous run are reported, and that the output of import doesn't report the
+// resource files of generate.
+func TestOutput_StaleResourceFiles(t *testing.T) {
+	defer func(dir string) { outputDir = dir }(outputDir)
+	outputDir = t.TempDir()
+	defer log.SetOutput(log.Out)
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+
+	for _, name := range []string{"job.tf", "project_12.tf.json", "custom.tf", "terraform.tfvars"} {
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, name), nil, 0644))
+	}
+
+	imports := newOutputFiles()
+	imports.file("imports.tf").WriteString("")
+	require.NoError(t, imports.write())
+	assert.Empty(t, logs.String())
+
+	generated := newOutputFiles()
+	generated.file("project.tf").WriteString("")
+	generated.file("providers.tf").WriteString("")
+	require.NoError(t, generated.write())
+	assert.Contains(t, logs.String(), filepath.Join(outputDir, "job.tf")+" was not written by this run")
+	assert.Contains(t, logs.String(), filepath.Join(outputDir, "project_12.tf.json")+" was not written by this run")
+	assert.NotContains(t, logs.String(), "custom.tf")
+	assert.NotContains(t, logs.String(), "imports.tf")
+	assert.FileExists(t, filepath.Join(outputDir, "job.tf"))
+}
@@ -0,0 +1,463 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/samber/lo"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+var target string
+
+// pulumiTypeNames are the names of the resources in the Pulumi provider
+// bridged from the dbt Cloud provider that don't follow the resource types,
+// see pulumiResourceType.
+var pulumiTypeNames = map[string]string{
+	"dbtcloud_bigquery_connection": "BigQueryConnection",
+	"dbtcloud_bigquery_credential": "BigQueryCredential",
+}
+
+// pulumiConfigTypes are the types of the config values by type of variable.
+var pulumiConfigTypes = map[string]string{
+	"string": "string",
+	"number": "integer",
+	"bool":   "boolean",
+}
+
+// pulumiEscaper escapes the ${ sequences of the strings, which are
+// interpolations in Pulumi YAML.
+var pulumiEscaper = strings.NewReplacer("${", "$${")
+
+// pulumiProgram is a Pulumi YAML program, the fields are in the order of the
+// Pulumi documentation.
+type pulumiProgram struct {
+	Name        string                    `yaml:"name"`
+	Runtime     string                    `yaml:"runtime"`
+	Description string                    `yaml:"description"`
+	Config      map[string]any            `yaml:"config,omitempty"`
+	Variables   map[string]any            `yaml:"variables,omitempty"`
+	Resources   map[string]pulumiResource `yaml:"resources"`
+}
+
+type pulumiResource struct {
+	Type       string         `yaml:"type"`
+	Properties map[string]any `yaml:"properties,omitempty"`
+	Options    *pulumiOptions `yaml:"options,omitempty"`
+}
+
+type pulumiOptions struct {
+	Import        string   `yaml:"import,omitempty"`
+	DependsOn     []string `yaml:"dependsOn,omitempty"`
+	IgnoreChanges []string `yaml:"ignoreChanges,omitempty"`
+}
+
+// pulumiFiles returns the generated config converted to a Pulumi YAML
+// program, written to Pulumi.yaml with --output-dir. The .tf files of the
+// config are converted as a whole, the resources being linked across them,
+// and the other files, e.g. terraform.tfvars.example, are dropped.
+func pulumiFiles(files *outputFiles, schema *tfjson.ProviderSchema) (*outputFiles, error) {
+	config := newOutputFiles()
+	for _, name := range files.names {
+		if name == "" || strings.HasSuffix(name, ".tf") {
+			config.file(name).Write(files.contents[name].Bytes())
+		}
+	}
+
+	program, err := hclToPulumiProgram(config.bytes(), schema)
+	if err != nil {
+		return nil, err
+	}
+	content, err := marshalYAML(program)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := newOutputFiles()
+	converted.file("Pulumi.yaml").Write(content)
+	return converted, nil
+}
+
+// hclToPulumiProgram converts the HCL config src to a Pulumi YAML program:
+//   - the resources become Pulumi resources named after their resource type
+//     and label, e.g. job_terraform_managed_resource_123, with the IDs of
+//     their import blocks as import options
+//   - the variables become secret config values and the provider
+//     configuration the config of the provider
+//   - the references become ${...} interpolations and jsonencode fn::toJSON
+//   - the resources whose count is 0 are left out, and the conversion fails
+//     when a count can't be evaluated, e.g. when it reads a data source
+//
+// The expressions that can't be converted are replaced with ---TBD---
+// placeholders, as for the credentials that are not supported yet.
+func hclToPulumiProgram(src []byte, schema *tfjson.ProviderSchema) (*pulumiProgram, error) {
+	file, diags := hclsyntax.ParseConfig(src, "", hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to convert the config to Pulumi: %s", diags.Error())
+	}
+
+	program := &pulumiProgram{
+		Name:        "dbtcloud",
+		Runtime:     "yaml",
+		Description: "dbt Cloud resources generated by dbtcloud-terraforming",
+		Config:      map[string]any{},
+		Variables:   map[string]any{},
+		Resources:   map[string]pulumiResource{},
+	}
+	converter := pulumiConverter{src: src}
+	evalContext := pulumiLocalsContext(file.Body.(*hclsyntax.Body))
+
+	imports := map[string]string{}
+	for _, block := range file.Body.(*hclsyntax.Body).Blocks {
+		switch block.Type {
+		case "resource":
+			resourceType, label := block.Labels[0], block.Labels[1]
+			if count, ok := block.Body.Attributes["count"]; ok {
+				// Pulumi YAML has no count, the resources are kept when
+				// there is one instance and left out when there is none
+				instances, err := pulumiCount(count.Expr, evalContext)
+				if err != nil {
+					return nil, fmt.Errorf("failed to convert %s.%s to Pulumi: %w", resourceType, label, err)
+				}
+				if instances == 0 {
+					log.Debugf("skipping %s.%s: its count is 0", resourceType, label)
+					continue
+				}
+			}
+			var resourceSchema *tfjson.SchemaBlock
+			if schema != nil && schema.ResourceSchemas[resourceType] != nil {
+				resourceSchema = schema.ResourceSchemas[resourceType].Block
+			}
+			resource := pulumiResource{
+				Type:       pulumiResourceType(resourceType),
+				Properties: converter.body(block.Body, resourceSchema),
+				Options:    &pulumiOptions{},
+			}
+			if dependsOn, ok := block.Body.Attributes["depends_on"]; ok {
+				resource.Options.DependsOn = converter.dependencies(dependsOn.Expr)
+			}
+			for _, lifecycle := range block.Body.Blocks {
+				if ignoreChanges, ok := lifecycle.Body.Attributes["ignore_changes"]; lifecycle.Type == "lifecycle" && ok {
+					resource.Options.IgnoreChanges = lo.Map(converter.elements(ignoreChanges.Expr), func(attribute string, _ int) string {
+						return pulumiPropertyName(attribute)
+					})
+				}
+			}
+			program.Resources[pulumiResourceName(resourceType, label)] = resource
+		case "import":
+			address := converter.source(block.Body.Attributes["to"].Expr)
+			resourceType, label, _ := strings.Cut(address, ".")
+			id, diags := block.Body.Attributes["id"].Expr.Value(nil)
+			if diags.HasErrors() || id.Type() != cty.String {
+				return nil, fmt.Errorf("unsupported import ID for %s", address)
+			}
+			imports[pulumiResourceName(resourceType, label)] = id.AsString()
+		case "variable":
+			variable := map[string]any{"type": "string", "secret": true}
+			if variableType, ok := block.Body.Attributes["type"]; ok && pulumiConfigTypes[converter.source(variableType.Expr)] != "" {
+				variable["type"] = pulumiConfigTypes[converter.source(variableType.Expr)]
+			}
+			if description, ok := block.Body.Attributes["description"]; ok {
+				if value, diags := description.Expr.Value(nil); !diags.HasErrors() && value.Type() == cty.String {
+					variable["description"] = value.AsString()
+				}
+			}
+			program.Config[block.Labels[0]] = variable
+		case "provider":
+			for name, value := range converter.body(block.Body, nil) {
+				program.Config[block.Labels[0]+":"+name] = value
+			}
+		case "locals":
+			for name, attribute := range block.Body.Attributes {
+				program.Variables[name] = converter.expression(attribute.Expr, cty.DynamicPseudoType)
+			}
+		case "terraform":
+			// the provider is installed by Pulumi
+		default:
+			log.Warnf("the %s blocks can't be converted to Pulumi", block.Type)
+		}
+	}
+
+	for name, resource := range program.Resources {
+		resource.Options.Import = imports[name]
+		if resource.Options.Import == "" && len(resource.Options.DependsOn) == 0 && len(resource.Options.IgnoreChanges) == 0 {
+			resource.Options = nil
+		}
+		program.Resources[name] = resource
+	}
+	return program, nil
+}
+
+// pulumiLocalsContext returns the context to evaluate the expressions
+// referencing the locals of body whose values are known without Terraform,
+// e.g. not the ones reading data sources.
+func pulumiLocalsContext(body *hclsyntax.Body) *hcl.EvalContext {
+	locals := map[string]cty.Value{}
+	evalContext := &hcl.EvalContext{
+		Variables: map[string]cty.Value{},
+		// the function of the counts generated for the users looked up by
+		// email
+		Functions: map[string]function.Function{"length": stdlib.LengthFunc},
+	}
+	// the locals can reference each other, they are evaluated until no
+	// more of them are known
+	for known := -1; known != len(locals); {
+		known = len(locals)
+		evalContext.Variables["local"] = cty.ObjectVal(locals)
+		for _, block := range body.Blocks {
+			if block.Type != "locals" {
+				continue
+			}
+			for name, attribute := range block.Body.Attributes {
+				if _, ok := locals[name]; ok {
+					continue
+				}
+				if value, diags := attribute.Expr.Value(evalContext); !diags.HasErrors() && value.IsWhollyKnown() {
+					locals[name] = value
+				}
+			}
+		}
+	}
+	return evalContext
+}
+
+// pulumiCount returns the number of instances of a resource with count,
+// either 0 or 1 as the Pulumi resources have a single instance.
+func pulumiCount(expr hclsyntax.Expression, evalContext *hcl.EvalContext) (int, error) {
+	value, diags := expr.Value(evalContext)
+	if diags.HasErrors() || !value.IsWhollyKnown() || value.IsNull() || value.Type() != cty.Number {
+		return 0, fmt.Errorf("its count can't be evaluated without Terraform")
+	}
+	switch {
+	case value.Equals(cty.Zero).True():
+		return 0, nil
+	case value.Equals(cty.NumberIntVal(1)).True():
+		return 1, nil
+	}
+	return 0, fmt.Errorf("its count is %s, Pulumi resources have a single instance", value.AsBigFloat().String())
+}
+
+// pulumiResourceType returns the type of the resource in the Pulumi provider,
+// e.g. dbtcloud:index/snowflakeCredential:SnowflakeCredential.
+func pulumiResourceType(resourceType string) string {
+	name, ok := pulumiTypeNames[resourceType]
+	if !ok {
+		name = pulumiPropertyName(strings.TrimPrefix(resourceType, "dbtcloud_"))
+		name = strings.ToUpper(name[:1]) + name[1:]
+	}
+	return fmt.Sprintf("dbtcloud:index/%s:%s", strings.ToLower(name[:1])+name[1:], name)
+}
+
+// pulumiResourceName returns the name of the resource in the program, the
+// labels of the resources repeating across resource types.
+func pulumiResourceName(resourceType, label string) string {
+	return strings.TrimPrefix(resourceType, "dbtcloud_") + "_" + label
+}
+
+// pulumiPropertyName returns the name of an attribute in the Pulumi
+// provider, e.g. environment_id to environmentId.
+func pulumiPropertyName(attribute string) string {
+	parts := strings.Split(attribute, "_")
+	for i, part := range parts[1:] {
+		if part != "" {
+			parts[i+1] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// pulumiConverter converts the expressions of the HCL config src.
+type pulumiConverter struct {
+	src []byte
+}
+
+func (c pulumiConverter) source(expr hclsyntax.Expression) string {
+	return strings.TrimSpace(string(expr.Range().SliceBytes(c.src)))
+}
+
+// elements returns the sources of the elements of a list, e.g. the
+// attribute names of ignore_changes.
+func (c pulumiConverter) elements(expr hclsyntax.Expression) []string {
+	tuple, ok := expr.(*hclsyntax.TupleConsExpr)
+	if !ok {
+		return nil
+	}
+	return lo.Map(tuple.Exprs, func(elem hclsyntax.Expression, _ int) string {
+		return c.source(elem)
+	})
+}
+
+// dependencies returns the resources of depends_on, referenced by name in
+// Pulumi.
+func (c pulumiConverter) dependencies(expr hclsyntax.Expression) []string {
+	return lo.Map(c.elements(expr), func(address string, _ int) string {
+		resourceType, label, _ := strings.Cut(address, ".")
+		return fmt.Sprintf("${%s}", pulumiResourceName(resourceType, label))
+	})
+}
+
+// body returns the properties of a resource or of a nested block. The nested
+// blocks limited to one item are objects in Pulumi, the others are lists.
+// Without schema, the nested blocks are lists.
+func (c pulumiConverter) body(body *hclsyntax.Body, schema *tfjson.SchemaBlock) map[string]any {
+	properties := map[string]any{}
+	for name, attribute := range body.Attributes {
+		switch name {
+		case "depends_on":
+			// an option of the resource in Pulumi
+			continue
+		case "count":
+			// evaluated by hclToPulumiProgram
+			continue
+		}
+		ty := cty.DynamicPseudoType
+		if schema != nil && schema.Attributes[name] != nil {
+			ty = schemaAttributeType(schema.Attributes[name])
+		}
+		properties[pulumiPropertyName(name)] = c.expression(attribute.Expr, ty)
+	}
+
+	for _, block := range body.Blocks {
+		if block.Type == "lifecycle" {
+			continue
+		}
+		var blockSchema *tfjson.SchemaBlockType
+		if schema != nil {
+			blockSchema = schema.NestedBlocks[block.Type]
+		}
+		var nestedSchema *tfjson.SchemaBlock
+		if blockSchema != nil {
+			nestedSchema = blockSchema.Block
+		}
+		content := c.body(block.Body, nestedSchema)
+
+		name := pulumiPropertyName(block.Type)
+		if blockSchema != nil && (blockSchema.MaxItems == 1 || blockSchema.NestingMode == tfjson.SchemaNestingModeSingle) {
+			properties[name] = content
+			continue
+		}
+		list, _ := properties[name].([]any)
+		properties[name] = append(list, content)
+	}
+	return properties
+}
+
+// schemaAttributeType returns the type of an attribute of the provider
+// schema, the nested attributes of the plugin framework being objects.
+func schemaAttributeType(attribute *tfjson.SchemaAttribute) cty.Type {
+	if attribute.AttributeNestedType == nil {
+		if attribute.AttributeType == cty.NilType {
+			return cty.DynamicPseudoType
+		}
+		return attribute.AttributeType
+	}
+
+	attributes := map[string]cty.Type{}
+	for name, nested := range attribute.AttributeNestedType.Attributes {
+		attributes[name] = schemaAttributeType(nested)
+	}
+	object := cty.Object(attributes)
+	switch attribute.AttributeNestedType.NestingMode {
+	case tfjson.SchemaNestingModeList:
+		return cty.List(object)
+	case tfjson.SchemaNestingModeSet:
+		return cty.Set(object)
+	case tfjson.SchemaNestingModeMap:
+		return cty.Map(object)
+	}
+	return object
+}
+
+// expression returns the value of an expression of type ty in the program.
+// The keys of the objects are the names of their attributes in Pulumi,
+// unlike the keys of the maps, e.g. the names of the environments of
+// environment_values, and of the JSON documents.
+func (c pulumiConverter) expression(expr hclsyntax.Expression, ty cty.Type) any {
+	switch expr := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		values := []any{}
+		for i, elem := range expr.Exprs {
+			elemType := cty.DynamicPseudoType
+			switch {
+			case ty.IsListType(), ty.IsSetType():
+				elemType = ty.ElementType()
+			case ty.IsTupleType() && i < len(ty.TupleElementTypes()):
+				elemType = ty.TupleElementTypes()[i]
+			}
+			values = append(values, c.expression(elem, elemType))
+		}
+		return values
+	case *hclsyntax.ObjectConsExpr:
+		values := map[string]any{}
+		for _, item := range expr.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || key.Type() != cty.String || key.IsNull() {
+				return c.unsupported(expr)
+			}
+			name, valueType := key.AsString(), cty.DynamicPseudoType
+			switch {
+			case ty.IsObjectType():
+				if ty.HasAttribute(name) {
+					valueType = ty.AttributeType(name)
+				}
+				name = pulumiPropertyName(name)
+			case ty.IsMapType():
+				valueType = ty.ElementType()
+			}
+			values[name] = c.expression(item.ValueExpr, valueType)
+		}
+		return values
+	case *hclsyntax.FunctionCallExpr:
+		if expr.Name == "jsonencode" && len(expr.Args) == 1 {
+			return map[string]any{"fn::toJSON": c.expression(expr.Args[0], cty.DynamicPseudoType)}
+		}
+		return c.unsupported(expr)
+	case *hclsyntax.ScopeTraversalExpr:
+		return c.reference(expr)
+	}
+
+	if len(expr.Variables()) == 0 {
+		if value, diags := expr.Value(nil); !diags.HasErrors() {
+			return literalValue(value, pulumiEscaper)
+		}
+	}
+	return c.unsupported(expr)
+}
+
+// reference returns the interpolation of a reference: to a resource, e.g.
+// ${job_terraform_managed_resource_123.id}, to a config value for the
+// variables or to a variable for the locals.
+func (c pulumiConverter) reference(expr *hclsyntax.ScopeTraversalExpr) any {
+	path := []string{}
+	for _, step := range expr.Traversal {
+		switch step := step.(type) {
+		case hcl.TraverseRoot:
+			path = append(path, step.Name)
+		case hcl.TraverseAttr:
+			path = append(path, step.Name)
+		default:
+			return c.unsupported(expr)
+		}
+	}
+
+	switch {
+	case len(path) == 2 && (path[0] == "var" || path[0] == "local"):
+		return fmt.Sprintf("${%s}", path[1])
+	case len(path) >= 3 && strings.HasPrefix(path[0], "dbtcloud_"):
+		attributes := lo.Map(path[2:], func(attribute string, _ int) string {
+			return pulumiPropertyName(attribute)
+		})
+		return fmt.Sprintf("${%s.%s}", pulumiResourceName(path[0], path[1]), strings.Join(attributes, "."))
+	}
+	return c.unsupported(expr)
+}
+
+func (c pulumiConverter) unsupported(expr hclsyntax.Expression) string {
+	log.Warnf("the expression %s at %s can't be converted to Pulumi and needs to be filled by hand", c.source(expr), expr.Range())
+	return fmt.Sprintf("---TBD---%s---", c.source(expr))
+}
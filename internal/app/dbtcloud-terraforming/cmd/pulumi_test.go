@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestPulumi_Program(t *testing.T) {
+	src := `
+resource "dbtcloud_job" "terraform_managed_resource_1" {
+  environment_id = dbtcloud_environment.terraform_managed_resource_100.environment_id
+  execute_steps  = ["dbt build --vars '{\"a\": \"$${b}\"}'"]
+  num_threads    = 4
+  triggers = {
+    github_webhook = false
+    schedule       = true
+  }
+  job_completion_trigger_condition {
+    job_id   = dbtcloud_job.terraform_managed_resource_2.id
+    statuses = ["success"]
+  }
+  depends_on = [dbtcloud_project.terraform_managed_resource_10]
+  lifecycle {
+    ignore_changes = [execute_steps]
+  }
+}
+
+resource "dbtcloud_environment" "terraform_managed_resource_100" {
+  extended_attributes = { target_name = "prod" }
+  custom_branch       = jsonencode({ dbt_version = "latest" })
+  credential_id       = var.token
+}
+
+variable "token" {
+  type        = string
+  description = "The token"
+}
+
+variable "count" {
+  type = number
+}
+
+import {
+  to = dbtcloud_job.terraform_managed_resource_1
+  id = "1"
+}
+`
+	schema := &tfjson.ProviderSchema{
+		ResourceSchemas: map[string]*tfjson.Schema{
+			"dbtcloud_job": {Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"environment_id": {AttributeType: cty.Number},
+					"triggers":       {AttributeType: cty.Map(cty.Bool)},
+				},
+				NestedBlocks: map[string]*tfjson.SchemaBlockType{
+					"job_completion_trigger_condition": {
+						NestingMode: tfjson.SchemaNestingModeSet,
+						MaxItems:    1,
+						Block: &tfjson.SchemaBlock{Attributes: map[string]*tfjson.SchemaAttribute{
+							"job_id": {AttributeType: cty.Number},
+						}},
+					},
+				},
+			}},
+			"dbtcloud_environment": {Block: &tfjson.SchemaBlock{
+				Attributes: map[string]*tfjson.SchemaAttribute{
+					"extended_attributes": {AttributeNestedType: &tfjson.SchemaNestedAttributeType{
+						NestingMode: tfjson.SchemaNestingModeSingle,
+						Attributes: map[string]*tfjson.SchemaAttribute{
+							"target_name": {AttributeType: cty.String},
+						},
+					}},
+				},
+			}},
+		},
+	}
+
+	program, err := hclToPulumiProgram([]byte(src), schema)
+	require.NoError(t, err)
+
+	assert.Equal(t, pulumiResource{
+		Type: "dbtcloud:index/job:Job",
+		Properties: map[string]any{
+			"environmentId": "${environment_terraform_managed_resource_100.environmentId}",
+			"executeSteps":  []any{`dbt build --vars '{"a": "$${b}"}'`},
+			"numThreads":    int64(4),
+			"triggers":      map[string]any{"github_webhook": false, "schedule": true},
+			"jobCompletionTriggerCondition": map[string]any{
+				"jobId":    "${job_terraform_managed_resource_2.id}",
+				"statuses": []any{"success"},
+			},
+		},
+		Options: &pulumiOptions{
+			Import:        "1",
+			DependsOn:     []string{"${project_terraform_managed_resource_10}"},
+			IgnoreChanges: []string{"executeSteps"},
+		},
+	}, program.Resources["job_terraform_managed_resource_1"])
+
+	assert.Equal(t, pulumiResource{
+		Type: "dbtcloud:index/environment:Environment",
+		Properties: map[string]any{
+			"extendedAttributes": map[string]any{"targetName": "prod"},
+			"customBranch":       map[string]any{"fn::toJSON": map[string]any{"dbt_version": "latest"}},
+			"credentialId":       "${token}",
+		},
+	}, program.Resources["environment_terraform_managed_resource_100"])
+
+	assert.Equal(t, map[string]any{
+		"token": map[string]any{"type": "string", "secret": true, "description": "The token"},
+		"count": map[string]any{"type": "integer", "secret": true},
+	}, program.Config)
+}
+
+// TestPulumi_Unsupported checks that the expressions without equivalent in
+// Pulumi YAML are replaced with placeholders.
+func TestPulumi_Unsupported(t *testing.T) {
+	program, err := hclToPulumiProgram([]byte(`resource "dbtcloud_job" "a" {
+  name = local.deactivate ? "a" : "b"
+  id   = data.dbtcloud_users.all.users[0].id
+}
+`), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"name": `---TBD---local.deactivate ? "a" : "b"---`,
+		"id":   "---TBD---data.dbtcloud_users.all.users[0].id---",
+	}, program.Resources["job_a"].Properties)
+	assert.Nil(t, program.Resources["job_a"].Options)
+}
+
+// TestPulumi_Count checks that the resources whose count is 0 are left out
+// with their import, and that the counts Pulumi can't evaluate are refused.
+func TestPulumi_Count(t *testing.T) {
+	program, err := hclToPulumiProgram([]byte(`locals {
+  details_a = []
+  count_a   = length(local.details_a)
+}
+
+resource "dbtcloud_user_groups" "terraform_managed_resource_7" {
+  count   = local.count_a
+  user_id = 7
+}
+
+resource "dbtcloud_user_groups" "terraform_managed_resource_8" {
+  count   = 1
+  user_id = 8
+}
+
+import {
+  to = dbtcloud_user_groups.terraform_managed_resource_7
+  id = "7"
+}
+`), nil)
+	require.NoError(t, err)
+	assert.NotContains(t, program.Resources, "user_groups_terraform_managed_resource_7")
+	assert.Equal(t, map[string]any{"userId": int64(8)}, program.Resources["user_groups_terraform_managed_resource_8"].Properties)
+
+	_, err = hclToPulumiProgram([]byte(`locals {
+  count_a = length([for user in data.dbtcloud_users.all.users : user if user.email == "a@example.com"])
+}
+
+resource "dbtcloud_user_groups" "terraform_managed_resource_7" {
+  count   = local.count_a
+  user_id = 7
+}
+`), nil)
+	assert.ErrorContains(t, err, "dbtcloud_user_groups.terraform_managed_resource_7")
+}
+
+// TestPulumi_UserGroups checks that the users only in the default groups are
+// not generated for Pulumi, and that the others are generated without count
+// as the users are not looked up by email.
+func TestPulumi_UserGroups(t *testing.T) {
+	fakeAPI(t, map[string]string{
+		"/v2/accounts/9999/projects/": listResponse(),
+		"/v3/accounts/9999/groups/":   listResponse(`{"id": 1, "name": "Everyone"}`, `{"id": 2, "name": "Analysts"}`),
+		"/v3/accounts/9999/users/": listResponse(
+			`{"id": 7, "email": "a@example.com", "permissions": [{"groups": [{"id": 1, "name": "Everyone"}]}]}`,
+			`{"id": 8, "email": "b@example.com", "permissions": [{"groups": [{"id": 1, "name": "Everyone"}, {"id": 2, "name": "Analysts"}]}]}`,
+		),
+	})
+	defer func(t string, linked []string) {
+		target = t
+		listLinkedResources = linked
+	}(target, listLinkedResources)
+	target = "pulumi-yaml"
+	listLinkedResources = []string{"all"}
+
+	handler := resourceHandlers["dbtcloud_user_groups"]
+	data := prefetchAccountData(context.Background(), []ResourceHandler{resourceHandlers["dbtcloud_group"], handler})
+	items, err := handler.Fetch(context.Background(), data)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	user := handler.Transform(items[0], data)
+	assert.NotContains(t, user, "count")
+	assert.Equal(t, 8, user["user_id"])
+}
+
+func TestPulumi_Files(t *testing.T) {
+	defer func() { outputDir = "" }()
+	outputDir = "config"
+
+	files := newOutputFiles()
+	files.file("job.tf").WriteString("resource \"dbtcloud_job\" \"a\" {\n  name = \"a\"\n}\n")
+	files.file("imports.tf").WriteString("import {\n  to = dbtcloud_job.a\n  id = \"1\"\n}\n")
+	files.file("terraform.tfvars.example").WriteString("token = \"\"\n")
+
+	converted, err := pulumiFiles(files, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Pulumi.yaml"}, converted.names)
+	assert.Contains(t, converted.file("Pulumi.yaml").String(), "name: dbtcloud\nruntime: yaml\n")
+	assert.Contains(t, converted.file("Pulumi.yaml").String(), "resources:\n  job_a:\n    type: dbtcloud:index/job:Job\n    properties:\n      name: a\n    options:\n      import: \"1\"\n")
+}
+
+func TestPulumi_Names(t *testing.T) {
+	assert.Equal(t, "dbtcloud:index/snowflakeCredential:SnowflakeCredential", pulumiResourceType("dbtcloud_snowflake_credential"))
+	assert.Equal(t, "dbtcloud:index/bigQueryConnection:BigQueryConnection", pulumiResourceType("dbtcloud_bigquery_connection"))
+	assert.Equal(t, "job_terraform_managed_resource_1", pulumiResourceName("dbtcloud_job", "terraform_managed_resource_1"))
+	assert.Equal(t, "environmentId", pulumiPropertyName("environment_id"))
+	assert.Equal(t, "name", pulumiPropertyName("name"))
+}
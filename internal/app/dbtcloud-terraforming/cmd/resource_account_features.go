@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/samber/lo"
+)
+
+func init() {
+	// account_features is a singleton: one set of feature flags per account,
+	// not a list of items, so there's no per-item id to derive a resource
+	// label from. The import id is the account id.
+	registerResource(resource[map[string]any]{
+		resourceType: "dbtcloud_account_features",
+		scope:        "Account",
+		fetch: func(ctx context.Context, _ *accountData) ([]map[string]any, error) {
+			features, err := dbtCloudClient.GetAccountFeatures(ctx)
+			return lo.Map(features, func(feature any, _ int) map[string]any {
+				return feature.(map[string]any)
+			}), err
+		},
+		transform: func(features map[string]any, _ *accountData) map[string]any {
+			return features
+		},
+		name: func(_ map[string]any) string {
+			return "account_features"
+		},
+		label: func(_ map[string]any) string {
+			return "account_features"
+		},
+		importID: func(features map[string]any) string {
+			return fmt.Sprintf("%v", features["id"])
+		},
+	})
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Connection]{
+		resourceType: "dbtcloud_bigquery_connection",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project"},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Connection, error) {
+			return dbtCloudClient.GetBigQueryConnections(ctx, listFilterProjects)
+		},
+		transform: func(connection dbtcloud.Connection, _ *accountData) map[string]any {
+			connectionTyped := connection.Raw
+			projectID := connection.ProjectID
+			connectionID := connection.ID
+
+			// we "promote" all details fields one level up like in the Terraform resource
+			for detailKey, detailVal := range connection.Details {
+				connectionTyped[detailKey] = detailVal
+			}
+			// we have to put back the ID as it is only set at the top level
+			connectionTyped["id"] = connectionID
+
+			// we set the project IDs to the correct values
+			// unfortunately project ID can mean a dbt Cloud project or a GCP project
+			connectionTyped["project_id"] = projectID
+			connectionTyped["gcp_project_id"] = connection.Details["project_id"]
+
+			// we add the secure fields
+			varName := fmt.Sprintf("dbtcloud_bigquery_connection_private_key_%d", connectionID)
+			targetURL := settingsURL("connections/%d/", connectionID)
+			AllTFVars = append(AllTFVars, tfVar{
+				varType:        "string",
+				varName:        varName,
+				varDescription: "The private key for the bigquery connection " + fmt.Sprintf("%d", connectionID) + " - " + targetURL,
+			})
+			connectionTyped["private_key"] = hclReference("var." + varName)
+
+			if linkResource("dbtcloud_project") {
+				connectionTyped["project_id"] = resourceReference("dbtcloud_project", projectID, "id")
+			}
+			return connectionTyped
+		},
+		name: func(connection dbtcloud.Connection) string {
+			return connection.Name
+		},
+		label:    connectionLabel,
+		importID: connectionImportID,
+		projectID: func(connection dbtcloud.Connection) int {
+			return connection.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "connection_id"},
+			label:    []string{"connection_id"},
+		},
+		inventory: func(connection dbtcloud.Connection, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{column("warehouse_type", connection.WarehouseType())}
+		},
+		url: func(connection dbtcloud.Connection) string {
+			return settingsURL("connections/%d/", connection.ID)
+		},
+	})
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Credential]{
+		resourceType: "dbtcloud_bigquery_credential",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project"},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Credential, error) {
+			return dbtCloudClient.GetBigQueryCredentials(ctx, listFilterProjects)
+		},
+		// the inventory lists the credentials no environment uses as well
+		list: func(ctx context.Context, _ *accountData) ([]dbtcloud.Credential, error) {
+			return dbtCloudClient.GetAllWarehouseCredentials(ctx, listFilterProjects, "bigquery")
+		},
+		transform: func(credential dbtcloud.Credential, _ *accountData) map[string]any {
+			credentialTyped := credential.Raw
+
+			credentialTyped["num_threads"] = credentialTyped["threads"]
+			credentialTyped["dataset"] = credentialTyped["schema"]
+
+			if linkResource("dbtcloud_project") {
+				credentialTyped["project_id"] = resourceReference("dbtcloud_project", credential.ProjectID, "id")
+			}
+			return credentialTyped
+		},
+		label:    credentialLabel,
+		importID: credentialImportID,
+		projectID: func(credential dbtcloud.Credential) int {
+			return credential.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "credential_id"},
+			label:    []string{"credential_id"},
+		},
+		inventory: credentialInventory,
+		url:       credentialURL,
+	})
+}
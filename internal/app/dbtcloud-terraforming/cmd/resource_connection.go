@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Connection]{
+		resourceType: "dbtcloud_connection",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project"},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Connection, error) {
+			return dbtCloudClient.GetGenericConnections(ctx, listFilterProjects)
+		},
+		transform: func(connection dbtcloud.Connection, _ *accountData) map[string]any {
+			connectionTyped := connection.Raw
+
+			// we "promote" all details fields one level up like in the Terraform resource
+			for detailKey, detailVal := range connection.Details {
+				connectionTyped[detailKey] = detailVal
+			}
+			// we have to put back the ID as it is only set at the top level
+			connectionTyped["id"] = connection.ID
+
+			if connection.Type == "snowflake" {
+				connectionTyped["oauth_client_id"] = "---TBD if using OAuth, otherwise delete---"
+				connectionTyped["oauth_client_secret"] = "---TBD if using OAuth, otherwise delete---"
+			}
+
+			if connection.Type == "redshift" || connection.Type == "postgres" {
+				connectionTyped["host_name"] = connectionTyped["hostname"]
+				connectionTyped["database"] = connectionTyped["dbname"]
+			}
+
+			if connection.WarehouseType() == "adapter/databricks" {
+				connectionTyped["host_name"] = connection.AdapterField("host")
+				connectionTyped["http_path"] = connection.AdapterField("http_path")
+				connectionTyped["catalog"] = connection.AdapterField("catalog")
+				connectionTyped["database"] = "<set-empty-string>"
+			}
+			// we don't support adapter/spark yet
+
+			if linkResource("dbtcloud_project") {
+				connectionTyped["project_id"] = resourceReference("dbtcloud_project", connection.ProjectID, "id")
+			}
+			return connectionTyped
+		},
+		name: func(connection dbtcloud.Connection) string {
+			return connection.Name
+		},
+		label:    connectionLabel,
+		importID: connectionImportID,
+		projectID: func(connection dbtcloud.Connection) int {
+			return connection.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "connection_id"},
+			label:    []string{"connection_id"},
+		},
+		inventory: func(connection dbtcloud.Connection, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{column("warehouse_type", connection.WarehouseType())}
+		},
+		url: func(connection dbtcloud.Connection) string {
+			return settingsURL("connections/%d/", connection.ID)
+		},
+	})
+}
+
+// connectionLabel and connectionImportID are shared with
+// dbtcloud_bigquery_connection.
+func connectionLabel(connection dbtcloud.Connection) string {
+	return fmt.Sprintf("%d", connection.ID)
+}
+
+func connectionImportID(connection dbtcloud.Connection) string {
+	return fmt.Sprintf("%d:%d", connection.ProjectID, connection.ID)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Credential]{
+		resourceType: "dbtcloud_databricks_credential",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project"},
+		fetch:        fetchDatabricksCredentials,
+		// the inventory lists the credentials no environment uses as well
+		list: func(ctx context.Context, _ *accountData) ([]dbtcloud.Credential, error) {
+			return dbtCloudClient.GetAllWarehouseCredentials(ctx, listFilterProjects, "databricks")
+		},
+		transform: func(credential dbtcloud.Credential, _ *accountData) map[string]any {
+			credentialTyped := credential.Raw
+
+			// we remove the adapter_id as we will use global connections
+			credentialTyped["adapter_id"] = ""
+
+			credentialID := credential.ID
+			credentialTyped["adapter_type"] = "databricks"
+
+			targetURL := deployURL("projects/%d/environments/%d/settings/", credential.ProjectID, credential.EnvironmentID)
+			varName := fmt.Sprintf("dbtcloud_databricks_credential_token_%d", credentialID)
+			AllTFVars = append(AllTFVars, tfVar{
+				varType:        "string",
+				varName:        varName,
+				varDescription: "The token for the databricks credential " + fmt.Sprintf("%d", credentialID) + " - " + targetURL,
+			})
+			credentialTyped["token"] = hclReference("var." + varName)
+
+			// the target_name is deprecated at the credentials level
+			delete(credentialTyped, "target_name")
+
+			if linkResource("dbtcloud_project") {
+				credentialTyped["project_id"] = resourceReference("dbtcloud_project", credential.ProjectID, "id")
+			}
+			return credentialTyped
+		},
+		label:    credentialLabel,
+		importID: credentialImportID,
+		projectID: func(credential dbtcloud.Credential) int {
+			return credential.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "credential_id"},
+			label:    []string{"credential_id"},
+		},
+		inventory: credentialInventory,
+		url:       credentialURL,
+	})
+}
+
+// fetchDatabricksCredentials returns the Databricks credentials with the
+// fields of their unencrypted details added to their payload. The
+// credentials whose details can't be fetched are skipped.
+func fetchDatabricksCredentials(ctx context.Context, _ *accountData) ([]dbtcloud.Credential, error) {
+	listCredentials, err := dbtCloudClient.GetDatabricksCredentials(ctx, listFilterProjects)
+	errs := []error{err}
+
+	credentials := []dbtcloud.Credential{}
+	for _, credential := range listCredentials {
+		credentialDetails, err := dbtCloudClient.GetCredential(ctx, credential.ProjectID, credential.ID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for key, value := range credentialDetails.UnencryptedCredentialDetails {
+			credential.Raw[key] = value
+		}
+		credentials = append(credentials, credential)
+	}
+
+	return credentials, errors.Join(errs...)
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Environment]{
+		resourceType: "dbtcloud_environment",
+		scope:        "Project",
+		dependencies: []string{
+			"dbtcloud_project", "dbtcloud_profile", "dbtcloud_global_connection", "dbtcloud_extended_attributes",
+			"dbtcloud_snowflake_credential", "dbtcloud_bigquery_credential", "dbtcloud_databricks_credential",
+		},
+		// the payload of the environments is modified by
+		// transformEnvironmentForGenerate, so we don't use the prefetched ones
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Environment, error) {
+			return dbtCloudClient.GetEnvironments(ctx, listFilterProjects)
+		},
+		transform: func(environment dbtcloud.Environment, _ *accountData) map[string]any {
+			return transformEnvironmentForGenerate(environment)
+		},
+		name: func(environment dbtcloud.Environment) string {
+			return environment.Name
+		},
+		identity: func(environment dbtcloud.Environment) map[string]any {
+			return map[string]any{"project_id": environment.ProjectID, "name": environment.Name}
+		},
+		label: func(environment dbtcloud.Environment) string {
+			return fmt.Sprintf("%d", environment.ID)
+		},
+		importID: func(environment dbtcloud.Environment) string {
+			return fmt.Sprintf("%d:%d", environment.ProjectID, environment.ID)
+		},
+		projectID: func(environment dbtcloud.Environment) int {
+			return environment.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "environment_id"},
+			label:    []string{"environment_id"},
+		},
+		links: func(environment dbtcloud.Environment, _ *accountData) []resourceLink {
+			links := []resourceLink{linkTo("project_id", "dbtcloud_project", environment.ProjectID)}
+			if environment.PrimaryProfileID != nil {
+				return append(links, linkTo("primary_profile_id", "dbtcloud_profile", fmt.Sprintf("%d_%d", environment.ProjectID, *environment.PrimaryProfileID)))
+			}
+			if environment.ConnectionID != nil {
+				links = append(links, linkTo("connection_id", "dbtcloud_global_connection", *environment.ConnectionID))
+			}
+			if credentialType := credentialResourceType(environment.Credentials); environment.CredentialsID != nil && credentialType != "" {
+				links = append(links, linkTo("credential_id", credentialType, *environment.CredentialsID))
+			}
+			if environment.ExtendedAttributesID != nil {
+				links = append(links, linkTo("extended_attributes_id", "dbtcloud_extended_attributes", *environment.ExtendedAttributesID))
+			}
+			return links
+		},
+		inventory: func(environment dbtcloud.Environment, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{
+				column("type", environment.Type),
+				column("deployment_type", environment.DeploymentType),
+				column("dbt_version", environment.DbtVersion),
+				column("primary_profile_id", environment.PrimaryProfileID),
+				column("connection_id", environment.ConnectionID),
+				column("credentials_id", environment.CredentialsID),
+			}
+		},
+		url: func(environment dbtcloud.Environment) string {
+			return deployURL("projects/%d/environments/%d/settings/", environment.ProjectID, environment.ID)
+		},
+	})
+}
+
+// transformEnvironmentForGenerate applies the dbtcloud_environment-specific
+// generate-time transforms to a single environment payload: optionally
+// linking project_id to a generated dbtcloud_project resource, and - the
+// core either/or invariant - binding the environment to either a profile
+// (via primary_profile_id) or the legacy connection_id/credential_id/
+// extended_attributes_id trio, but never both. The provider rejects setting
+// primary_profile_id alongside any of the legacy trio, so whichever
+// primary_profile_id is present on the payload decides which branch runs,
+// and the branch not taken is guaranteed absent from the result.
+//
+// It mutates and returns the raw payload of the environment in place,
+// matching the mutate-in-place style used by every other resource type.
+func transformEnvironmentForGenerate(environment dbtcloud.Environment) map[string]any {
+	environmentsTyped := environment.Raw
+
+	if linkResource("dbtcloud_project") {
+		environmentsTyped["project_id"] = resourceReference("dbtcloud_project", environment.ProjectID, "id")
+	}
+
+	if environment.PrimaryProfileID != nil {
+		if linkResource("dbtcloud_profile") {
+			environmentsTyped["primary_profile_id"] = resourceReference("dbtcloud_profile", fmt.Sprintf("%d_%d", environment.ProjectID, *environment.PrimaryProfileID), "profile_id")
+		}
+
+		// omit the legacy trio entirely - both the raw API field names and the
+		// "credential_id" rename performed in the non-profile branch below.
+		delete(environmentsTyped, "connection_id")
+		delete(environmentsTyped, "credentials_id")
+		delete(environmentsTyped, "credential_id")
+		delete(environmentsTyped, "extended_attributes_id")
+	} else {
+		// the credentials are not set for some environments
+		if environment.CredentialsID != nil {
+			environmentsTyped["credential_id"] = *environment.CredentialsID
+			if linkCredentials() {
+				environmentsTyped["credential_id"] = credentialReference(environment.Credentials, *environment.CredentialsID)
+			}
+		}
+		if environment.ConnectionID != nil && linkResource("dbtcloud_global_connection") {
+			environmentsTyped["connection_id"] = resourceReference("dbtcloud_global_connection", *environment.ConnectionID, "id")
+		}
+
+		// handle the case when extended_attributes_id is not set
+		if environment.ExtendedAttributesID != nil && linkResource("dbtcloud_extended_attributes") {
+			environmentsTyped["extended_attributes_id"] = resourceReference("dbtcloud_extended_attributes", *environment.ExtendedAttributesID, "extended_attributes_id")
+		}
+	}
+
+	return environmentsTyped
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/gosimple/slug"
+	"github.com/samber/lo"
+)
+
+// environmentVariable is a variable of the project projectID, the API
+// returns them grouped by project.
+type environmentVariable struct {
+	dbtcloud.EnvironmentVariable
+	projectID int
+}
+
+func init() {
+	registerResource(resource[environmentVariable]{
+		resourceType: "dbtcloud_environment_variable",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project", "dbtcloud_environment"},
+		fetch: func(ctx context.Context, _ *accountData) ([]environmentVariable, error) {
+			mapEnvVars, err := dbtCloudClient.GetEnvironmentVariables(ctx, listFilterProjects)
+
+			// maps are sorted so that the config is always generated in the same order
+			envVars := []environmentVariable{}
+			projectIDs := lo.Keys(mapEnvVars)
+			sort.Ints(projectIDs)
+			for _, projectID := range projectIDs {
+				// the variables are already sorted by name
+				for _, envVar := range mapEnvVars[projectID] {
+					envVars = append(envVars, environmentVariable{EnvironmentVariable: envVar, projectID: projectID})
+				}
+			}
+			return envVars, err
+		},
+		transform: transformEnvironmentVariable,
+		name: func(envVar environmentVariable) string {
+			return envVar.Name
+		},
+		identity: func(envVar environmentVariable) map[string]any {
+			return map[string]any{"project_id": envVar.projectID, "name": envVar.Name}
+		},
+		label: func(envVar environmentVariable) string {
+			return fmt.Sprintf("%d_%s", envVar.projectID, envVar.Name)
+		},
+		importID: func(envVar environmentVariable) string {
+			return fmt.Sprintf("%d:%s", envVar.projectID, envVar.Name)
+		},
+		projectID: func(envVar environmentVariable) int {
+			return envVar.projectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "name"},
+			label:    []string{"project_id", "name"},
+		},
+		url: func(envVar environmentVariable) string {
+			return deployURL("projects/%d/environments/", envVar.projectID)
+		},
+	})
+}
+
+// transformEnvironmentVariable returns the attributes of a
+// dbtcloud_environment_variable. The values of the secret variables are
+// replaced by Terraform variables and, when environments are linked, the
+// variable depends on the environments it has a value for.
+func transformEnvironmentVariable(envVar environmentVariable, data *accountData) map[string]any {
+	projectID := envVar.projectID
+	envVarName := envVar.Name
+
+	envDetails := map[string]any{}
+	envDetails["name"] = envVarName
+	envDetails["id"] = fmt.Sprintf("%d_%s", projectID, envVarName)
+	envDetails["project_id"] = projectID
+
+	if linkResource("dbtcloud_project") {
+		envDetails["project_id"] = resourceReference("dbtcloud_project", projectID, "id")
+	}
+
+	// we need to make int a map[string]any to work with the matching strategy
+	collectEnvValues := map[string]any{}
+
+	listEnvNames := []string{}
+	envNames := lo.Keys(envVar.Values)
+	sort.Strings(envNames)
+	for _, envName := range envNames {
+		envValue := envVar.Values[envName]
+
+		if envName != "project" {
+			listEnvNames = append(listEnvNames, envName)
+		}
+
+		if envValue != nil {
+			collectEnvValues[envName] = envValue.Value
+
+			targetURL := deployURL("projects/%d/environments/", projectID)
+			if strings.HasPrefix(envVarName, "DBT_ENV_SECRET_") {
+				varName := fmt.Sprintf("dbtcloud_environment_variable_%d_%s_%s", projectID, envVarName, slug.Make(envName))
+				AllTFVars = append(AllTFVars, tfVar{
+					varType:        "string",
+					varName:        varName,
+					varDescription: "The secret env var for " + envVarName + " in the environment " + envName + " in the project " + fmt.Sprintf("%d", projectID) + " - " + targetURL,
+				})
+				collectEnvValues[envName] = hclReference("var." + varName)
+			}
+		}
+	}
+
+	if linkResource("dbtcloud_environment") {
+		matchingEnvs := lo.Filter(data.environments, func(env dbtcloud.Environment, index int) bool {
+			return env.ProjectID == projectID && lo.Contains(listEnvNames, env.Name)
+		})
+
+		listDependsOn := []hclExpression{}
+		for _, matchingEnv := range matchingEnvs {
+			listDependsOn = append(listDependsOn, resourceReference("dbtcloud_environment", matchingEnv.ID, ""))
+		}
+		envDetails["depends_on"] = listDependsOn
+	}
+	envDetails["environment_values"] = collectEnvValues
+
+	return envDetails
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/gosimple/slug"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.EnvironmentVariableJobOverride]{
+		resourceType: "dbtcloud_environment_variable_job_override",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project", "dbtcloud_job"},
+		fetch: func(ctx context.Context, data *accountData) ([]dbtcloud.EnvironmentVariableJobOverride, error) {
+			return dbtCloudClient.GetEnvironmentVariableJobOverrides(ctx, listFilterProjects, data.jobs)
+		},
+		transform: func(override dbtcloud.EnvironmentVariableJobOverride, _ *accountData) map[string]any {
+			return transformEnvironmentVariableJobOverrideForGenerate(override)
+		},
+		// environment_variable_job_override_id is only meaningful together
+		// with the project/job scope it lives in
+		name: func(override dbtcloud.EnvironmentVariableJobOverride) string {
+			return override.Name
+		},
+		label: func(override dbtcloud.EnvironmentVariableJobOverride) string {
+			return fmt.Sprintf("%d_%d_%d", override.ProjectID, override.JobDefinitionID, override.ID)
+		},
+		importID: func(override dbtcloud.EnvironmentVariableJobOverride) string {
+			return fmt.Sprintf("%d:%d:%d", override.ProjectID, override.JobDefinitionID, override.ID)
+		},
+		projectID: func(override dbtcloud.EnvironmentVariableJobOverride) int {
+			return override.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "job_definition_id", "environment_variable_job_override_id"},
+			label:    []string{"project_id", "job_definition_id", "environment_variable_job_override_id"},
+		},
+		links: func(override dbtcloud.EnvironmentVariableJobOverride, _ *accountData) []resourceLink {
+			return []resourceLink{
+				linkTo("project_id", "dbtcloud_project", override.ProjectID),
+				linkTo("job_definition_id", "dbtcloud_job", override.JobDefinitionID),
+			}
+		},
+		inventory: func(override dbtcloud.EnvironmentVariableJobOverride, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{column("job_definition_id", override.JobDefinitionID)}
+		},
+		url: func(override dbtcloud.EnvironmentVariableJobOverride) string {
+			return deployURL("projects/%d/jobs/%d/settings/", override.ProjectID, override.JobDefinitionID)
+		},
+	})
+}
+
+// transformEnvironmentVariableJobOverrideForGenerate applies the
+// dbtcloud_environment_variable_job_override-specific generate-time
+// transforms to a single override payload (as returned by
+// GetEnvironmentVariableJobOverrides): it folds project_id/job_definition_id/
+// the override's own numeric id into "id" (mirroring transformProfileForGenerate's
+// approach, since environment_variable_job_override_id is only meaningful
+// together with the project/job scope it lives in), externalizes the value
+// of a secret-named override (DBT_ENV_SECRET_ prefix) to a Terraform
+// variable exactly as the dbtcloud_environment_variable resource does,
+// and optionally links job_definition_id/project_id to their generated
+// counterparts.
+//
+// It mutates and returns the raw payload of the override in place, matching
+// the mutate-in-place style used by every other resource type.
+func transformEnvironmentVariableJobOverrideForGenerate(override dbtcloud.EnvironmentVariableJobOverride) map[string]any {
+	overrideTyped := override.Raw
+	projectID := override.ProjectID
+	jobDefinitionID := override.JobDefinitionID
+	envVarName := override.Name
+
+	overrideTyped["id"] = fmt.Sprintf("%d_%d_%d", projectID, jobDefinitionID, override.ID)
+
+	// mirror the DBT_ENV_SECRET_ externalization pattern used by the
+	// dbtcloud_environment_variable resource exactly: a secret-named
+	// override's value is registered as a Terraform variable and
+	// substituted with a var.<name> reference instead of being emitted
+	// inline.
+	if strings.HasPrefix(envVarName, "DBT_ENV_SECRET_") {
+		targetURL := deployURL("projects/%d/jobs/%d/settings/", projectID, jobDefinitionID)
+		varName := fmt.Sprintf("dbtcloud_environment_variable_job_override_%d_%d_%s", projectID, jobDefinitionID, slug.Make(envVarName))
+		AllTFVars = append(AllTFVars, tfVar{
+			varType:        "string",
+			varName:        varName,
+			varDescription: "The secret env var override for " + envVarName + " on job " + fmt.Sprintf("%d", jobDefinitionID) + " in the project " + fmt.Sprintf("%d", projectID) + " - " + targetURL,
+		})
+		overrideTyped["raw_value"] = hclReference("var." + varName)
+	}
+
+	if linkResource("dbtcloud_job") {
+		overrideTyped["job_definition_id"] = resourceReference("dbtcloud_job", jobDefinitionID, "id")
+	}
+	if linkResource("dbtcloud_project") {
+		overrideTyped["project_id"] = resourceReference("dbtcloud_project", projectID, "id")
+	}
+
+	return overrideTyped
+}
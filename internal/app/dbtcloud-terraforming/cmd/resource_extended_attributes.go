@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.ExtendedAttributes]{
+		resourceType: "dbtcloud_extended_attributes",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project"},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.ExtendedAttributes, error) {
+			return dbtCloudClient.GetExtendedAttributes(ctx, listFilterProjects)
+		},
+		transform: func(extendedAttributes dbtcloud.ExtendedAttributes, _ *accountData) map[string]any {
+			extendedAttributesTyped := extendedAttributes.Raw
+
+			extendedAttributesTyped["extended_attributes"] = extendedAttributesValue(extendedAttributes)
+
+			extendedAttributesTyped["state"] = ""
+
+			if linkResource("dbtcloud_project") {
+				extendedAttributesTyped["project_id"] = resourceReference("dbtcloud_project", extendedAttributes.ProjectID, "id")
+			}
+			return extendedAttributesTyped
+		},
+		label: func(extendedAttributes dbtcloud.ExtendedAttributes) string {
+			return fmt.Sprintf("%d", extendedAttributes.ID)
+		},
+		importID: func(extendedAttributes dbtcloud.ExtendedAttributes) string {
+			return fmt.Sprintf("%d:%d", extendedAttributes.ProjectID, extendedAttributes.ID)
+		},
+		projectID: func(extendedAttributes dbtcloud.ExtendedAttributes) int {
+			return extendedAttributes.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "extended_attributes_id"},
+			label:    []string{"extended_attributes_id"},
+		},
+	})
+}
+
+// extendedAttributesValue returns the extended attributes written as HCL in
+// jsonencode. The extended attributes that can't be converted are recorded
+// as errors of the run and written as a JSON string.
+func extendedAttributesValue(extendedAttributes dbtcloud.ExtendedAttributes) any {
+	marshalledExtendedAttributes, err := json.Marshal(extendedAttributes.ExtendedAttributes)
+	if err != nil {
+		recordError("dbtcloud_extended_attributes", fmt.Errorf("extended attributes %d: %w", extendedAttributes.ID, err))
+		return nil
+	}
+	jsonValue, err := hclJSONEncode(marshalledExtendedAttributes)
+	if err != nil {
+		recordError("dbtcloud_extended_attributes", fmt.Errorf("extended attributes %d: %w", extendedAttributes.ID, err))
+		return string(marshalledExtendedAttributes)
+	}
+	return jsonValue
+}
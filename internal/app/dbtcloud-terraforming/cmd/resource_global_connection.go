@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.GlobalConnection]{
+		resourceType: "dbtcloud_global_connection",
+		scope:        "Account",
+		// the summary of the connections doesn't have their config
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.GlobalConnection, error) {
+			return dbtCloudClient.GetGlobalConnections(ctx)
+		},
+		transform: transformGlobalConnection,
+		name: func(connection dbtcloud.GlobalConnection) string {
+			return connection.Name
+		},
+		identity: func(connection dbtcloud.GlobalConnection) map[string]any {
+			return map[string]any{"name": connection.Name}
+		},
+		label: func(connection dbtcloud.GlobalConnection) string {
+			return fmt.Sprintf("%d", connection.ID)
+		},
+		importID: func(connection dbtcloud.GlobalConnection) string {
+			return fmt.Sprintf("%d", connection.ID)
+		},
+		inventory: func(connection dbtcloud.GlobalConnection, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{column("adapter_version", connection.AdapterVersion)}
+		},
+		url: func(connection dbtcloud.GlobalConnection) string {
+			return settingsURL("connections/%d/", connection.ID)
+		},
+	})
+}
+
+// transformGlobalConnection returns the attributes of a
+// dbtcloud_global_connection, with its config in the block of its adapter
+// and the secret fields replaced by Terraform variables.
+func transformGlobalConnection(connection dbtcloud.GlobalConnection, _ *accountData) map[string]any {
+	connectionTyped := connection.Raw
+
+	configSection := getAdapterFromAdapterVersion(connection.AdapterVersion)
+
+	// the config is shared with the raw payload, so that the
+	// changes below are reflected in the generated config
+	configTyped := connection.Config
+	if configTyped == nil {
+		log.Warnf("the global connection %d has no config", connection.ID)
+		configTyped = map[string]any{}
+	}
+	delete(configTyped, "adapter_id")
+	targetURL := settingsURL("connections/%d/", connection.ID)
+
+	// handle the fields that don't come back from the API
+	if _, exists := configTyped["oauth_client_id"]; exists {
+		varName := fmt.Sprintf("dbtcloud_global_connection_oauth_client_id_%d", connection.ID)
+		AllTFVars = append(AllTFVars, tfVar{
+			varType:        "string",
+			varName:        varName,
+			varDescription: "The OAuth client ID for the global connection " + fmt.Sprintf("%d", connection.ID) + " - " + targetURL,
+		})
+		configTyped["oauth_client_id"] = hclReference("var." + varName)
+	}
+	if _, exists := configTyped["oauth_client_secret"]; exists {
+		varName := fmt.Sprintf("dbtcloud_global_connection_oauth_client_secret_%d", connection.ID)
+		AllTFVars = append(AllTFVars, tfVar{
+			varType:        "string",
+			varName:        varName,
+			varDescription: "The OAuth client secret for the global connection " + fmt.Sprintf("%d", connection.ID) + " - " + targetURL,
+		})
+		configTyped["oauth_client_secret"] = hclReference("var." + varName)
+	}
+	if _, exists := configTyped["private_key"]; exists {
+		varName := fmt.Sprintf("dbtcloud_global_connection_private_key_%d", connection.ID)
+		AllTFVars = append(AllTFVars, tfVar{
+			varType:        "string",
+			varName:        varName,
+			varDescription: "The private key for the global connection " + fmt.Sprintf("%d", connection.ID) + " - " + targetURL,
+		})
+		configTyped["private_key"] = hclReference("var." + varName)
+	}
+	if _, exists := configTyped["application_id"]; exists {
+		varName := fmt.Sprintf("dbtcloud_global_connection_application_id_%d", connection.ID)
+		AllTFVars = append(AllTFVars, tfVar{
+			varType:        "string",
+			varName:        varName,
+			varDescription: "The application ID for the global connection " + fmt.Sprintf("%d", connection.ID) + " - " + targetURL,
+		})
+		configTyped["application_id"] = hclReference("var." + varName)
+	}
+	if _, exists := configTyped["application_secret"]; exists {
+		varName := fmt.Sprintf("dbtcloud_global_connection_application_secret_%d", connection.ID)
+		AllTFVars = append(AllTFVars, tfVar{
+			varType:        "string",
+			varName:        varName,
+			varDescription: "The application secret for the global connection " + fmt.Sprintf("%d", connection.ID) + " - " + targetURL,
+		})
+		configTyped["application_secret"] = hclReference("var." + varName)
+	}
+	// For BQ, to handle the renaming of the fields
+	if gcpProjectID, exists := configTyped["project_id"]; exists && configSection == "bigquery" {
+		configTyped["gcp_project_id"] = gcpProjectID
+		delete(configTyped, "project_id")
+	}
+
+	if connectionTyped["private_link_endpoint_id"] != nil {
+		connectionTyped["private_link_endpoint_id"] = hclReference(fmt.Sprintf("var.dbtcloud_global_connection_private_link_endpoint_id_%d", connection.ID))
+		varName := fmt.Sprintf("dbtcloud_global_connection_private_link_endpoint_id_%d", connection.ID)
+		allVarNames := lo.Map(AllTFVars, func(i tfVar, _ int) string { return i.varName })
+		if !lo.Contains(allVarNames, varName) {
+			AllTFVars = append(AllTFVars, tfVar{
+				varType:        "string",
+				varName:        varName,
+				varDescription: "The private link endpoint ID for the global connection " + fmt.Sprintf("%d", connection.ID) + " - " + targetURL,
+			})
+		}
+	}
+
+	connectionTyped[configSection] = configTyped
+	return connectionTyped
+}
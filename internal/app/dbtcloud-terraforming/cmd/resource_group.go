@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+// defaultGroups are the built-in dbt platform groups that are never generated
+// as dbtcloud_group resources (their membership is managed by dbt platform
+// itself, not Terraform). Any other resource that references groups (e.g.
+// dbtcloud_user_groups) must exclude these from its output to avoid dangling
+// references to resources that are never created.
+var defaultGroups = []string{"Owner", "Member", "Everyone"}
+
+func init() {
+	registerResource(resource[dbtcloud.Group]{
+		resourceType: "dbtcloud_group",
+		scope:        "Account",
+		dependencies: []string{"dbtcloud_project"},
+		// the payload of the groups is modified by the transform, so we
+		// don't use the prefetched groups
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Group, error) {
+			groups, err := dbtCloudClient.GetGroups(ctx)
+			// remove the default groups
+			return lo.Filter(groups, func(group dbtcloud.Group, _ int) bool {
+				return !lo.Contains(defaultGroups, group.Name)
+			}), err
+		},
+		// the inventory lists the default groups as well
+		list: func(ctx context.Context, _ *accountData) ([]dbtcloud.Group, error) {
+			return dbtCloudClient.GetGroups(ctx)
+		},
+		transform: func(group dbtcloud.Group, data *accountData) map[string]any {
+			groupTyped := group.Raw
+
+			if linkResource("dbtcloud_project") {
+				projectIDs := data.projectIDs()
+
+				newGroupPermissionsTyped := []map[string]any{}
+				for _, groupPermission := range group.GroupPermissions {
+					if !groupPermission.AllProjects && groupPermission.ProjectID != nil && lo.Contains(projectIDs, *groupPermission.ProjectID) {
+						groupPermissionTyped := groupPermission.Raw
+						groupPermissionTyped["project_id"] = resourceReference("dbtcloud_project", *groupPermission.ProjectID, "id")
+						newGroupPermissionsTyped = append(newGroupPermissionsTyped, groupPermissionTyped)
+					}
+				}
+				groupTyped["group_permissions"] = newGroupPermissionsTyped
+			}
+			return groupTyped
+		},
+		name: func(group dbtcloud.Group) string {
+			return group.Name
+		},
+		identity: func(group dbtcloud.Group) map[string]any {
+			return map[string]any{"name": group.Name}
+		},
+		label: func(group dbtcloud.Group) string {
+			return fmt.Sprintf("%d", group.ID)
+		},
+		importID: func(group dbtcloud.Group) string {
+			return fmt.Sprintf("%d", group.ID)
+		},
+		inventory: func(group dbtcloud.Group, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{
+				column("assign_by_default", group.AssignByDefault),
+				column("sso_mapping_groups", group.SSOMappingGroups),
+				column("permissions", permissionSets(group.GroupPermissions)),
+			}
+		},
+		url: func(group dbtcloud.Group) string {
+			return settingsURL("groups/%d/", group.ID)
+		},
+	})
+}
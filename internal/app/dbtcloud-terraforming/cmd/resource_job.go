@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Job]{
+		resourceType: "dbtcloud_job",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project", "dbtcloud_environment"},
+		// the payload of the jobs is modified by transformJob, so we don't use
+		// the prefetched jobs
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Job, error) {
+			return dbtCloudClient.GetJobs(ctx, listFilterProjects)
+		},
+		transform: func(job dbtcloud.Job, _ *accountData) map[string]any {
+			return transformJob(job)
+		},
+		name: func(job dbtcloud.Job) string {
+			return job.Name
+		},
+		identity: func(job dbtcloud.Job) map[string]any {
+			return map[string]any{"environment_id": job.EnvironmentID, "name": job.Name}
+		},
+		label: func(job dbtcloud.Job) string {
+			return fmt.Sprintf("%d", job.ID)
+		},
+		importID: func(job dbtcloud.Job) string {
+			return fmt.Sprintf("%d", job.ID)
+		},
+		projectID: func(job dbtcloud.Job) int {
+			return job.ProjectID
+		},
+		links: func(job dbtcloud.Job, _ *accountData) []resourceLink {
+			links := []resourceLink{
+				linkTo("project_id", "dbtcloud_project", job.ProjectID),
+				linkTo("environment_id", "dbtcloud_environment", job.EnvironmentID),
+			}
+			if job.DeferringEnvironmentID != nil {
+				links = append(links, linkTo("deferring_environment_id", "dbtcloud_environment", *job.DeferringEnvironmentID))
+			}
+			if job.JobCompletionTriggerCondition != nil && job.JobCompletionTriggerCondition.Condition != nil {
+				links = append(links, linkTo("job_completion_trigger_condition", "dbtcloud_job", job.JobCompletionTriggerCondition.Condition.JobID))
+			}
+			return links
+		},
+		inventory: func(job dbtcloud.Job, _ *accountData) []inventoryColumn {
+			return append([]inventoryColumn{
+				column("environment_id", job.EnvironmentID),
+				column("deferring_environment_id", job.DeferringEnvironmentID),
+				column("job_type", job.JobType),
+			}, jobSchedule(job)...)
+		},
+		url: func(job dbtcloud.Job) string {
+			return deployURL("projects/%d/jobs/%d/settings/", job.ProjectID, job.ID)
+		},
+	})
+}
+
+// transformJob returns the attributes of a dbtcloud_job: the schedule, the
+// triggers and the completion trigger condition are reshaped from the API
+// payload to the shape of the resource.
+func transformJob(job dbtcloud.Job) map[string]any {
+	jobTyped := job.Raw
+
+	jobTyped["num_threads"] = job.Settings.Threads
+	jobTyped["target_name"] = job.Settings.TargetName
+	jobTyped["timeout_seconds"] = job.Execution.TimeoutSeconds
+
+	jobScheduleDate := job.Schedule.Date
+	jobTyped["schedule_type"] = jobScheduleDate.Type
+
+	if jobScheduleDate.Type == "custom_cron" {
+		jobTyped["schedule_cron"] = jobScheduleDate.Cron
+	}
+	if jobScheduleDate.Type == "interval_cron" {
+		jobTyped["schedule_type"] = "custom_cron"
+		jobTyped["schedule_cron"] = jobScheduleDate.Cron
+	}
+	if jobScheduleDate.Type == "days_of_week" {
+		// an empty list would be written as an empty attribute
+		if len(jobScheduleDate.Days) > 0 {
+			jobTyped["schedule_days"] = jobScheduleDate.Days
+		}
+
+		if job.Schedule.Time.Type == "at_exact_hours" && len(job.Schedule.Time.Hours) > 0 {
+			jobTyped["schedule_hours"] = job.Schedule.Time.Hours
+		}
+
+		// TODO: Handle the case when this is every x hours
+	}
+
+	jobTriggers := job.Triggers
+
+	// we allow deactivating jobs based on a local variable
+	var triggers map[string]any
+	if parameterizeJobs {
+		triggers = map[string]any{
+			"github_webhook":       hclRaw(fmt.Sprintf("local.deactivate_jobs_pr ? false : %t", jobTriggers.GithubWebhook)),
+			"git_provider_webhook": hclRaw(fmt.Sprintf("local.deactivate_jobs_pr ? false : %t", jobTriggers.GitProviderWebhook)),
+			"schedule":             hclRaw(fmt.Sprintf("local.deactivate_jobs_schedule ? false : %t", jobTriggers.Schedule)),
+			"on_merge":             hclRaw(fmt.Sprintf("local.deactivate_jobs_merge ? false : %t", jobTriggers.OnMerge)),
+		}
+	} else {
+		triggers = map[string]any{
+			"github_webhook":       jobTriggers.GithubWebhook,
+			"git_provider_webhook": jobTriggers.GitProviderWebhook,
+			"schedule":             jobTriggers.Schedule,
+			"on_merge":             jobTriggers.OnMerge,
+		}
+	}
+
+	jobTyped["triggers"] = triggers
+
+	if linkResource("dbtcloud_environment") {
+		jobTyped["environment_id"] = resourceReference("dbtcloud_environment", job.EnvironmentID, "environment_id")
+
+		// handle the case when deferring_environment_id is not set
+		if job.DeferringEnvironmentID != nil {
+			jobTyped["deferring_environment_id"] = resourceReference("dbtcloud_environment", *job.DeferringEnvironmentID, "environment_id")
+		}
+	}
+	if linkResource("dbtcloud_project") {
+		jobTyped["project_id"] = resourceReference("dbtcloud_project", job.ProjectID, "id")
+	}
+
+	if job.JobCompletionTriggerCondition != nil && job.JobCompletionTriggerCondition.Condition != nil {
+		jobCompletionTriggerCondition := job.JobCompletionTriggerCondition.Condition
+
+		projectID := jobCompletionTriggerCondition.ProjectID
+		jobID := jobCompletionTriggerCondition.JobID
+
+		completionTriggers := map[string]any{
+			"job_id":     jobID,
+			"project_id": projectID,
+			"statuses":   mapJobStatusCodeToText(jobCompletionTriggerCondition.Statuses),
+		}
+
+		if linkResource("dbtcloud_job") {
+			completionTriggers["job_id"] = resourceReference("dbtcloud_job", jobID, "id")
+		}
+
+		if linkResource("dbtcloud_project") {
+			completionTriggers["project_id"] = resourceReference("dbtcloud_project", projectID, "id")
+		}
+
+		jobTyped["job_completion_trigger_condition"] = completionTriggers
+	}
+
+	return jobTyped
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+func init() {
+	// dbtcloud_job_completion_trigger reproduces, as its own standalone
+	// resource, the "run after" relationship that already rides along on a
+	// job's own payload under job_completion_trigger_condition. Its import id
+	// is just the downstream job's plain numeric id, matching the provider's
+	// ImportState which parses the import id directly as job_id.
+	registerResource(resource[dbtcloud.Job]{
+		resourceType: "dbtcloud_job_completion_trigger",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project", "dbtcloud_job"},
+		// the prefetched jobs are not modified, the attributes are built
+		// from scratch by transformJobCompletionTriggerForGenerate
+		fetch: func(_ context.Context, data *accountData) ([]dbtcloud.Job, error) {
+			return lo.Filter(data.jobs, func(job dbtcloud.Job, _ int) bool {
+				_, ok := transformJobCompletionTriggerForGenerate(job)
+				return ok
+			}), nil
+		},
+		transform: func(job dbtcloud.Job, _ *accountData) map[string]any {
+			triggerData, _ := transformJobCompletionTriggerForGenerate(job)
+			return triggerData
+		},
+		name: func(job dbtcloud.Job) string {
+			return job.Name
+		},
+		label: func(job dbtcloud.Job) string {
+			return fmt.Sprintf("%d", job.ID)
+		},
+		importID: func(job dbtcloud.Job) string {
+			return fmt.Sprintf("%d", job.ID)
+		},
+		projectID: func(job dbtcloud.Job) int {
+			return job.ProjectID
+		},
+		links: func(job dbtcloud.Job, _ *accountData) []resourceLink {
+			return []resourceLink{
+				linkTo("job_id", "dbtcloud_job", job.ID),
+				linkTo("trigger_job_id", "dbtcloud_job", job.JobCompletionTriggerCondition.Condition.JobID),
+			}
+		},
+		inventory: func(job dbtcloud.Job, _ *accountData) []inventoryColumn {
+			condition := job.JobCompletionTriggerCondition.Condition
+			return []inventoryColumn{
+				column("trigger_job_id", condition.JobID),
+				column("statuses", mapJobStatusCodeToText(condition.Statuses)),
+			}
+		},
+		url: func(job dbtcloud.Job) string {
+			return deployURL("projects/%d/jobs/%d/settings/", job.ProjectID, job.ID)
+		},
+	})
+}
+
+// transformJobCompletionTriggerForGenerate builds the jsonStructData entry
+// for a single dbtcloud_job_completion_trigger, from the
+// job_completion_trigger_condition field already present on a dbtcloud_job
+// payload (see transformJob, which parses this same field for its own,
+// separate purposes).
+//
+// It returns (nil, false) when the job carries no completion trigger
+// condition at all, those jobs are not returned by the Fetch of the
+// resource.
+//
+// The real dbtcloud_job_completion_trigger resource schema is flat - job_id
+// (the downstream job this trigger is attached to), trigger_job_id (the
+// upstream job whose completion fires it), project_id, and statuses - with
+// no nested "condition" block, confirmed against the provider's schema
+// source. Its id is just the downstream job's plain numeric id (matching the
+// provider's ImportState, which parses the import id directly as job_id),
+// so no composite-id folding is needed here, unlike
+// transformProfileForGenerate.
+func transformJobCompletionTriggerForGenerate(job dbtcloud.Job) (map[string]any, bool) {
+	if job.JobCompletionTriggerCondition == nil || job.JobCompletionTriggerCondition.Condition == nil {
+		return nil, false
+	}
+	condition := job.JobCompletionTriggerCondition.Condition
+
+	triggerData := map[string]any{
+		"id":             job.ID,
+		"job_id":         job.ID,
+		"trigger_job_id": condition.JobID,
+		"project_id":     condition.ProjectID,
+		"statuses":       mapJobStatusCodeToText(condition.Statuses),
+	}
+
+	if linkResource("dbtcloud_job") {
+		triggerData["job_id"] = resourceReference("dbtcloud_job", job.ID, "id")
+		triggerData["trigger_job_id"] = resourceReference("dbtcloud_job", condition.JobID, "id")
+	}
+	if linkResource("dbtcloud_project") {
+		triggerData["project_id"] = resourceReference("dbtcloud_project", condition.ProjectID, "id")
+	}
+
+	return triggerData, true
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/gosimple/slug"
+	"github.com/samber/lo"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Notification]{
+		resourceType: "dbtcloud_notification",
+		scope:        "Account",
+		dependencies: []string{"dbtcloud_job", "dbtcloud_user"},
+		fetch: func(ctx context.Context, data *accountData) ([]dbtcloud.Notification, error) {
+			notifications, err := dbtCloudClient.GetNotifications(ctx)
+			userEmails := data.userEmails()
+			return lo.Filter(notifications, func(notification dbtcloud.Notification, _ int) bool {
+				if notification.Type == dbtcloud.NotificationTypeExternalEmail && notification.ExternalEmail == nil {
+					// for some reason there are external notifications without an email
+					return false
+				}
+				if _, ok := userEmails[notification.UserID]; !ok && linkResource("users_by_email") {
+					log.Warnf("User %d not found", notification.UserID)
+					return false
+				}
+				return true
+			}), err
+		},
+		// the inventory lists all the notifications, even the ones that can't be generated
+		list: func(ctx context.Context, _ *accountData) ([]dbtcloud.Notification, error) {
+			return dbtCloudClient.GetNotifications(ctx)
+		},
+		transform: func(notification dbtcloud.Notification, data *accountData) map[string]any {
+			notificationTyped := notification.Raw
+
+			notificationTyped["notification_type"] = notification.Type
+			notificationTyped["state"] = nil
+
+			if linkResource("dbtcloud_job") {
+				listOns := map[string][]int{
+					"on_cancel":  notification.OnCancel,
+					"on_failure": notification.OnFailure,
+					"on_success": notification.OnSuccess,
+					"on_warning": notification.OnWarning,
+				}
+
+				jobIDs := data.jobIDs()
+				for notifHook, notifJobIDs := range listOns {
+
+					// we remove jobs that are not relevant to the current project or that have been deleted
+					filteredJobIDs := lo.Filter(notifJobIDs, func(jobID int, _ int) bool {
+						return lo.Contains(jobIDs, jobID)
+					})
+					linkedJobIDs := lo.Map(filteredJobIDs, func(jobID int, index int) hclExpression {
+						return resourceReference("dbtcloud_job", jobID, "id")
+					})
+					notificationTyped[notifHook] = linkedJobIDs
+				}
+			}
+
+			if linkResource("users_by_email") {
+				userEmail := data.userEmails()[notification.UserID]
+				notificationTyped["user_id"] = hclReference(fmt.Sprintf("local.id_%s", slug.Make(userEmail)))
+				notificationTyped["count"] = hclReference(fmt.Sprintf("local.count_%s", slug.Make(userEmail)))
+
+				AllLocals[fmt.Sprintf("details_%s", slug.Make(userEmail))] = hclRaw(fmt.Sprintf(`[for user in data.dbtcloud_users.all.users : user if user.email == "%s"]`, userEmail))
+				AllLocals[fmt.Sprintf("count_%s", slug.Make(userEmail))] = hclRaw(fmt.Sprintf("length(local.%s)", fmt.Sprintf("details_%s", slug.Make(userEmail))))
+				AllLocals[fmt.Sprintf("id_%s", slug.Make(userEmail))] = hclRaw(fmt.Sprintf("local.count_%s == 1 ? local.details_%s[0].id : 0", slug.Make(userEmail), slug.Make(userEmail)))
+			}
+			return notificationTyped
+		},
+		name: func(notification dbtcloud.Notification) string {
+			return lo.FromPtr(notification.ExternalEmail)
+		},
+		label: func(notification dbtcloud.Notification) string {
+			return fmt.Sprintf("%d", notification.ID)
+		},
+		importID: func(notification dbtcloud.Notification) string {
+			return fmt.Sprintf("%d", notification.ID)
+		},
+		links: func(notification dbtcloud.Notification, _ *accountData) []resourceLink {
+			links := []resourceLink{}
+			for _, on := range []struct {
+				attribute string
+				jobIDs    []int
+			}{
+				{"on_cancel", notification.OnCancel},
+				{"on_failure", notification.OnFailure},
+				{"on_success", notification.OnSuccess},
+				{"on_warning", notification.OnWarning},
+			} {
+				for _, jobID := range on.jobIDs {
+					links = append(links, linkTo(on.attribute, "dbtcloud_job", jobID))
+				}
+			}
+			return links
+		},
+		inventory: func(notification dbtcloud.Notification, data *accountData) []inventoryColumn {
+			email := data.userEmails()[notification.UserID]
+			if notification.ExternalEmail != nil {
+				email = *notification.ExternalEmail
+			}
+			return []inventoryColumn{
+				column("email", email),
+				column("on_cancel", notification.OnCancel),
+				column("on_failure", notification.OnFailure),
+				column("on_success", notification.OnSuccess),
+				column("on_warning", notification.OnWarning),
+			}
+		},
+	})
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Profile]{
+		resourceType: "dbtcloud_profile",
+		scope:        "Project",
+		dependencies: []string{
+			"dbtcloud_project", "dbtcloud_global_connection", "dbtcloud_extended_attributes",
+			"dbtcloud_snowflake_credential", "dbtcloud_bigquery_credential", "dbtcloud_databricks_credential",
+		},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Profile, error) {
+			return dbtCloudClient.GetProfiles(ctx, listFilterProjects)
+		},
+		transform: func(profile dbtcloud.Profile, _ *accountData) map[string]any {
+			return transformProfileForGenerate(profile)
+		},
+		// profile_id is only unique within a project, not across the
+		// account, so a plain numeric label (as used by e.g.
+		// dbtcloud_environment) could collide across projects
+		name: func(profile dbtcloud.Profile) string {
+			return profile.Key
+		},
+		identity: func(profile dbtcloud.Profile) map[string]any {
+			return map[string]any{"project_id": profile.ProjectID, "key": profile.Key}
+		},
+		label: func(profile dbtcloud.Profile) string {
+			return fmt.Sprintf("%d_%d", profile.ProjectID, profile.ID)
+		},
+		importID: func(profile dbtcloud.Profile) string {
+			return fmt.Sprintf("%d:%d", profile.ProjectID, profile.ID)
+		},
+		projectID: func(profile dbtcloud.Profile) int {
+			return profile.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "profile_id"},
+			label:    []string{"project_id", "profile_id"},
+		},
+		links: func(profile dbtcloud.Profile, _ *accountData) []resourceLink {
+			links := []resourceLink{linkTo("project_id", "dbtcloud_project", profile.ProjectID)}
+			if profile.ConnectionID != nil {
+				links = append(links, linkTo("connection_id", "dbtcloud_global_connection", *profile.ConnectionID))
+			}
+			if credentialType := credentialResourceType(profile.Credentials); profile.CredentialsID != nil && credentialType != "" {
+				links = append(links, linkTo("credentials_id", credentialType, *profile.CredentialsID))
+			}
+			if profile.ExtendedAttributesID != nil {
+				links = append(links, linkTo("extended_attributes_id", "dbtcloud_extended_attributes", *profile.ExtendedAttributesID))
+			}
+			return links
+		},
+		inventory: func(profile dbtcloud.Profile, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{
+				column("connection_id", profile.ConnectionID),
+				column("credentials_id", profile.CredentialsID),
+				column("extended_attributes_id", profile.ExtendedAttributesID),
+			}
+		},
+	})
+}
+
+// transformProfileForGenerate applies the dbtcloud_profile-specific
+// generate-time transforms to a single profile payload: it folds project_id
+// into the "id" field (profile_id is only unique within a project, not
+// across the account, see the label of the resource), keeps the plain
+// numeric profile id
+// separately as "profile_id" for the resource's own attribute, and
+// optionally links project_id/connection_id/credentials_id/
+// extended_attributes_id to their generated counterparts, mirroring
+// transformEnvironmentForGenerate's linking logic exactly.
+//
+// It mutates and returns the raw payload of the profile in place, matching
+// the mutate-in-place style used by every other resource type.
+func transformProfileForGenerate(profile dbtcloud.Profile) map[string]any {
+	profileTyped := profile.Raw
+
+	profileTyped["profile_id"] = profile.ID
+	profileTyped["id"] = fmt.Sprintf("%d_%d", profile.ProjectID, profile.ID)
+
+	if linkResource("dbtcloud_project") {
+		profileTyped["project_id"] = resourceReference("dbtcloud_project", profile.ProjectID, "id")
+	}
+
+	if profile.ConnectionID != nil && linkResource("dbtcloud_global_connection") {
+		profileTyped["connection_id"] = resourceReference("dbtcloud_global_connection", *profile.ConnectionID, "id")
+	}
+
+	// the credentials are not set for some profiles
+	if profile.CredentialsID != nil && linkCredentials() {
+		profileTyped["credentials_id"] = credentialReference(profile.Credentials, *profile.CredentialsID)
+	}
+
+	// handle the case when extended_attributes_id is not set
+	if profile.ExtendedAttributesID != nil && linkResource("dbtcloud_extended_attributes") {
+		profileTyped["extended_attributes_id"] = resourceReference("dbtcloud_extended_attributes", *profile.ExtendedAttributesID, "extended_attributes_id")
+	}
+
+	return profileTyped
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Project]{
+		resourceType: "dbtcloud_project",
+		scope:        "Project",
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Project, error) {
+			return dbtCloudClient.GetProjects(ctx, listFilterProjects)
+		},
+		transform: func(project dbtcloud.Project, _ *accountData) map[string]any {
+			return project.Raw
+		},
+		name: func(project dbtcloud.Project) string {
+			return project.Name
+		},
+		identity: func(project dbtcloud.Project) map[string]any {
+			return map[string]any{"name": project.Name}
+		},
+		label: func(project dbtcloud.Project) string {
+			return fmt.Sprintf("%d", project.ID)
+		},
+		importID: func(project dbtcloud.Project) string {
+			return fmt.Sprintf("%d", project.ID)
+		},
+		projectID: func(project dbtcloud.Project) int {
+			return project.ID
+		},
+		inventory: func(project dbtcloud.Project, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{
+				column("description", project.Description),
+				column("repository_id", project.RepositoryID),
+			}
+		},
+		url: func(project dbtcloud.Project) string {
+			return settingsURL("projects/%d/", project.ID)
+		},
+	})
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Project]{
+		resourceType: "dbtcloud_project_repository",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project", "dbtcloud_repository"},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Project, error) {
+			projects, err := dbtCloudClient.GetProjects(ctx, listFilterProjects)
+			// only the projects with a repository have a project repository
+			return lo.Filter(projects, func(project dbtcloud.Project, _ int) bool {
+				return project.RepositoryID != nil
+			}), err
+		},
+		transform: func(project dbtcloud.Project, _ *accountData) map[string]any {
+			projectTyped := project.Raw
+			projectTyped["project_id"] = project.ID
+
+			if linkResource("dbtcloud_project") {
+				projectTyped["project_id"] = resourceReference("dbtcloud_project", project.ID, "id")
+			}
+			if linkResource("dbtcloud_repository") {
+				projectTyped["repository_id"] = resourceReference("dbtcloud_repository", *project.RepositoryID, "repository_id")
+			}
+			return projectTyped
+		},
+		name: func(project dbtcloud.Project) string {
+			return project.Name
+		},
+		label: func(project dbtcloud.Project) string {
+			return fmt.Sprintf("%d", project.ID)
+		},
+		importID: func(project dbtcloud.Project) string {
+			return fmt.Sprintf("%d:%d", project.ID, *project.RepositoryID)
+		},
+		projectID: func(project dbtcloud.Project) int {
+			return project.ID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "repository_id"},
+			label:    []string{"project_id"},
+		},
+		links: func(project dbtcloud.Project, _ *accountData) []resourceLink {
+			return []resourceLink{
+				linkTo("project_id", "dbtcloud_project", project.ID),
+				linkTo("repository_id", "dbtcloud_repository", *project.RepositoryID),
+			}
+		},
+	})
+}
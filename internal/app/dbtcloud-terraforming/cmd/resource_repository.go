@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Repository]{
+		resourceType: "dbtcloud_repository",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project"},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Repository, error) {
+			return dbtCloudClient.GetRepositories(ctx, listFilterProjects)
+		},
+		transform: func(repository dbtcloud.Repository, _ *accountData) map[string]any {
+			repositoryTyped := repository.Raw
+
+			if repository.GithubInstallationID != nil {
+				githubInstallationID := *repository.GithubInstallationID
+
+				varName := fmt.Sprintf("dbtcloud_repository_github_installation_id_%d", githubInstallationID)
+				// we only add the variable if it doesn't already exist
+				allVarNames := lo.Map(AllTFVars, func(i tfVar, _ int) string { return i.varName })
+				if !lo.Contains(allVarNames, varName) {
+					AllTFVars = append(AllTFVars, tfVar{
+						varType:        "number",
+						varName:        varName,
+						varDescription: "The new GitHub installation ID for the existing installation ID " + fmt.Sprintf("%d", githubInstallationID),
+					})
+				}
+				repositoryTyped["github_installation_id"] = hclReference("var." + varName)
+			}
+			if linkResource("dbtcloud_project") {
+				repositoryTyped["project_id"] = resourceReference("dbtcloud_project", repository.ProjectID, "id")
+			}
+			return repositoryTyped
+		},
+		name: func(repository dbtcloud.Repository) string {
+			return repositoryName(repository.RemoteURL)
+		},
+		identity: func(repository dbtcloud.Repository) map[string]any {
+			return map[string]any{"project_id": repository.ProjectID, "remote_url": repository.RemoteURL}
+		},
+		label: func(repository dbtcloud.Repository) string {
+			return fmt.Sprintf("%d", repository.ID)
+		},
+		importID: func(repository dbtcloud.Repository) string {
+			return fmt.Sprintf("%d:%d", repository.ProjectID, repository.ID)
+		},
+		projectID: func(repository dbtcloud.Repository) int {
+			return repository.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "repository_id"},
+			label:    []string{"repository_id"},
+		},
+		inventory: func(repository dbtcloud.Repository, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{
+				column("remote_url", repository.RemoteURL),
+				column("git_clone_strategy", repository.GitCloneStrategy),
+			}
+		},
+	})
+}
+
+// repositoryName returns the name of the repository of a remote URL, e.g.
+// jaffle_shop for git@github.com:dbt-labs/jaffle_shop.git
+func repositoryName(remoteURL string) string {
+	name := strings.TrimSuffix(strings.TrimRight(remoteURL, "/"), ".git")
+	return name[strings.LastIndexAny(name, "/:")+1:]
+}
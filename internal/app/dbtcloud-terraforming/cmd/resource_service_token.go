@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+// serviceToken is a service token with its permissions, which are fetched
+// separately.
+type serviceToken struct {
+	dbtcloud.ServiceToken
+	permissions []dbtcloud.Permission
+}
+
+func init() {
+	registerResource(resource[serviceToken]{
+		resourceType: "dbtcloud_service_token",
+		scope:        "Account",
+		dependencies: []string{"dbtcloud_project"},
+		fetch: func(ctx context.Context, _ *accountData) ([]serviceToken, error) {
+			listServiceTokens, err := dbtCloudClient.GetServiceTokens(ctx)
+			if err != nil {
+				return nil, err
+			}
+			serviceTokenIDs := lo.Map(listServiceTokens, func(serviceToken dbtcloud.ServiceToken, _ int) int {
+				return serviceToken.ID
+			})
+			listPermissions, err := dbtCloudClient.GetServiceTokensPermissions(ctx, serviceTokenIDs)
+
+			serviceTokens := []serviceToken{}
+			for i, token := range listServiceTokens {
+				serviceTokens = append(serviceTokens, serviceToken{ServiceToken: token, permissions: listPermissions[i]})
+			}
+			return serviceTokens, err
+		},
+		transform: func(token serviceToken, data *accountData) map[string]any {
+			serviceTokenTyped := token.Raw
+			serviceTokenTyped["uid"] = nil
+
+			permissions := dbtcloud.RawPayloads(token.permissions)
+
+			if linkResource("dbtcloud_project") {
+				projectIDs := data.projectIDs()
+				permissionsFilteredProjects := []any{}
+				for _, permissionsSet := range token.permissions {
+					if permissionsSet.ProjectID != nil && lo.Contains(projectIDs, *permissionsSet.ProjectID) {
+						permissionsSetTyped := permissionsSet.Raw
+						projectResources := resourceReference("dbtcloud_project", *permissionsSet.ProjectID, "id")
+						permissionsSetTyped["project_id"] = projectResources
+						permissionsFilteredProjects = append(permissionsFilteredProjects, permissionsSetTyped)
+					}
+				}
+				permissions = permissionsFilteredProjects
+			}
+
+			serviceTokenTyped["service_token_permissions"] = permissions
+			return serviceTokenTyped
+		},
+		name: func(token serviceToken) string {
+			return token.Name
+		},
+		identity: func(token serviceToken) map[string]any {
+			return map[string]any{"name": token.Name}
+		},
+		label: func(token serviceToken) string {
+			return fmt.Sprintf("%d", token.ID)
+		},
+		importID: func(token serviceToken) string {
+			return fmt.Sprintf("%d", token.ID)
+		},
+		inventory: func(token serviceToken, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{
+				column("active", token.State == 1),
+				column("permissions", permissionSets(token.permissions)),
+			}
+		},
+		url: func(token serviceToken) string {
+			return settingsURL("service-tokens/%d/", token.ID)
+		},
+	})
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Credential]{
+		resourceType: "dbtcloud_snowflake_credential",
+		scope:        "Project",
+		dependencies: []string{"dbtcloud_project"},
+		fetch: func(ctx context.Context, _ *accountData) ([]dbtcloud.Credential, error) {
+			return dbtCloudClient.GetSnowflakeCredentials(ctx, listFilterProjects)
+		},
+		// the inventory lists the credentials no environment uses as well
+		list: func(ctx context.Context, _ *accountData) ([]dbtcloud.Credential, error) {
+			return dbtCloudClient.GetAllWarehouseCredentials(ctx, listFilterProjects, "snowflake")
+		},
+		transform: func(credential dbtcloud.Credential, _ *accountData) map[string]any {
+			credentialTyped := credential.Raw
+
+			credentialID := credential.ID
+			credentialTyped["num_threads"] = credentialTyped["threads"]
+
+			targetURL := deployURL("projects/%d/environments/%d/settings/", credential.ProjectID, credential.EnvironmentID)
+			switch credential.AuthType {
+			case "password":
+				varName := fmt.Sprintf("dbtcloud_snowflake_credential_password_%d", credentialID)
+				AllTFVars = append(AllTFVars, tfVar{
+					varType:        "string",
+					varName:        varName,
+					varDescription: "The password for the snowflake credential " + fmt.Sprintf("%d", credentialID) + " - " + targetURL,
+				})
+				credentialTyped["password"] = hclReference("var." + varName)
+			case "keypair":
+				varName := fmt.Sprintf("dbtcloud_snowflake_credential_private_key_%d", credentialID)
+				AllTFVars = append(AllTFVars, tfVar{
+					varType:        "string",
+					varName:        varName,
+					varDescription: "The private key for the snowflake credential " + fmt.Sprintf("%d", credentialID) + " - " + targetURL,
+				})
+				credentialTyped["private_key"] = hclReference("var." + varName)
+				varNamePassphrase := fmt.Sprintf("dbtcloud_snowflake_credential_private_key_passphrase_%d", credentialID)
+				AllTFVars = append(AllTFVars, tfVar{
+					varType:        "string",
+					varName:        varNamePassphrase,
+					varDescription: "The passphrase for the snowflake credential " + fmt.Sprintf("%d", credentialID) + " - " + targetURL,
+				})
+				credentialTyped["private_key_passphrase"] = hclReference("var." + varNamePassphrase)
+			}
+
+			if linkResource("dbtcloud_project") {
+				credentialTyped["project_id"] = resourceReference("dbtcloud_project", credential.ProjectID, "id")
+			}
+			return credentialTyped
+		},
+		label:    credentialLabel,
+		importID: credentialImportID,
+		projectID: func(credential dbtcloud.Credential) int {
+			return credential.ProjectID
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"project_id", "credential_id"},
+			label:    []string{"credential_id"},
+		},
+		inventory: credentialInventory,
+		url:       credentialURL,
+	})
+}
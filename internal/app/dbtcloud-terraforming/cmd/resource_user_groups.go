@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/gosimple/slug"
+	"github.com/samber/lo"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.User]{
+		resourceType: "dbtcloud_user_groups",
+		scope:        "Account",
+		dependencies: []string{"dbtcloud_group"},
+		fetch:        fetchUserGroups,
+		// the inventory lists all the users, with the default groups
+		list: func(ctx context.Context, _ *accountData) ([]dbtcloud.User, error) {
+			return dbtCloudClient.GetUsers(ctx)
+		},
+		transform: func(user dbtcloud.User, data *accountData) map[string]any {
+			userTyped := user.Raw
+
+			userTyped["user_id"] = user.ID
+			if linkResource("users_by_email") {
+				userSlug := slug.Make(user.Email)
+				userTyped["user_id"] = hclReference("local.id_group_" + userSlug)
+				userTyped["count"] = hclReference("local.count_group_" + userSlug)
+
+				AllLocals[fmt.Sprintf("details_group_%s", userSlug)] = hclRaw(fmt.Sprintf(`[for user in data.dbtcloud_users.all.users : user if user.email == "%s"]`, user.Email))
+				AllLocals[fmt.Sprintf("count_group_%s", userSlug)] = hclRaw(fmt.Sprintf("length(local.%s)", fmt.Sprintf("details_group_%s", userSlug)))
+				AllLocals[fmt.Sprintf("id_group_%s", userSlug)] = hclRaw(fmt.Sprintf("local.count_group_%s == 1 ? local.details_group_%s[0].id : 0", userSlug, userSlug))
+			}
+
+			groupIDs := filterOutDefaultGroupIDs(userGroupIDs(user), buildGroupIDToNameMap(data.groups))
+			userTyped["group_ids"] = groupIDs
+
+			if linkResource("dbtcloud_group") {
+				linkedGroupIDs := lo.Map(groupIDs, func(i int, index int) hclExpression {
+					return resourceReference("dbtcloud_group", i, "id")
+				})
+				userTyped["group_ids"] = linkedGroupIDs
+			}
+			return userTyped
+		},
+		name: func(user dbtcloud.User) string {
+			return user.Email
+		},
+		label: func(user dbtcloud.User) string {
+			return fmt.Sprintf("%d", user.ID)
+		},
+		importID: func(user dbtcloud.User) string {
+			return fmt.Sprintf("%d", user.ID)
+		},
+		stateIDs: stateIDAttributes{
+			importID: []string{"user_id"},
+			label:    []string{"user_id"},
+		},
+		// the built-in default groups are not generated and not linked to
+		links: func(user dbtcloud.User, data *accountData) []resourceLink {
+			groupIDs := filterOutDefaultGroupIDs(userGroupIDs(user), buildGroupIDToNameMap(data.groups))
+			return lo.Map(groupIDs, func(groupID int, _ int) resourceLink {
+				return linkTo("group_ids", "dbtcloud_group", groupID)
+			})
+		},
+		// all the groups of the user, including the default ones
+		inventory: func(user dbtcloud.User, data *accountData) []inventoryColumn {
+			groupIDToName := buildGroupIDToNameMap(data.groups)
+			return []inventoryColumn{
+				column("groups", lo.Map(userGroupIDs(user), func(groupID int, _ int) string {
+					return groupIDToName[groupID]
+				})),
+			}
+		},
+		url: func(user dbtcloud.User) string {
+			return settingsURL("users/%d/", user.ID)
+		},
+	})
+}
+
+// fetchUserGroups returns the users that are a member of at least one group
+// other than the built-in default groups (Owner/Member/Everyone). Those
+// groups are never generated as dbtcloud_group resources, so keeping them
+// would produce dangling references (when linked) or manage membership dbt
+// platform already controls (when not linked). The users left without any
+// group have nothing for the resource to manage.
+func fetchUserGroups(ctx context.Context, data *accountData) ([]dbtcloud.User, error) {
+	users, err := dbtCloudClient.GetUsers(ctx)
+	groupIDToName := buildGroupIDToNameMap(data.groups)
+
+	return lo.Filter(users, func(user dbtcloud.User, _ int) bool {
+		return len(filterOutDefaultGroupIDs(userGroupIDs(user), groupIDToName)) > 0
+	}), err
+}
+
+// buildGroupIDToNameMap indexes a list of groups (as returned by
+// dbtCloudClient.GetGroups()) by their numeric ID, so callers can resolve a
+// group ID to its name (e.g. to check whether it's a default group).
+func buildGroupIDToNameMap(listGroups []dbtcloud.Group) map[int]string {
+	groupIDToName := map[int]string{}
+	for _, group := range listGroups {
+		groupIDToName[group.ID] = group.Name
+	}
+	return groupIDToName
+}
+
+// userGroupIDs returns the IDs of the groups a user is a member of, in the
+// account the user permissions are listed for.
+func userGroupIDs(user dbtcloud.User) []int {
+	if len(user.Permissions) == 0 {
+		return []int{}
+	}
+	return lo.Map(user.Permissions[0].Groups, func(group dbtcloud.Group, _ int) int {
+		return group.ID
+	})
+}
+
+// isDefaultGroupID reports whether groupID resolves (via groupIDToName) to
+// one of the built-in default groups (Owner/Member/Everyone). If the ID
+// isn't found in the map, it is treated as not default.
+func isDefaultGroupID(groupID int, groupIDToName map[int]string) bool {
+	name, ok := groupIDToName[groupID]
+	if !ok {
+		return false
+	}
+	return lo.Contains(defaultGroups, name)
+}
+
+// filterOutDefaultGroupIDs returns groupIDs with any built-in default group
+// (Owner/Member/Everyone) removed. Those groups are never generated as
+// dbtcloud_group resources, so any resource referencing group IDs must
+// exclude them to avoid dangling references (when linked) or attempting to
+// manage membership dbt platform itself controls (when not linked).
+func filterOutDefaultGroupIDs(groupIDs []int, groupIDToName map[int]string) []int {
+	filtered := []int{}
+	for _, groupID := range groupIDs {
+		if isDefaultGroupID(groupID, groupIDToName) {
+			continue
+		}
+		filtered = append(filtered, groupID)
+	}
+	return filtered
+}
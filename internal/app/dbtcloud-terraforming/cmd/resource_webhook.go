@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+)
+
+func init() {
+	registerResource(resource[dbtcloud.Webhook]{
+		resourceType: "dbtcloud_webhook",
+		scope:        "Account",
+		dependencies: []string{"dbtcloud_job"},
+		fetch: func(ctx context.Context, data *accountData) ([]dbtcloud.Webhook, error) {
+			webhooks, err := dbtCloudClient.GetWebhooks(ctx)
+			jobIDs := webhookJobIDs(data)
+			return lo.Filter(webhooks, func(webhook dbtcloud.Webhook, _ int) bool {
+				// if there is no job defined, then the webhook is for all jobs
+				if len(webhook.JobIDs) == 0 {
+					return true
+				}
+				// otherwise, at least one of its jobs must be in the current
+				// projects and not deleted, as having an empty list of jobs
+				// means "all jobs" from a dbt Cloud API standpoint
+				return len(lo.Intersect(webhook.JobIDs, jobIDs)) > 0
+			}), err
+		},
+		// the inventory lists all the webhooks, even the ones of deleted jobs
+		list: func(ctx context.Context, _ *accountData) ([]dbtcloud.Webhook, error) {
+			return dbtCloudClient.GetWebhooks(ctx)
+		},
+		transform: func(webhook dbtcloud.Webhook, data *accountData) map[string]any {
+			webhookTyped := webhook.Raw
+
+			if linkResource("dbtcloud_job") {
+				// we remove jobs that are not relevant to the current project or that have been deleted
+				jobIDs := lo.Intersect(webhook.JobIDs, webhookJobIDs(data))
+				linkedJobIDs := lo.Map(jobIDs, func(s string, index int) hclExpression {
+					return resourceReference("dbtcloud_job", s, "id")
+				})
+				webhookTyped["job_ids"] = linkedJobIDs
+			}
+			return webhookTyped
+		},
+		name: func(webhook dbtcloud.Webhook) string {
+			return webhook.Name
+		},
+		identity: func(webhook dbtcloud.Webhook) map[string]any {
+			return map[string]any{"name": webhook.Name}
+		},
+		label: func(webhook dbtcloud.Webhook) string {
+			return webhook.ID
+		},
+		importID: func(webhook dbtcloud.Webhook) string {
+			return webhook.ID
+		},
+		// all the jobs of the webhook, the deleted ones are not in the
+		// generated config
+		links: func(webhook dbtcloud.Webhook, _ *accountData) []resourceLink {
+			return lo.Map(webhook.JobIDs, func(jobID string, _ int) resourceLink {
+				return linkTo("job_ids", "dbtcloud_job", jobID)
+			})
+		},
+		inventory: func(webhook dbtcloud.Webhook, _ *accountData) []inventoryColumn {
+			return []inventoryColumn{
+				column("active", webhook.Active),
+				column("client_url", webhook.ClientURL),
+				column("event_types", webhook.EventTypes),
+				column("job_ids", webhook.JobIDs),
+			}
+		},
+		url: func(webhook dbtcloud.Webhook) string {
+			return settingsURL("webhooks/%s/", webhook.ID)
+		},
+	})
+}
+
+// webhookJobIDs returns the IDs of the prefetched jobs as strings, as our API
+// for webhooks returns job IDs as strings.
+func webhookJobIDs(data *accountData) []string {
+	return lo.Map(data.jobIDs(), func(jobID int, _ int) string {
+		return fmt.Sprintf("%d", jobID)
+	})
+}
@@ -0,0 +1,350 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/spf13/viper"
+)
+
+// ResourceHandler implements a resource type for all the commands: generate
+// and import iterate over the same items returned by Fetch, so that the
+// resources generated and the ones imported always match.
+type ResourceHandler interface {
+	// ResourceType is the name of the resource in the Terraform provider
+	ResourceType() string
+	// Scope is either "Account" or "Project"
+	Scope() string
+	// Dependencies are the resource types the generated resources can be
+	// linked to. The data of some of them (projects, jobs, environments,
+	// groups and users) is prefetched in accountData for Fetch and Transform.
+	Dependencies() []string
+	// Fetch gets the items to generate and import from dbt Cloud, without the
+	// ones that can't or shouldn't be managed in Terraform. All the API calls
+	// of the resource type happen here.
+	Fetch(ctx context.Context, data *accountData) ([]any, error)
+	// List gets all the items of the resource type for the inventory,
+	// including the ones Fetch leaves out, e.g. the default groups.
+	List(ctx context.Context, data *accountData) ([]any, error)
+	// Transform returns the attributes of the generated resource for an item
+	// returned by Fetch.
+	Transform(item any, data *accountData) map[string]any
+	// Name returns the human-readable name of an item, used for its label
+	// with --label-strategy name, or "" if it doesn't have one.
+	Name(item any) string
+	// Identity returns the values of the attributes identifying an item in a
+	// config written by hand, e.g. the name of a project, or nil if its
+	// resources can't be matched.
+	Identity(item any) map[string]any
+	// Label returns the suffix of the label of the resource of an item. It
+	// identifies the item among the ones of the resource type.
+	Label(item any) string
+	// ImportID returns the ID used by the provider to import an item.
+	ImportID(item any) string
+	// ProjectID returns the ID of the project of an item, 0 for the resource
+	// types of the account.
+	ProjectID(item any) int
+	// StateIDAttributes returns the attributes of the resources in the
+	// Terraform state that match their ImportID and Label.
+	StateIDAttributes() stateIDAttributes
+	// Links returns the links of an item to other items, e.g. from a job to
+	// its environment, including the ones to items that don't exist anymore.
+	Links(item any, data *accountData) []resourceLink
+	// Inventory returns the columns of the inventory specific to the
+	// resource type for an item, e.g. the schedule of a job.
+	Inventory(item any, data *accountData) []inventoryColumn
+	// URL returns the URL of the page of an item in the dbt Cloud UI, or ""
+	// if it doesn't have its own page.
+	URL(item any) string
+}
+
+// resourceLink is a link of an item, through its attribute, to the item of
+// resourceType whose Label is key.
+type resourceLink struct {
+	attribute    string
+	resourceType string
+	key          string
+}
+
+func linkTo(attribute, resourceType string, key any) resourceLink {
+	return resourceLink{attribute: attribute, resourceType: resourceType, key: fmt.Sprint(key)}
+}
+
+// resourceHandlers is the registry of the supported resource types, filled
+// by the init function of the file of each resource type.
+var resourceHandlers = map[string]ResourceHandler{}
+
+func registerResource(handler ResourceHandler) {
+	if _, exists := resourceHandlers[handler.ResourceType()]; exists {
+		panic(fmt.Sprintf("the resource type %s is registered twice", handler.ResourceType()))
+	}
+	resourceHandlers[handler.ResourceType()] = handler
+}
+
+// resourceTypeNames returns the names of the supported resource types, sorted.
+func resourceTypeNames() []string {
+	names := lo.Keys(resourceHandlers)
+	sort.Strings(names)
+	return names
+}
+
+// resourceLabel returns the label of the resource of an item, as used in
+// both the generated config and the import blocks and commands.
+func resourceLabel(handler ResourceHandler, item any) string {
+	if os.Getenv("USE_STATIC_RESOURCE_IDS") == "true" {
+		return terraformResourceNamePrefix
+	}
+	return labelOf(handler.ResourceType(), handler.Label(item))
+}
+
+// resourceAddress returns the Terraform address of the resource of an item,
+// e.g. dbtcloud_project.terraform_managed_resource_123. The import blocks and
+// commands point to it and it matches the generated resource block.
+func resourceAddress(handler ResourceHandler, item any) string {
+	return fmt.Sprintf("%s.%s", handler.ResourceType(), resourceLabel(handler, item))
+}
+
+// resource implements ResourceHandler for the items of type T, so that the
+// functions of each resource type work on their model instead of on any.
+type resource[T any] struct {
+	resourceType string
+	scope        string
+	dependencies []string
+	fetch        func(ctx context.Context, data *accountData) ([]T, error)
+	list         func(ctx context.Context, data *accountData) ([]T, error)
+	transform    func(item T, data *accountData) map[string]any
+	name         func(item T) string
+	identity     func(item T) map[string]any
+	label        func(item T) string
+	importID     func(item T) string
+	projectID    func(item T) int
+	stateIDs     stateIDAttributes
+	links        func(item T, data *accountData) []resourceLink
+	inventory    func(item T, data *accountData) []inventoryColumn
+	url          func(item T) string
+}
+
+func (r resource[T]) ResourceType() string {
+	return r.resourceType
+}
+
+func (r resource[T]) Scope() string {
+	return r.scope
+}
+
+func (r resource[T]) Dependencies() []string {
+	return r.dependencies
+}
+
+func (r resource[T]) Fetch(ctx context.Context, data *accountData) ([]any, error) {
+	items, err := r.fetch(ctx, data)
+	return lo.ToAnySlice(items), err
+}
+
+func (r resource[T]) List(ctx context.Context, data *accountData) ([]any, error) {
+	if r.list == nil {
+		return r.Fetch(ctx, data)
+	}
+	items, err := r.list(ctx, data)
+	return lo.ToAnySlice(items), err
+}
+
+func (r resource[T]) Transform(item any, data *accountData) map[string]any {
+	return r.transform(item.(T), data)
+}
+
+func (r resource[T]) Name(item any) string {
+	if r.name == nil {
+		return ""
+	}
+	return r.name(item.(T))
+}
+
+func (r resource[T]) Identity(item any) map[string]any {
+	if r.identity == nil {
+		return nil
+	}
+	return r.identity(item.(T))
+}
+
+func (r resource[T]) Label(item any) string {
+	return r.label(item.(T))
+}
+
+func (r resource[T]) ImportID(item any) string {
+	return r.importID(item.(T))
+}
+
+func (r resource[T]) ProjectID(item any) int {
+	if r.projectID == nil {
+		return 0
+	}
+	return r.projectID(item.(T))
+}
+
+func (r resource[T]) StateIDAttributes() stateIDAttributes {
+	ids := r.stateIDs
+	if ids.importID == nil {
+		ids.importID = []string{"id"}
+	}
+	if ids.label == nil {
+		ids.label = []string{"id"}
+	}
+	return ids
+}
+
+func (r resource[T]) Links(item any, data *accountData) []resourceLink {
+	if r.links == nil {
+		return nil
+	}
+	return r.links(item.(T), data)
+}
+
+func (r resource[T]) Inventory(item any, data *accountData) []inventoryColumn {
+	if r.inventory == nil {
+		return nil
+	}
+	return r.inventory(item.(T), data)
+}
+
+func (r resource[T]) URL(item any) string {
+	if r.url == nil {
+		return ""
+	}
+	return r.url(item.(T))
+}
+
+// accountData is the data shared between resource types, e.g. the jobs that
+// webhooks and notifications refer to. It is fetched once before the handlers
+// run and must not be modified by them: the handlers modifying the payload of
+// their items fetch their own copy from the client, whose cache makes this
+// cheap.
+type accountData struct {
+	projects     []dbtcloud.Project
+	jobs         []dbtcloud.Job
+	environments []dbtcloud.Environment
+	groups       []dbtcloud.Group
+	users        []dbtcloud.User
+}
+
+// prefetchAccountData fetches the data needed by the dependencies of the
+// handlers. A failure is recorded against the resource type of the data, the
+// resource types relying on it will then be empty or incomplete.
+func prefetchAccountData(ctx context.Context, handlers []ResourceHandler) *accountData {
+	dependencies := []string{}
+	for _, handler := range handlers {
+		dependencies = append(dependencies, handler.Dependencies()...)
+	}
+
+	data := &accountData{}
+	var err error
+
+	// we always get all projects
+	data.projects, err = dbtCloudClient.GetProjects(ctx, listFilterProjects)
+	recordError("dbtcloud_project", err)
+
+	// we only get jobs if we need them, there might be a lot of them
+	if lo.Contains(dependencies, "dbtcloud_job") {
+		data.jobs, err = dbtCloudClient.GetJobs(ctx, listFilterProjects)
+		recordError("dbtcloud_job", err)
+	}
+	if lo.Contains(dependencies, "dbtcloud_environment") {
+		data.environments, err = dbtCloudClient.GetEnvironments(ctx, listFilterProjects)
+		recordError("dbtcloud_environment", err)
+	}
+	if lo.Contains(dependencies, "dbtcloud_group") {
+		data.groups, err = dbtCloudClient.GetGroups(ctx)
+		recordError("dbtcloud_group", err)
+	}
+	if lo.Contains(dependencies, "dbtcloud_user") {
+		data.users, err = dbtCloudClient.GetUsers(ctx)
+		recordError("dbtcloud_user", err)
+	}
+
+	return data
+}
+
+// fetchedAccount is the data of the account the config and the imports are
+// written from, fetched once by genimport for both.
+type fetchedAccount struct {
+	handlers  []ResourceHandler
+	data      *accountData
+	resources map[string][]any
+}
+
+// fetchAccount fetches the data shared between the resource types, e.g. the
+// jobs that webhooks and notifications refer to, and the items of the
+// handlers, see fetchResources.
+func fetchAccount(ctx context.Context, handlers []ResourceHandler) *fetchedAccount {
+	listFilterProjects = viper.GetIntSlice("projects")
+
+	data := prefetchAccountData(ctx, handlers)
+	return &fetchedAccount{
+		handlers:  handlers,
+		data:      data,
+		resources: fetchResources(ctx, handlers, data),
+	}
+}
+
+// fetchResources fetches the items of the handlers, keyed by resource type,
+// without the ones already in the state or in --existing-config-dir, and
+// assigns the labels of their resources. This happens before any item is
+// transformed so that the references between resources use the labels of the
+// resources they point to.
+func fetchResources(ctx context.Context, handlers []ResourceHandler, data *accountData) map[string][]any {
+	resourceLabels = map[string]map[string]string{}
+
+	items := map[string][]any{}
+	for _, handler := range handlers {
+		resourceType := handler.ResourceType()
+		log.Debugf("fetching %s", resourceType)
+
+		var err error
+		items[resourceType], err = handler.Fetch(ctx, data)
+		recordError(resourceType, err)
+		items[resourceType] = skipManagedItems(handler, items[resourceType])
+	}
+
+	skipExistingConfigItems(handlers, items)
+	for _, handler := range handlers {
+		assignResourceLabels(handler, items[handler.ResourceType()], data)
+	}
+	return items
+}
+
+func (d *accountData) projectIDs() []int {
+	return lo.Map(d.projects, func(project dbtcloud.Project, _ int) int {
+		return project.ID
+	})
+}
+
+func (d *accountData) jobIDs() []int {
+	return lo.Map(d.jobs, func(job dbtcloud.Job, _ int) int {
+		return job.ID
+	})
+}
+
+// userEmails returns the emails of the users keyed by user ID.
+func (d *accountData) userEmails() map[int]string {
+	return lo.SliceToMap(d.users, func(user dbtcloud.User) (int, string) {
+		return user.ID, user.Email
+	})
+}
+
+// selectedHandlers returns the handlers of resourceTypes, failing for the
+// resource types that are not supported.
+func selectedHandlers(resourceTypes []string, unsupportedMessage string) ([]ResourceHandler, error) {
+	handlers := []ResourceHandler{}
+	for _, resourceType := range resourceTypes {
+		handler, ok := resourceHandlers[resourceType]
+		if !ok {
+			return nil, fmt.Errorf("%q is not yet supported for %s", resourceType, unsupportedMessage)
+		}
+		handlers = append(handlers, handler)
+	}
+	return handlers, nil
+}
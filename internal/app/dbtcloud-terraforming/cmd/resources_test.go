@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResources_README checks that the table of supported resources in the
+// README lists every registered resource type with its scope, so that the
+// docs don't drift from the registry.
+func TestResources_README(t *testing.T) {
+	readme, err := os.ReadFile("../../../../README.md")
+	require.NoError(t, err)
+
+	resourceName := regexp.MustCompile(`^dbtcloud_[a-z_]+`)
+	documented := map[string][]string{}
+	for _, line := range strings.Split(string(readme), "\n") {
+		cells := strings.Split(line, "|")
+		if len(cells) < 6 {
+			continue
+		}
+		name := resourceName.FindString(strings.TrimSpace(cells[1]))
+		if name == "" {
+			continue
+		}
+		documented[name] = []string{strings.TrimSpace(cells[2]), strings.TrimSpace(cells[3]), strings.TrimSpace(cells[4])}
+	}
+
+	for _, resourceType := range resourceTypeNames() {
+		t.Run(resourceType, func(t *testing.T) {
+			row, ok := documented[resourceType]
+			require.True(t, ok, "%s is missing from the README", resourceType)
+			assert.Equal(t, []string{resourceHandlers[resourceType].Scope(), "✅", "✅"}, row)
+		})
+	}
+}
+
+// TestResources_Dependencies checks that the handlers only depend on resource
+// types that exist, the prefetched data being looked up by name.
+func TestResources_Dependencies(t *testing.T) {
+	for _, handler := range resourceHandlers {
+		for _, dependency := range handler.Dependencies() {
+			_, registered := resourceHandlers[dependency]
+			assert.True(t, registered || dependency == "dbtcloud_user", "%s depends on the unknown resource type %s", handler.ResourceType(), dependency)
+		}
+	}
+}
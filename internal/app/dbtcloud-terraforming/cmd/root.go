@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -8,9 +10,10 @@ import (
 )
 
 var log = logrus.New()
-var zoneID, hostURL, apiToken, accountID, terraformInstallPath, terraformingInstallPath, terraformBinaryPath string
+var zoneID, hostURL, apiToken, accountID, terraformInstallPath, terraformingInstallPath, terraformBinaryPath, fromSnapshot string
 var listFilterProjects []int
-var verbose, useModernImportBlock, parameterizeJobs bool
+var rateLimit, maxRetries, concurrency int
+var verbose, useModernImportBlock, parameterizeJobs, noCache bool
 var dbtCloudClient *dbtcloud.DbtCloudHTTPClient
 var terraformImportCmdPrefix = "terraform import"
 var terraformResourceNamePrefix = "terraform_managed_resource"
@@ -29,7 +32,16 @@ all of their existing dbt Cloud configuration into Terraform.`,
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		log.Error(err)
-		return
+		os.Exit(1)
+	}
+
+	if dbtCloudClient != nil && dbtCloudClient.Cache != nil {
+		hits, misses := dbtCloudClient.Cache.Stats()
+		log.Debugf("%d API requests sent, %d served from the cache", misses, hits)
+	}
+
+	if printErrorSummary(os.Stderr) > 0 || runFailed {
+		os.Exit(1)
 	}
 }
 
@@ -48,6 +60,11 @@ func init() {
 		log.Fatal(err)
 	}
 
+	rootCmd.PersistentFlags().StringVar(&outputDir, "output-dir", "", "Output directory. If specified, the output is split in providers.tf, variables.tf, locals.tf, imports.tf, terraform.tfvars.example and one file per resource type or per project, see --split-by")
+	if err = viper.BindPFlag("output-dir", rootCmd.PersistentFlags().Lookup("output-dir")); err != nil {
+		log.Fatal(err)
+	}
+
 	// Account
 	rootCmd.PersistentFlags().StringVarP(&accountID, "account", "a", "", "Use specific account ID for commands. [env var: DBT_CLOUD_ACCOUNT_ID]")
 	if err = viper.BindPFlag("account", rootCmd.PersistentFlags().Lookup("account")); err != nil {
@@ -75,6 +92,38 @@ func init() {
 		log.Fatal(err)
 	}
 
+	rootCmd.PersistentFlags().IntVar(&rateLimit, "rate-limit", dbtcloud.DefaultRequestsPerMinute, "Maximum number of requests per minute sent to the dbt Cloud API, 0 for no limit. [env var: DBT_CLOUD_RATE_LIMIT]")
+	if err = viper.BindPFlag("rate-limit", rootCmd.PersistentFlags().Lookup("rate-limit")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("rate-limit", "DBT_CLOUD_RATE_LIMIT"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries", dbtcloud.DefaultMaxRetries, "Number of times an API request is retried after a 429, a 5xx or a network error, 0 to disable retries. [env var: DBT_CLOUD_MAX_RETRIES]")
+	if err = viper.BindPFlag("max-retries", rootCmd.PersistentFlags().Lookup("max-retries")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("max-retries", "DBT_CLOUD_MAX_RETRIES"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 4, "Number of API requests sent at the same time when fetching data for each project, job or connection. [env var: DBT_CLOUD_CONCURRENCY]")
+	if err = viper.BindPFlag("concurrency", rootCmd.PersistentFlags().Lookup("concurrency")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("concurrency", "DBT_CLOUD_CONCURRENCY"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Send every API request to dbt Cloud instead of reusing the responses already received during the run. [env var: DBT_CLOUD_NO_CACHE]")
+	if err = viper.BindPFlag("no-cache", rootCmd.PersistentFlags().Lookup("no-cache")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("no-cache", "DBT_CLOUD_NO_CACHE"); err != nil {
+		log.Fatal(err)
+	}
+
 	rootCmd.PersistentFlags().StringVarP(&terraformingInstallPath, "terraforming-install-path", "", "", "Path to installation [env var: TERRAFORMING_INSTALL_PATH]")
 	if err = viper.BindPFlag("terraforming-install-path", rootCmd.PersistentFlags().Lookup("terraforming-install-path")); err != nil {
 		log.Fatal(err)
@@ -100,9 +149,9 @@ func init() {
 
 	rootCmd.PersistentFlags().StringSliceVar(&listLinkedResources, "linked-resource-types", []string{}, "List of resource types to make dependencies links to instead of using IDs. Can be set to 'all' for linking all resources")
 
-	rootCmd.PersistentFlags().BoolVarP(&useModernImportBlock, "modern-import-block", "", true, "Whether to generate HCL import blocks for generated resources instead of terraform import compatible CLI commands. This is only compatible with Terraform 1.5+.")
+	rootCmd.PersistentFlags().BoolVarP(&useModernImportBlock, "modern-import-block", "", true, "Whether to generate HCL import blocks for generated resources instead of terraform import compatible CLI commands. This is only compatible with Terraform 1.5+ and OpenTofu 1.6+.")
 
-	rootCmd.PersistentFlags().StringVar(&terraformInstallPath, "terraform-install-path", ".", "Path to an initialized Terraform working directory [env var: DBT_CLOUD_TERRAFORM_INSTALL_PATH]")
+	rootCmd.PersistentFlags().StringVar(&terraformInstallPath, "terraform-install-path", "", "Path to an initialized Terraform working directory. If not set, a temporary one is initialized with the dbt Cloud provider --provider-version [env var: DBT_CLOUD_TERRAFORM_INSTALL_PATH]")
 
 	if err = viper.BindPFlag("terraform-install-path", rootCmd.PersistentFlags().Lookup("terraform-install-path")); err != nil {
 		log.Fatal(err)
@@ -122,7 +171,135 @@ func init() {
 		log.Fatal(err)
 	}
 
+	rootCmd.PersistentFlags().StringVar(&providerSchemaFile, "provider-schema-file", "", "Path to the output of 'terraform providers schema -json' to read the schema of the dbt Cloud provider from, instead of running Terraform [env var: DBT_CLOUD_PROVIDER_SCHEMA_FILE]")
+	if err = viper.BindPFlag("provider-schema-file", rootCmd.PersistentFlags().Lookup("provider-schema-file")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("provider-schema-file", "DBT_CLOUD_PROVIDER_SCHEMA_FILE"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().StringVar(&binaryFlavor, "binary-flavor", "terraform", "Binary used to read the provider schema and in the import commands, either terraform or tofu (OpenTofu) [env var: DBT_CLOUD_BINARY_FLAVOR]")
+	if err = viper.BindPFlag("binary-flavor", rootCmd.PersistentFlags().Lookup("binary-flavor")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("binary-flavor", "DBT_CLOUD_BINARY_FLAVOR"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().Bool("tofu", false, "Use OpenTofu instead of Terraform, same as --binary-flavor tofu")
+	if err = viper.BindPFlag("tofu", rootCmd.PersistentFlags().Lookup("tofu")); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().StringVar(&providerVersion, "provider-version", defaultProviderVersion, "Version constraint of the dbt Cloud provider installed to read its schema when --terraform-install-path is not set. The generated config requires the same version [env var: DBT_CLOUD_PROVIDER_VERSION]")
+	if err = viper.BindPFlag("provider-version", rootCmd.PersistentFlags().Lookup("provider-version")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("provider-version", "DBT_CLOUD_PROVIDER_VERSION"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().StringVar(&pluginCacheDir, "plugin-cache-dir", "", "Directory where the installed providers are cached between runs. Defaults to TF_PLUGIN_CACHE_DIR if set, or to a directory in the user cache directory [env var: DBT_CLOUD_PLUGIN_CACHE_DIR]")
+	if err = viper.BindPFlag("plugin-cache-dir", rootCmd.PersistentFlags().Lookup("plugin-cache-dir")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("plugin-cache-dir", "DBT_CLOUD_PLUGIN_CACHE_DIR"); err != nil {
+		log.Fatal(err)
+	}
+
+	rootCmd.PersistentFlags().StringVar(&providerMirror, "provider-mirror", "", "Filesystem mirror directory to install the dbt Cloud provider from instead of the registry [env var: DBT_CLOUD_PROVIDER_MIRROR]")
+	if err = viper.BindPFlag("provider-mirror", rootCmd.PersistentFlags().Lookup("provider-mirror")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("provider-mirror", "DBT_CLOUD_PROVIDER_MIRROR"); err != nil {
+		log.Fatal(err)
+	}
+
+	// the flags of single commands are bound to viper when the command runs,
+	// see sharedPreRun
+	for _, command := range []*cobra.Command{generateCmd, importCommand, genimportCmd} {
+		command.Flags().StringVar(&splitBy, "split-by", "resource-type", "How the resources are split in files with --output-dir, either resource-type or project")
+	}
+
+	for _, command := range []*cobra.Command{generateCmd, importCommand, genimportCmd, exportJobsCmd} {
+		command.Flags().StringVar(&labelStrategy, "label-strategy", "id", "How the labels of the resources are built, either id (e.g. terraform_managed_resource_123) or name (e.g. analytics__nightly_full_refresh)")
+	}
+
+	genimportCmd.Flags().BoolVar(&converge, "converge", false, "Plan the generated config and rewrite the attributes that would change with their imported value, or ignore their changes when they can't be read, until the plan only imports resources [env var: DBT_CLOUD_CONVERGE]")
+	if err = viper.BindEnv("converge", "DBT_CLOUD_CONVERGE"); err != nil {
+		log.Fatal(err)
+	}
+
+	genimportCmd.Flags().IntVar(&convergeMaxIterations, "converge-max-iterations", 5, "Maximum number of plans run by --converge [env var: DBT_CLOUD_CONVERGE_MAX_ITERATIONS]")
+	if err = viper.BindEnv("converge-max-iterations", "DBT_CLOUD_CONVERGE_MAX_ITERATIONS"); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, command := range []*cobra.Command{generateCmd, importCommand, genimportCmd} {
+		command.Flags().StringVar(&stateDir, "state-dir", "", "Initialized Terraform working directory whose state lists the resources already managed. They are skipped and the resources linked to them reference their address in the state [env var: DBT_CLOUD_STATE_DIR]")
+	}
+	if err = viper.BindEnv("state-dir", "DBT_CLOUD_STATE_DIR"); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, command := range []*cobra.Command{generateCmd, importCommand, genimportCmd} {
+		command.Flags().StringVar(&stateFile, "state-file", "", "Same as --state-dir with a state file, e.g. the output of terraform state pull [env var: DBT_CLOUD_STATE_FILE]")
+	}
+	if err = viper.BindEnv("state-file", "DBT_CLOUD_STATE_FILE"); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, command := range []*cobra.Command{generateCmd, importCommand, genimportCmd} {
+		command.Flags().StringVar(&existingConfigDir, "existing-config-dir", "", "Directory of Terraform files already defining dbt Cloud resources. The objects matching them, e.g. by name, are skipped and the resources linked to them reference their address [env var: DBT_CLOUD_EXISTING_CONFIG_DIR]")
+	}
+	if err = viper.BindEnv("existing-config-dir", "DBT_CLOUD_EXISTING_CONFIG_DIR"); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, command := range []*cobra.Command{generateCmd, importCommand, genimportCmd} {
+		command.Flags().StringVar(&outputFormat, "format", "hcl", "Syntax of the config, either hcl or tf-json (Terraform JSON, in .tf.json files) [env var: DBT_CLOUD_FORMAT]")
+	}
+	if err = viper.BindEnv("format", "DBT_CLOUD_FORMAT"); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, command := range []*cobra.Command{generateCmd, importCommand, genimportCmd} {
+		command.Flags().StringVar(&target, "target", "terraform", "Infrastructure as code tool the config is written for, either terraform or pulumi-yaml (a Pulumi YAML program using the dbtcloud bridged provider), with generate and genimport [env var: DBT_CLOUD_TARGET]")
+	}
+	if err = viper.BindEnv("target", "DBT_CLOUD_TARGET"); err != nil {
+		log.Fatal(err)
+	}
+
+	graphCmd.Flags().StringVar(&graphFormat, "graph-format", "dot", "Format of the output of graph, either dot (Graphviz), mermaid or json [env var: DBT_CLOUD_GRAPH_FORMAT]")
+	if err = viper.BindEnv("graph-format", "DBT_CLOUD_GRAPH_FORMAT"); err != nil {
+		log.Fatal(err)
+	}
+
+	inventoryCmd.Flags().StringVar(&inventoryFormat, "inventory-format", "csv", "Format of the output of inventory, either csv, json or markdown [env var: DBT_CLOUD_INVENTORY_FORMAT]")
+	if err = viper.BindEnv("inventory-format", "DBT_CLOUD_INVENTORY_FORMAT"); err != nil {
+		log.Fatal(err)
+	}
+
+	auditCmd.Flags().StringVar(&auditFormat, "audit-format", "text", "Format of the output of audit, either text, json or sarif [env var: DBT_CLOUD_AUDIT_FORMAT]")
+	if err = viper.BindEnv("audit-format", "DBT_CLOUD_AUDIT_FORMAT"); err != nil {
+		log.Fatal(err)
+	}
+
+	exportJobsCmd.Flags().BoolVar(&jobsAsCodeVars, "jobs-as-code-vars", false, "Replace the account, project and environment IDs of export-jobs with dbt-jobs-as-code variables, written to vars.yml [env var: DBT_CLOUD_JOBS_AS_CODE_VARS]")
+	if err = viper.BindEnv("jobs-as-code-vars", "DBT_CLOUD_JOBS_AS_CODE_VARS"); err != nil {
+		log.Fatal(err)
+	}
+
 	rootCmd.PersistentFlags().BoolVarP(&parameterizeJobs, "parameterize-jobs", "", false, "Whether to parameterize jobs. Default=false")
+
+	rootCmd.PersistentFlags().StringVar(&fromSnapshot, "from-snapshot", "", "Path to a snapshot file created with the fetch command. The dbt Cloud data is read from it instead of the API [env var: DBT_CLOUD_FROM_SNAPSHOT]")
+	if err = viper.BindPFlag("from-snapshot", rootCmd.PersistentFlags().Lookup("from-snapshot")); err != nil {
+		log.Fatal(err)
+	}
+	if err = viper.BindEnv("from-snapshot", "DBT_CLOUD_FROM_SNAPSHOT"); err != nil {
+		log.Fatal(err)
+	}
 }
 
 // initConfig reads ENV variables if set.
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/spf13/viper"
+)
+
+//go:generate go run ../../../tools/providerschema -provider-version "~> 1.0" -output-dir schemas
+
+// embeddedSchemas holds the snapshot of the provider schema written by go
+// generate, see schemas/README.md.
+//
+//go:embed schemas
+var embeddedSchemas embed.FS
+
+// providerSources are the addresses of the provider in the registries of
+// Terraform and OpenTofu, by --binary-flavor
+var providerSources = map[string]string{
+	"terraform": "registry.terraform.io/dbt-labs/dbtcloud",
+	"tofu":      "registry.opentofu.org/dbt-labs/dbtcloud",
+}
+
+var binaryFlavor string
+
+// defaultProviderVersion is the version constraint of the provider installed
+// in the temporary working directory, known to work with the tool
+const defaultProviderVersion = "~> 1.0"
+
+var providerSchemaFile, providerVersion, pluginCacheDir, providerMirror string
+
+// loadProviderSchema returns the schema of the dbt Cloud provider, read from
+// --provider-schema-file if set, otherwise from Terraform and, if Terraform
+// can't be used (e.g. without network access), from the embedded snapshot.
+// It also returns the version constraint of the provider the schema is for,
+// empty when it is not known.
+func loadProviderSchema(ctx context.Context) (*tfjson.ProviderSchema, string, error) {
+	if providerSchemaFile != "" {
+		log.Debugf("reading the provider schema from %s", providerSchemaFile)
+		content, err := os.ReadFile(providerSchemaFile)
+		if err != nil {
+			return nil, "", err
+		}
+		schema, err := parseProviderSchema(content)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read the provider schema from %s: %w", providerSchemaFile, err)
+		}
+		return schema, "", nil
+	}
+
+	schema, schemaVersion, err := terraformProviderSchema(ctx)
+	if err == nil {
+		return schema, schemaVersion, nil
+	}
+
+	embeddedSchema, embeddedVersion, embeddedErr := embeddedProviderSchema()
+	if embeddedErr != nil {
+		return nil, "", errors.Join(err, embeddedErr)
+	}
+	log.Warnf("%s, using the schema of the version %s of the provider embedded in dbtcloud-terraforming", err, embeddedVersion)
+	return embeddedSchema, embeddedVersion, nil
+}
+
+// terraformProviderSchema reads the provider schema with Terraform or
+// OpenTofu, in the --terraform-install-path working directory or, if not set,
+// in a temporary one initialized with the version --provider-version of the
+// provider.
+func terraformProviderSchema(ctx context.Context) (*tfjson.ProviderSchema, string, error) {
+	workingDir := viper.GetString("terraform-install-path")
+
+	schemaVersion := ""
+	if workingDir == "" {
+		tmpDir, err := os.MkdirTemp("", "tfworkingdir")
+		if err != nil {
+			return nil, "", err
+		}
+		defer os.RemoveAll(tmpDir)
+		workingDir = tmpDir
+		schemaVersion = providerVersion
+	}
+
+	// Setup and configure Terraform to operate in the working directory where
+	// the provider is configured.
+	log.Debugf("initializing Terraform in %s", workingDir)
+	tf, cleanup, err := newTerraform(ctx, workingDir)
+	if err != nil {
+		return nil, "", err
+	}
+	defer cleanup()
+
+	if schemaVersion != "" {
+		if err := bootstrapWorkingDir(ctx, tf, workingDir); err != nil {
+			return nil, "", err
+		}
+	}
+
+	log.Debug("reading Terraform schema for dbt Cloud provider")
+	ps, err := tf.ProvidersSchema(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read provider schema: %w", err)
+	}
+	s, err := dbtCloudProviderSchema(ps)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to detect provider installation: %w", err)
+	}
+	return s, schemaVersion, nil
+}
+
+// bootstrapWorkingDir writes the provider requirements pinned to
+// --provider-version in workingDir and initializes it.
+func bootstrapWorkingDir(ctx context.Context, tf *tfexec.Terraform, workingDir string) error {
+	config := hclwrite.NewEmptyFile()
+	writeRequiredProviders(config.Body(), providerVersion)
+	if err := os.WriteFile(filepath.Join(workingDir, "main.tf"), config.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	log.Debugf("installing the version %s of the dbt Cloud provider", providerVersion)
+	return initWorkingDir(ctx, tf)
+}
+
+// parseProviderSchema returns the schema of the dbt Cloud provider from the
+// output of `terraform providers schema -json`.
+func parseProviderSchema(content []byte) (*tfjson.ProviderSchema, error) {
+	var ps tfjson.ProviderSchemas
+	if err := json.Unmarshal(content, &ps); err != nil {
+		return nil, err
+	}
+	return dbtCloudProviderSchema(&ps)
+}
+
+// dbtCloudProviderSchema returns the schema of the dbt Cloud provider among
+// the schemas of the providers. It is looked up in the registry of
+// --binary-flavor first, then in the other one as the schema files and the
+// embedded schema can come from either Terraform or OpenTofu.
+func dbtCloudProviderSchema(ps *tfjson.ProviderSchemas) (*tfjson.ProviderSchema, error) {
+	if s := ps.Schemas[providerSources[binaryFlavor]]; s != nil {
+		return s, nil
+	}
+	for _, source := range providerSources {
+		if s := ps.Schemas[source]; s != nil {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("the schema of %s is missing", providerSources[binaryFlavor])
+}
+
+// embeddedProviderSchema returns the embedded provider schema and the version
+// of the provider it is for.
+func embeddedProviderSchema() (*tfjson.ProviderSchema, string, error) {
+	content, err := embeddedSchemas.ReadFile("schemas/dbtcloud.json")
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", fmt.Errorf("no provider schema is embedded in this build of dbtcloud-terraforming, set --provider-schema-file to the output of `terraform providers schema -json`")
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	schema, err := parseProviderSchema(content)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read the embedded provider schema: %w", err)
+	}
+
+	providerVersion, err := embeddedSchemas.ReadFile("schemas/dbtcloud.version")
+	if err != nil {
+		return nil, "", err
+	}
+	return schema, strings.TrimSpace(string(providerVersion)), nil
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const schemaTestProvidersSchema = `{
+  "format_version": "1.0",
+  "provider_schemas": {
+    "registry.terraform.io/dbt-labs/dbtcloud": {
+      "provider": {"version": 0, "block": {}},
+      "resource_schemas": {
+        "dbtcloud_project": {
+          "version": 0,
+          "block": {
+            "attributes": {
+              "id": {"type": "string", "computed": true},
+              "name": {"type": "string", "required": true}
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+func TestSchema_ProviderSchemaFile(t *testing.T) {
+	defer func(file string) { providerSchemaFile = file }(providerSchemaFile)
+
+	providerSchemaFile = filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(providerSchemaFile, []byte(schemaTestProvidersSchema), 0644))
+
+	schema, schemaVersion, err := loadProviderSchema(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, schemaVersion)
+	require.Contains(t, schema.ResourceSchemas, "dbtcloud_project")
+	assert.True(t, schema.ResourceSchemas["dbtcloud_project"].Block.Attributes["name"].Required)
+}
+
+func TestSchema_ParseProviderSchemaWithoutProvider(t *testing.T) {
+	_, err := parseProviderSchema([]byte(`{"format_version": "1.0", "provider_schemas": {}}`))
+	assert.ErrorContains(t, err, "the schema of registry.terraform.io/dbt-labs/dbtcloud is missing")
+}
+
+func TestSchema_WriteRequiredProviders(t *testing.T) {
+	f := hclwrite.NewEmptyFile()
+	writeRequiredProviders(f.Body(), "~> 1.0")
+	assert.Equal(t, `terraform {
+  required_providers {
+    dbtcloud = {
+      source  = "dbt-labs/dbtcloud"
+      version = "~> 1.0"
+    }
+  }
+}
+
+`, string(hclwrite.Format(f.Bytes())))
+
+	// without version when the schema is not known to be for one
+	f = hclwrite.NewEmptyFile()
+	writeRequiredProviders(f.Body(), "")
+	assert.NotContains(t, string(f.Bytes()), "version")
+}
+
+func TestSchema_OpenTofuProviderSchema(t *testing.T) {
+	defer func(flavor string) { binaryFlavor = flavor }(binaryFlavor)
+
+	// the schemas of OpenTofu are under its own registry
+	tofuSchema := strings.ReplaceAll(schemaTestProvidersSchema, "registry.terraform.io", "registry.opentofu.org")
+
+	for _, flavor := range []string{"terraform", "tofu"} {
+		binaryFlavor = flavor
+		for _, content := range []string{schemaTestProvidersSchema, tofuSchema} {
+			schema, err := parseProviderSchema([]byte(content))
+			require.NoError(t, err)
+			assert.Contains(t, schema.ResourceSchemas, "dbtcloud_project")
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/samber/lo"
+)
+
+var stateDir, stateFile string
+
+// stateIDAttributes are the attributes of a resource in the Terraform state
+// that identify its item: joined with ":" they are the ImportID of the item,
+// and joined with "_" its Label. Both default to the id attribute.
+type stateIDAttributes struct {
+	importID []string
+	label    []string
+}
+
+// stateResource is a dbt Cloud resource of the Terraform state.
+type stateResource struct {
+	address      string
+	resourceType string
+	attributes   map[string]any
+}
+
+// managedResources are the addresses of the resources already in the state
+// of --state-dir or --state-file, by resource type and ImportID of their
+// item, and managedLabels the addresses of the resources in the state or in
+// --existing-config-dir by resource type and Label of their item, to link
+// resources to them.
+var managedResources, managedLabels map[string]map[string]string
+
+// loadState indexes the dbt Cloud resources of the state of --state-dir or
+// --state-file in managedResources and managedLabels.
+func loadState(ctx context.Context) error {
+	var resources []stateResource
+	var err error
+	if stateDir != "" {
+		resources, err = showState(ctx, stateDir)
+	} else {
+		resources, err = readStateFile(stateFile)
+	}
+	if err != nil {
+		return err
+	}
+
+	managedResources, managedLabels = indexStateResources(resources)
+	log.Debugf("%d dbt Cloud resources found in the state", len(resources))
+	return nil
+}
+
+// indexStateResources returns the addresses of the resources by resource
+// type and ImportID, and by resource type and Label, of their item.
+func indexStateResources(resources []stateResource) (map[string]map[string]string, map[string]map[string]string) {
+	byImportID := map[string]map[string]string{}
+	byLabel := map[string]map[string]string{}
+	for _, resource := range resources {
+		handler, ok := resourceHandlers[resource.resourceType]
+		if !ok {
+			continue
+		}
+		ids := handler.StateIDAttributes()
+
+		if importID, ok := stateID(resource.attributes, ids.importID, ":"); ok {
+			if byImportID[resource.resourceType] == nil {
+				byImportID[resource.resourceType] = map[string]string{}
+			}
+			byImportID[resource.resourceType][importID] = resource.address
+		}
+		if label, ok := stateID(resource.attributes, ids.label, "_"); ok {
+			if byLabel[resource.resourceType] == nil {
+				byLabel[resource.resourceType] = map[string]string{}
+			}
+			byLabel[resource.resourceType][label] = resource.address
+		}
+	}
+	return byImportID, byLabel
+}
+
+// stateID joins the values of the attributes with sep, or returns false if
+// any of them is missing.
+func stateID(attributes map[string]any, names []string, sep string) (string, bool) {
+	values := []string{}
+	for _, name := range names {
+		value, ok := attributeString(attributes[name])
+		if !ok {
+			return "", false
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, sep), true
+}
+
+// attributeString returns an ID attribute of the state as a string, the
+// numbers being decoded as float64.
+func attributeString(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, value != ""
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case json.Number:
+		return value.String(), true
+	default:
+		return "", false
+	}
+}
+
+// skipManagedItems returns the items of handler that are not in the state.
+func skipManagedItems(handler ResourceHandler, items []any) []any {
+	addresses := managedResources[handler.ResourceType()]
+	if len(addresses) == 0 {
+		return items
+	}
+
+	unmanaged := lo.Filter(items, func(item any, _ int) bool {
+		_, managed := addresses[handler.ImportID(item)]
+		return !managed
+	})
+	if skipped := len(items) - len(unmanaged); skipped > 0 {
+		log.Infof("skipping %d %s already in the state", skipped, handler.ResourceType())
+	}
+	return unmanaged
+}
+
+// showState returns the dbt Cloud resources of the state of the initialized
+// Terraform working directory dir.
+func showState(ctx context.Context, dir string) ([]stateResource, error) {
+	tf, cleanup, err := newTerraform(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	state, err := tf.Show(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the state of %s: %w", dir, err)
+	}
+	if state.Values == nil {
+		return nil, nil
+	}
+	return moduleStateResources(state.Values.RootModule), nil
+}
+
+// moduleStateResources returns the dbt Cloud resources of module and of its
+// child modules.
+func moduleStateResources(module *tfjson.StateModule) []stateResource {
+	if module == nil {
+		return nil
+	}
+
+	resources := []stateResource{}
+	for _, resource := range module.Resources {
+		if resource.Mode != tfjson.ManagedResourceMode || !strings.HasPrefix(resource.Type, "dbtcloud_") {
+			continue
+		}
+		resources = append(resources, stateResource{
+			address:      resource.Address,
+			resourceType: resource.Type,
+			attributes:   resource.AttributeValues,
+		})
+	}
+	for _, child := range module.ChildModules {
+		resources = append(resources, moduleStateResources(child)...)
+	}
+	return resources
+}
+
+// stateFileContent is the part of the format of the state files (version 4)
+// we read.
+type stateFileContent struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   any            `json:"index_key"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// readStateFile returns the dbt Cloud resources of the state file path, e.g.
+// terraform.tfstate or the output of `terraform state pull`.
+func readStateFile(path string) ([]stateResource, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state stateFileContent
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, fmt.Errorf("failed to read the state from %s: %w", path, err)
+	}
+	if state.Version != 4 {
+		return nil, fmt.Errorf("the version %d of the state format of %s is not supported", state.Version, path)
+	}
+
+	resources := []stateResource{}
+	for _, resource := range state.Resources {
+		if resource.Mode != "managed" || !strings.HasPrefix(resource.Type, "dbtcloud_") {
+			continue
+		}
+
+		address := fmt.Sprintf("%s.%s", resource.Type, resource.Name)
+		if resource.Module != "" {
+			address = resource.Module + "." + address
+		}
+		for _, instance := range resource.Instances {
+			instanceAddress := address
+			switch key := instance.IndexKey.(type) {
+			case string:
+				instanceAddress += fmt.Sprintf("[%q]", key)
+			case float64:
+				instanceAddress += fmt.Sprintf("[%s]", strconv.FormatFloat(key, 'f', -1, 64))
+			}
+			resources = append(resources, stateResource{
+				address:      instanceAddress,
+				resourceType: resource.Type,
+				attributes:   instance.Attributes,
+			})
+		}
+	}
+	return resources, nil
+}
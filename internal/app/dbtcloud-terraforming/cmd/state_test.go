@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stateTestFile = `{
+  "version": 4,
+  "terraform_version": "1.9.0",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "dbtcloud_project",
+      "name": "analytics",
+      "provider": "provider[\"registry.terraform.io/dbt-labs/dbtcloud\"]",
+      "instances": [
+        {"attributes": {"id": "1", "name": "Analytics"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "dbtcloud_environment",
+      "name": "prod",
+      "instances": [
+        {"index_key": "analytics", "attributes": {"id": "1:10", "project_id": 1, "environment_id": 10}}
+      ]
+    },
+    {
+      "module": "module.marketing",
+      "mode": "managed",
+      "type": "dbtcloud_job",
+      "name": "daily",
+      "instances": [
+        {"index_key": 0, "attributes": {"id": "20", "project_id": 2}}
+      ]
+    },
+    {
+      "mode": "data",
+      "type": "dbtcloud_users",
+      "name": "all",
+      "instances": [
+        {"attributes": {"id": "12345"}}
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "null_resource",
+      "name": "other",
+      "instances": [
+        {"attributes": {"id": "1"}}
+      ]
+    }
+  ]
+}`
+
+func TestState_ReadStateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(stateTestFile), 0644))
+
+	resources, err := readStateFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"dbtcloud_project.analytics",
+		`dbtcloud_environment.prod["analytics"]`,
+		"module.marketing.dbtcloud_job.daily[0]",
+	}, lo.Map(resources, func(resource stateResource, _ int) string { return resource.address }))
+
+	byImportID, byLabel := indexStateResources(resources)
+	assert.Equal(t, map[string]map[string]string{
+		"dbtcloud_project":     {"1": "dbtcloud_project.analytics"},
+		"dbtcloud_environment": {"1:10": `dbtcloud_environment.prod["analytics"]`},
+		"dbtcloud_job":         {"20": "module.marketing.dbtcloud_job.daily[0]"},
+	}, byImportID)
+	assert.Equal(t, map[string]map[string]string{
+		"dbtcloud_project":     {"1": "dbtcloud_project.analytics"},
+		"dbtcloud_environment": {"10": `dbtcloud_environment.prod["analytics"]`},
+		"dbtcloud_job":         {"20": "module.marketing.dbtcloud_job.daily[0]"},
+	}, byLabel)
+}
+
+func TestState_ReadStateFileVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terraform.tfstate")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 3, "modules": []}`), 0644))
+
+	_, err := readStateFile(path)
+	assert.ErrorContains(t, err, "the version 3 of the state format")
+}
+
+// TestState_SkipManagedItems checks that the items in the state are skipped
+// and that the references to them use their address in the state.
+func TestState_SkipManagedItems(t *testing.T) {
+	defer func() {
+		managedResources, managedLabels = nil, nil
+	}()
+	managedResources = map[string]map[string]string{
+		"dbtcloud_environment": {"1:10": "dbtcloud_environment.prod"},
+	}
+	managedLabels = map[string]map[string]string{
+		"dbtcloud_environment": {"10": "dbtcloud_environment.prod"},
+	}
+
+	environments := resourceHandlers["dbtcloud_environment"]
+	items := lo.ToAnySlice([]dbtcloud.Environment{
+		{ID: 10, ProjectID: 1},
+		{ID: 11, ProjectID: 1},
+	})
+
+	assert.Equal(t, items[1:], skipManagedItems(environments, items))
+	assert.Equal(t, items, skipManagedItems(resourceHandlers["dbtcloud_job"], items))
+
+	assert.Equal(t, "dbtcloud_environment.prod.environment_id", resourceReference("dbtcloud_environment", 10, "environment_id").String())
+	assert.Equal(t, "dbtcloud_environment.terraform_managed_resource_11.environment_id", resourceReference("dbtcloud_environment", 11, "environment_id").String())
+}
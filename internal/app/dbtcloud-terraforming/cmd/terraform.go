@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hc-install/product"
+	"github.com/hashicorp/hc-install/releases"
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/spf13/viper"
+)
+
+// newTerraform returns the Terraform or OpenTofu (see --binary-flavor) to run
+// in workingDir, and a function removing the binary if it was downloaded. If
+// --terraform-binary-path is not set, Terraform is downloaded and OpenTofu is
+// looked up in the PATH.
+func newTerraform(ctx context.Context, workingDir string) (*tfexec.Terraform, func(), error) {
+	execPath := viper.GetString("terraform-binary-path")
+	cleanup := func() {}
+
+	if execPath == "" && binaryFlavor == "tofu" {
+		var err error
+		execPath, err = exec.LookPath("tofu")
+		if err != nil {
+			return nil, nil, fmt.Errorf("OpenTofu was not found, install it or set --terraform-binary-path: %w", err)
+		}
+	}
+
+	//Download terraform if no existing binary was provided
+	if execPath == "" {
+		tmpDir, err := os.MkdirTemp("", "tfinstall")
+		if err != nil {
+			return nil, nil, err
+		}
+		cleanup = func() { os.RemoveAll(tmpDir) }
+
+		installConstraints, err := version.NewConstraint("~> 1.0")
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("failed to parse version constraints for installation version")
+		}
+
+		installer := &releases.LatestVersion{
+			Product:     product.Terraform,
+			Constraints: installConstraints,
+			InstallDir:  tmpDir,
+		}
+
+		execPath, err = installer.Install(ctx)
+		if err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("error installing Terraform: %w", err)
+		}
+	}
+
+	tf, err := tfexec.NewTerraform(workingDir, execPath)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	env, err := terraformEnv()
+	if err == nil {
+		err = tf.SetEnv(env)
+	}
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return tf, cleanup, nil
+}
+
+// terraformEnv returns the environment Terraform runs with: the one of the
+// tool, with the plugin cache directory (see --plugin-cache-dir) and the
+// account, token and host URL for the provider to reach dbt Cloud.
+func terraformEnv() (map[string]string, error) {
+	env := map[string]string{}
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		env[key] = value
+	}
+	env = tfexec.CleanEnv(env)
+
+	cacheDir := pluginCacheDir
+	if cacheDir == "" && env["TF_PLUGIN_CACHE_DIR"] == "" {
+		userCacheDir, err := os.UserCacheDir()
+		if err == nil {
+			cacheDir = filepath.Join(userCacheDir, "dbtcloud-terraforming", "plugins")
+		}
+	}
+	if cacheDir != "" {
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, err
+		}
+		env["TF_PLUGIN_CACHE_DIR"] = cacheDir
+	}
+
+	for key, value := range map[string]string{
+		"DBT_CLOUD_ACCOUNT_ID": accountID,
+		"DBT_CLOUD_TOKEN":      apiToken,
+		"DBT_CLOUD_HOST_URL":   hostURL,
+	} {
+		if value != "" {
+			env[key] = value
+		}
+	}
+	return env, nil
+}
+
+// initWorkingDir runs init in the working directory of tf. The providers are
+// installed from --provider-mirror if set, and kept in --plugin-cache-dir to
+// be reused by the next runs.
+func initWorkingDir(ctx context.Context, tf *tfexec.Terraform) error {
+	initOptions := []tfexec.InitOption{}
+	if providerMirror != "" {
+		initOptions = append(initOptions, tfexec.PluginDir(providerMirror))
+	}
+
+	if err := tf.Init(ctx, initOptions...); err != nil {
+		return fmt.Errorf("failed to install the dbt Cloud provider: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var outputFormat string
+
+// terraformJSONRawAttributes are the attributes whose values are written as
+// they are in the Terraform JSON syntax instead of as interpolations, e.g.
+// "to": "dbtcloud_job.terraform_managed_resource_123", by block type.
+var terraformJSONRawAttributes = map[string][]string{
+	"resource":  {"depends_on"},
+	"import":    {"to"},
+	"variable":  {"type"},
+	"lifecycle": {"ignore_changes"},
+}
+
+// formatConfig returns the generated config for --target and in the --format
+// syntax: as it is for hcl, converted by terraformJSONFiles for tf-json and
+// by pulumiFiles for the pulumi-yaml target.
+func formatConfig(files *outputFiles) (*outputFiles, error) {
+	if target == "pulumi-yaml" {
+		return pulumiFiles(files, generatedProviderSchema)
+	}
+	if outputFormat != "tf-json" {
+		return files, nil
+	}
+	return terraformJSONFiles(files)
+}
+
+// terraformJSONFiles returns the HCL files converted to the Terraform JSON
+// syntax, e.g. job.tf to job.tf.json and terraform.tfvars.example to
+// terraform.tfvars.json.example. The single file of the output is converted
+// as a whole and the other files, e.g. imports.sh, are kept as they are. The
+// comments are lost in the conversion.
+func terraformJSONFiles(files *outputFiles) (*outputFiles, error) {
+	converted := newOutputFiles()
+	for _, name := range files.names {
+		content := files.contents[name].Bytes()
+		if name != "" && !strings.HasSuffix(name, ".tf") && name != "terraform.tfvars.example" {
+			converted.file(name).Write(content)
+			continue
+		}
+
+		jsonContent, err := hclToTerraformJSON(content, name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case name == "terraform.tfvars.example":
+			name = "terraform.tfvars.json.example"
+		case name != "":
+			name += ".json"
+		}
+		converted.file(name).Write(jsonContent)
+	}
+	return converted, nil
+}
+
+// hclToTerraformJSON converts the HCL config src to the Terraform JSON
+// syntax: the blocks become objects nested by type and labels, and the
+// expressions that are not literals, e.g. the references to other resources,
+// become ${...} interpolations.
+func hclToTerraformJSON(src []byte, filename string) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, filename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to convert %s to JSON: %s", filename, diags.Error())
+	}
+
+	content, err := terraformJSONBody(file.Body.(*hclsyntax.Body), src, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(content); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// terraformJSONBody returns the object of a body of type blockType. The
+// blocks of the same type and labels, e.g. the import blocks, are grouped in
+// an array.
+func terraformJSONBody(body *hclsyntax.Body, src []byte, blockType string) (map[string]any, error) {
+	object := map[string]any{}
+	for name, attribute := range body.Attributes {
+		raw := false
+		for _, rawAttribute := range terraformJSONRawAttributes[blockType] {
+			raw = raw || rawAttribute == name
+		}
+		value, err := terraformJSONExpression(attribute.Expr, src, raw)
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+
+	for _, block := range body.Blocks {
+		content, err := terraformJSONBody(block.Body, src, block.Type)
+		if err != nil {
+			return nil, err
+		}
+
+		parent := object
+		keys := append([]string{block.Type}, block.Labels...)
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := parent[key].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				parent[key] = child
+			}
+			parent = child
+		}
+
+		last := keys[len(keys)-1]
+		switch existing := parent[last].(type) {
+		case nil:
+			parent[last] = content
+		case []any:
+			parent[last] = append(existing, content)
+		default:
+			parent[last] = []any{existing, content}
+		}
+	}
+	return object, nil
+}
+
+// terraformJSONExpression returns the JSON value of an expression: the
+// literals as values, the objects and the lists element by element, and the
+// other expressions as interpolations. With raw, the expressions are written
+// as they are, e.g. the addresses of depends_on.
+func terraformJSONExpression(expr hclsyntax.Expression, src []byte, raw bool) (any, error) {
+	switch expr := expr.(type) {
+	case *hclsyntax.TupleConsExpr:
+		values := []any{}
+		for _, elem := range expr.Exprs {
+			value, err := terraformJSONExpression(elem, src, raw)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case *hclsyntax.ObjectConsExpr:
+		values := map[string]any{}
+		for _, item := range expr.Items {
+			key, diags := item.KeyExpr.Value(nil)
+			if diags.HasErrors() || key.Type() != cty.String || key.IsNull() {
+				return nil, fmt.Errorf("unsupported object key at %s", item.KeyExpr.Range())
+			}
+			value, err := terraformJSONExpression(item.ValueExpr, src, raw)
+			if err != nil {
+				return nil, err
+			}
+			values[key.AsString()] = value
+		}
+		return values, nil
+	}
+
+	source := strings.TrimSpace(string(expr.Range().SliceBytes(src)))
+	if raw {
+		return source, nil
+	}
+	if len(expr.Variables()) == 0 {
+		// the literals, the function calls fail without an evaluation
+		// context
+		if value, diags := expr.Value(nil); !diags.HasErrors() {
+			return literalValue(value, terraformJSONEscaper), nil
+		}
+	}
+	return "${" + source + "}", nil
+}
+
+// terraformJSONEscaper escapes the ${ and %{ sequences of the strings, which
+// are templates in the Terraform JSON syntax.
+var terraformJSONEscaper = strings.NewReplacer("${", "$${", "%{", "%%{")
+
+// literalValue returns the Go value of a literal, to encode it as JSON or
+// YAML, with its strings escaped with escaper.
+func literalValue(value cty.Value, escaper *strings.Replacer) any {
+	if value.IsNull() {
+		return nil
+	}
+
+	switch {
+	case value.Type() == cty.String:
+		return escaper.Replace(value.AsString())
+	case value.Type() == cty.Number:
+		number := value.AsBigFloat()
+		if integer, accuracy := number.Int64(); number.IsInt() && accuracy == big.Exact {
+			return integer
+		}
+		float, _ := number.Float64()
+		return float
+	case value.Type() == cty.Bool:
+		return value.True()
+	case value.Type().IsListType(), value.Type().IsSetType(), value.Type().IsTupleType():
+		values := []any{}
+		for it := value.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			values = append(values, literalValue(elem, escaper))
+		}
+		return values
+	case value.Type().IsMapType(), value.Type().IsObjectType():
+		values := map[string]any{}
+		for it := value.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			values[key.AsString()] = literalValue(elem, escaper)
+		}
+		return values
+	}
+	return nil
+}
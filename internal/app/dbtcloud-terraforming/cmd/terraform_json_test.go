@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerraformJSON_Convert(t *testing.T) {
+	src := `
+terraform {
+  required_providers {
+    dbtcloud = {
+      source = "dbt-labs/dbtcloud"
+    }
+  }
+}
+
+resource "dbtcloud_job" "terraform_managed_resource_1" {
+  environment_id = dbtcloud_environment.terraform_managed_resource_100.environment_id
+  execute_steps  = ["dbt build --vars '{\"a\": \"$${b}\"}'"]
+  name           = "Nightly"
+  num_threads    = 4
+  triggers = {
+    github_webhook = local.deactivate_jobs_pr ? false : true
+    schedule       = true
+  }
+  job_completion_trigger_condition {
+    job_id   = dbtcloud_job.terraform_managed_resource_2.id
+    statuses = ["success"]
+  }
+  depends_on = [dbtcloud_project.terraform_managed_resource_10]
+}
+
+variable "token" {
+  type        = string
+  description = "The token"
+}
+
+import {
+  to = dbtcloud_job.terraform_managed_resource_1
+  id = "1"
+}
+
+import {
+  to = dbtcloud_job.terraform_managed_resource_2
+  id = "2"
+}
+`
+	content, err := hclToTerraformJSON([]byte(src), "main.tf")
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+  "terraform": {"required_providers": {"dbtcloud": {"source": "dbt-labs/dbtcloud"}}},
+  "resource": {
+    "dbtcloud_job": {
+      "terraform_managed_resource_1": {
+        "environment_id": "${dbtcloud_environment.terraform_managed_resource_100.environment_id}",
+        "execute_steps": ["dbt build --vars '{\"a\": \"$${b}\"}'"],
+        "name": "Nightly",
+        "num_threads": 4,
+        "triggers": {
+          "github_webhook": "${local.deactivate_jobs_pr ? false : true}",
+          "schedule": true
+        },
+        "job_completion_trigger_condition": {
+          "job_id": "${dbtcloud_job.terraform_managed_resource_2.id}",
+          "statuses": ["success"]
+        },
+        "depends_on": ["dbtcloud_project.terraform_managed_resource_10"]
+      }
+    }
+  },
+  "variable": {"token": {"type": "string", "description": "The token"}},
+  "import": [
+    {"to": "dbtcloud_job.terraform_managed_resource_1", "id": "1"},
+    {"to": "dbtcloud_job.terraform_managed_resource_2", "id": "2"}
+  ]
+}`, string(content))
+}
+
+func TestTerraformJSON_JSONEncode(t *testing.T) {
+	content, err := hclToTerraformJSON([]byte(`resource "dbtcloud_extended_attributes" "a" {
+  extended_attributes = jsonencode({ type = "snowflake" })
+}
+`), "")
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"extended_attributes": "${jsonencode({ type = \"snowflake\" })}"`)
+}
+
+func TestTerraformJSON_Files(t *testing.T) {
+	defer func() { outputDir = "" }()
+	outputDir = "config"
+
+	files := newOutputFiles()
+	files.file("job.tf").WriteString("resource \"dbtcloud_job\" \"a\" {\n  name = \"a\"\n}\n")
+	files.file("terraform.tfvars.example").WriteString("token = \"\"\n")
+	files.file("imports.sh").WriteString("terraform import dbtcloud_job.a 1\n")
+
+	converted, err := terraformJSONFiles(files)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"job.tf.json", "terraform.tfvars.json.example", "imports.sh"}, converted.names)
+	assert.JSONEq(t, `{"token": ""}`, converted.file("terraform.tfvars.json.example").String())
+	assert.Equal(t, "terraform import dbtcloud_job.a 1\n", converted.file("imports.sh").String())
+}
+
+func TestTerraformJSON_InvalidHCL(t *testing.T) {
+	_, err := hclToTerraformJSON([]byte("terraform import dbtcloud_job.a 1\n"), "")
+	assert.Error(t, err)
+}
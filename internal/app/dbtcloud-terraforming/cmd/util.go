@@ -5,31 +5,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strings"
 
 	"github.com/dbt-labs/dbtcloud-terraforming/dbtcloud"
-	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
 	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/samber/lo"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/zclconf/go-cty/cty"
 )
 
-var hasNumber = regexp.MustCompile("[0-9]+").MatchString
-
-func strShouldQuote(str string) (bool, string) {
-	startsWith := strings.HasPrefix(str, prefixNoQuotes)
-
-	if startsWith {
-		return false, str[len(prefixNoQuotes):]
-	}
-
-	return true, str
-}
-
 func executeCommandC(root *cobra.Command, args ...string) (output string, err error) {
 	buf := new(bytes.Buffer)
 	root.SetOut(buf)
@@ -67,10 +57,107 @@ func testDataFile(filename string) string {
 }
 
 func sharedPreRun(cmd *cobra.Command, args []string) {
+	// the flags of the command are bound when it runs, as the same flag can
+	// be registered on several commands, and set to their value from viper,
+	// e.g. from their environment variable
+	cmd.LocalNonPersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		if err := viper.BindPFlag(flag.Name, flag); err != nil {
+			log.Fatal(err)
+		}
+		if err := flag.Value.Set(viper.GetString(flag.Name)); err != nil {
+			log.Fatalf("invalid value for --%s: %v", flag.Name, err)
+		}
+	})
 
 	accountID = viper.GetString("account")
 	apiToken = viper.GetString("token")
 	hostURL = viper.GetString("host-url")
+	fromSnapshot = viper.GetString("from-snapshot")
+	rateLimit = viper.GetInt("rate-limit")
+	maxRetries = viper.GetInt("max-retries")
+	concurrency = viper.GetInt("concurrency")
+	noCache = viper.GetBool("no-cache")
+	outputDir = viper.GetString("output-dir")
+	providerSchemaFile = viper.GetString("provider-schema-file")
+	providerVersion = viper.GetString("provider-version")
+	pluginCacheDir = viper.GetString("plugin-cache-dir")
+	providerMirror = viper.GetString("provider-mirror")
+
+	if outputFile != "" && outputDir != "" {
+		log.Fatal("--output and --output-dir can't be used together")
+	}
+
+	if !lo.Contains([]string{"resource-type", "project"}, splitBy) {
+		log.Fatalf("--split-by must be either resource-type or project, not %q", splitBy)
+	}
+
+	binaryFlavor = viper.GetString("binary-flavor")
+	if viper.GetBool("tofu") {
+		binaryFlavor = "tofu"
+	}
+	if _, ok := providerSources[binaryFlavor]; !ok {
+		log.Fatalf("--binary-flavor must be either terraform or tofu, not %q", binaryFlavor)
+	}
+	terraformImportCmdPrefix = fmt.Sprintf("%s import", binaryFlavor)
+
+	if !lo.Contains([]string{"id", "name"}, labelStrategy) {
+		log.Fatalf("--label-strategy must be either id or name, not %q", labelStrategy)
+	}
+
+	if !lo.Contains([]string{"hcl", "tf-json"}, outputFormat) {
+		log.Fatalf("--format must be either hcl or tf-json, not %q", outputFormat)
+	}
+	if outputFormat == "tf-json" && !useModernImportBlock && lo.Contains([]string{"import", "genimport"}, cmd.Name()) {
+		log.Fatal("--format tf-json requires --modern-import-block, the terraform import commands can't be written as JSON")
+	}
+
+	if !lo.Contains([]string{"terraform", "pulumi-yaml"}, target) {
+		log.Fatalf("--target must be either terraform or pulumi-yaml, not %q", target)
+	}
+	if target == "pulumi-yaml" {
+		if outputFormat != "hcl" {
+			log.Fatal("--format only applies to the terraform target")
+		}
+		if parameterizeJobs {
+			log.Fatal("--parameterize-jobs can't be used with --target pulumi-yaml, Pulumi YAML has no conditional expressions")
+		}
+		switch cmd.Name() {
+		case "import":
+			log.Fatal("--target pulumi-yaml can't be used with import, the imports are options of the resources generated by genimport")
+		case "genimport":
+			if !useModernImportBlock {
+				log.Fatal("--target pulumi-yaml requires --modern-import-block, the import blocks become options of the resources")
+			}
+		}
+	}
+
+	if converge && !useModernImportBlock {
+		log.Fatal("--converge requires --modern-import-block, the plan imports the resources with import blocks")
+	}
+	if convergeMaxIterations < 1 {
+		log.Fatalf("--converge-max-iterations must be at least 1, not %d", convergeMaxIterations)
+	}
+
+	if stateDir != "" && stateFile != "" {
+		log.Fatal("--state-dir and --state-file can't be used together")
+	}
+	if stateDir != "" || stateFile != "" {
+		if err := loadState(cmd.Context()); err != nil {
+			log.Fatalf("failed to read the Terraform state: %v", err)
+		}
+	}
+	if existingConfigDir != "" {
+		var err error
+		if existingResources, err = readExistingConfig(existingConfigDir); err != nil {
+			log.Fatalf("failed to read the existing config: %v", err)
+		}
+	}
+
+	if fromSnapshot != "" {
+		snapshotPreRun()
+		return
+	}
+
 	if hostURL == "" {
 		hostURL = "https://cloud.getdbt.com/api"
 	}
@@ -89,8 +176,62 @@ func sharedPreRun(cmd *cobra.Command, args []string) {
 
 	if os.Getenv("CI") != "true" {
 
-		dbtCloudClient = dbtcloud.NewDbtCloudHTTPClient(hostURL, apiToken, accountID, nil)
+		transport := dbtcloud.NewRateLimitedTransport(rateLimit, maxRetries)
+		dbtCloudClient = dbtcloud.NewDbtCloudHTTPClient(hostURL, apiToken, accountID, transport)
+		dbtCloudClient.Concurrency = concurrency
+		if noCache {
+			dbtCloudClient.Cache = nil
+		}
+	}
+}
+
+// snapshotPreRun sets up the client to read from the --from-snapshot file.
+// The account and host URL are taken from the snapshot, so no token is needed
+// and the generated config (e.g. the URLs in variable descriptions) is the same
+// as if it had been generated against the live account.
+func snapshotPreRun() {
+	snapshot, err := dbtcloud.LoadSnapshot(fromSnapshot)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if accountID != "" && accountID != snapshot.AccountID {
+		log.Fatalf("the snapshot %s is for the account %s, not %s", fromSnapshot, snapshot.AccountID, accountID)
+	}
+
+	// a snapshot fetched for some projects only doesn't have the data for the others
+	missingProjects, _ := lo.Difference(viper.GetIntSlice("projects"), snapshot.Projects)
+	if len(snapshot.Projects) > 0 && (len(viper.GetIntSlice("projects")) == 0 || len(missingProjects) > 0) {
+		log.Warnf("the snapshot %s was fetched for the projects %v only", fromSnapshot, snapshot.Projects)
 	}
+
+	accountID = snapshot.AccountID
+	hostURL = snapshot.HostURL
+	// commands read those values again from viper
+	viper.Set("account", accountID)
+	viper.Set("host-url", hostURL)
+
+	dbtCloudClient = dbtcloud.NewDbtCloudHTTPClient(hostURL, "", accountID, dbtcloud.NewSnapshotTransport(snapshot))
+	dbtCloudClient.Concurrency = concurrency
+}
+
+// dbtCloudUIURL returns the URL of the dbt Cloud UI, the host URL of the API
+// without its /api suffix.
+func dbtCloudUIURL() string {
+	return dbtCloudClient.HostURL[:len(dbtCloudClient.HostURL)-4]
+}
+
+// deployURL returns the URL of a page of the Deploy section of the account,
+// e.g. deployURL("projects/%d/jobs/%d/settings/", projectID, jobID). They are
+// used in the descriptions of the variables and in the inventory.
+func deployURL(format string, args ...any) string {
+	return fmt.Sprintf("%s/deploy/%s/%s", dbtCloudUIURL(), dbtCloudClient.AccountID, fmt.Sprintf(format, args...))
+}
+
+// settingsURL returns the URL of a page of the settings of the account, e.g.
+// settingsURL("connections/%d/", connectionID).
+func settingsURL(format string, args ...any) string {
+	return fmt.Sprintf("%s/settings/accounts/%s/pages/%s", dbtCloudUIURL(), dbtCloudClient.AccountID, fmt.Sprintf(format, args...))
 }
 
 // sanitiseTerraformResourceName ensures that a Terraform resource name matches
@@ -184,174 +325,33 @@ func processBlocks(schemaBlock *tfjson.SchemaBlock, structData map[string]interf
 }
 
 // writeAttrLine outputs a line of HCL configuration with a configurable depth
-// for known types.
+// for known types. The references and the other expressions are written as
+// hclExpression values, on their own or in lists and maps.
 func writeAttrLine(key string, value interface{}, parentName string, body *hclwrite.Body) {
 	switch values := value.(type) {
-	case []map[string]interface{}:
-		var childCty []cty.Value
-		for _, item := range value.([]map[string]interface{}) {
-			mapCty := make(map[string]cty.Value)
-			for k, v := range item {
-				mapCty[k] = cty.StringVal(v.(string))
-			}
-			childCty = append(childCty, cty.MapVal(mapCty))
-		}
-		body.SetAttributeValue(key, cty.ListVal(childCty))
-	case map[string]interface{}:
-
-		sortedKeys := make([]string, 0, len(values))
-		for k := range values {
-			sortedKeys = append(sortedKeys, k)
-		}
-		sort.Strings(sortedKeys)
-
-		unquotedValues := false
-		hclTokens := []*hclwrite.Token{{Type: hclsyntax.TokenIdent, Bytes: []byte("{")}}
-
-		ctyMap := make(map[string]cty.Value)
-		for _, v := range sortedKeys {
-
-			// Check if values[v] can be safely type asserted to string
-			if strValue, ok := values[v].(string); ok {
-				if shouldQuote, vStr := strShouldQuote(strValue); !shouldQuote {
-					unquotedValues = true
-					if key == "environment_values" {
-						v = `"` + v + `"`
-					}
-					hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("\n")})
-					hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(v + ` = `)})
-					hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(vStr)})
-					continue
-				}
-			}
-
-			if hasNumber(v) {
-				v = fmt.Sprintf("%s", v)
-			}
-			// else {
-			switch val := values[v].(type) {
-			case string:
-				ctyMap[v] = cty.StringVal(val)
-			case float64:
-				ctyMap[v] = cty.NumberFloatVal(val)
-			case bool:
-				ctyMap[v] = cty.BoolVal(val)
-			case int:
-				ctyMap[v] = cty.NumberIntVal(int64(val))
-			}
-		}
-
-		if !unquotedValues {
-			// Set the regular attributes with SetAttributeValue via the cty approach (easy)
-			body.SetAttributeValue(key, cty.ObjectVal(ctyMap))
-
-		} else {
-			// If there are unquoted values we set them via lower level hclwrite.Token API
-			// the annoying thing is we need to also set all the other attributes for a given key
-			// there is no way to mix/match the cty approach and the hclwrite.Token approach
-			for k := range ctyMap {
-				hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("\n")})
-				hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(k + ` = `)})
-
-				switch values[k].(type) {
-				case string:
-					hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(fmt.Sprintf(`"%s"`, values[k].(string)))})
-				case bool:
-					hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(fmt.Sprintf("%t", values[k].(bool)))})
-				case float64:
-					hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(fmt.Sprintf("%0.f", values[k].(float64)))})
-				case int:
-					hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(fmt.Sprintf("%d", values[k].(int)))})
-				default:
-					log.Panicf("got unknown attribute configuration: key %s, value %v, value type %T", key, values[k], values[k])
-				}
-			}
-
-			hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("\n")})
-			hclTokens = append(hclTokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("}")})
-			body.SetAttributeRaw(key, hclTokens)
-		}
-
-	case []interface{}:
-		var stringItems []string
-		var intItems []int
-		var interfaceItems []map[string]interface{}
-
-		for _, item := range value.([]interface{}) {
-			switch item := item.(type) {
-			case string:
-				stringItems = append(stringItems, item)
-			case map[string]interface{}:
-				interfaceItems = append(interfaceItems, item)
-			case float64:
-				intItems = append(intItems, int(item))
-			}
-		}
-		if len(stringItems) > 0 {
-			writeAttrLine(key, stringItems, parentName, body)
-		}
-
-		if len(intItems) > 0 {
-			writeAttrLine(key, intItems, parentName, body)
-		}
-
-		if len(interfaceItems) > 0 {
-			writeAttrLine(key, interfaceItems, parentName, body)
-		}
-	case []int:
-		var vals []cty.Value
-		for _, i := range value.([]int) {
-			vals = append(vals, cty.NumberIntVal(int64(i)))
-		}
-		if len(vals) > 0 {
-			body.SetAttributeValue(key, cty.ListVal(vals))
-		} else {
-			body.SetAttributeValue(key, cty.ListValEmpty(cty.Number))
-		}
-	case []string:
-		var items []string
-		items = append(items, value.([]string)...)
-		if len(items) > 0 {
-			// if the key isn't used to link a resource type, we can use the string values
-			if shouldQuote, _ := strShouldQuote(items[0]); shouldQuote {
-				var vals []cty.Value
-				for _, item := range items {
-					vals = append(vals, cty.StringVal(item))
-				}
-				body.SetAttributeValue(key, cty.ListVal(vals))
-			} else {
-				// otherwise we need to use the raw tokens
-				tokens := []*hclwrite.Token{{Type: hclsyntax.TokenIdent, Bytes: []byte("[\n")}}
-				for _, item := range items {
-					_, itemStr := strShouldQuote(item)
-					tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte(itemStr + ",\n")})
-				}
-				tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenIdent, Bytes: []byte("]")})
-				body.SetAttributeRaw(key, tokens)
-			}
-		}
 	case string:
-		if parentName == "query" && key == "value" && value == "" {
+		if parentName == "query" && key == "value" && values == "" {
 			body.SetAttributeValue(key, cty.StringVal(""))
 		}
-		if shouldQuote, valueStr := strShouldQuote(value.(string)); !shouldQuote {
-
-			tokens := []*hclwrite.Token{
-				{Type: hclsyntax.TokenIdent, Bytes: []byte(valueStr)},
-			}
-			body.SetAttributeRaw(key, tokens)
-
-		} else if value != "" {
-			body.SetAttributeValue(key, cty.StringVal(value.(string)))
+		if values != "" {
+			body.SetAttributeValue(key, cty.StringVal(values))
 		}
-	case int:
-		body.SetAttributeValue(key, cty.NumberIntVal(int64(value.(int))))
-	case float64:
-		body.SetAttributeValue(key, cty.NumberFloatVal(value.(float64)))
-	case bool:
-		body.SetAttributeValue(key, cty.BoolVal(value.(bool)))
+	case []interface{}, []string:
+		if reflect.ValueOf(values).Len() == 0 {
+			return
+		}
+		tokens, _ := valueTokens(values)
+		body.SetAttributeRaw(key, tokens)
+	case []int:
+		tokens, _ := valueTokens(values)
+		body.SetAttributeRaw(key, tokens)
 	default:
-		log.Debugf("got unknown attribute configuration: key %s, value %v, value type %T", key, value, value)
+		tokens, ok := valueTokens(values)
+		if !ok {
+			log.Debugf("got unknown attribute configuration: key %s, value %v, value type %T", key, value, value)
+			return
+		}
+		body.SetAttributeRaw(key, tokens)
 	}
 }
 
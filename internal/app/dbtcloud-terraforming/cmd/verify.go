@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/samber/lo"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Plan the output of genimport and report the resources that would change",
+	Long: `Writes the output of genimport in --output-dir (or a temporary directory), runs a plan and lists the attributes
+that would change for each resource. The command fails unless the plan only imports resources.
+The provider reaches dbt Cloud with the account, token and host URL of the tool.`,
+	Run:    runVerify(),
+	PreRun: sharedPreRun,
+}
+
+// resourceDrift is a resource the plan would change instead of only importing
+// it.
+type resourceDrift struct {
+	address    string
+	action     string
+	attributes []string
+}
+
+func runVerify() func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if err := verify(cmd); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// verify writes the output of genimport, plans it and returns an error
+// listing the resources the plan would change unless it only imports
+// resources.
+func verify(cmd *cobra.Command) error {
+	// the plan needs import blocks to import the resources
+	if !useModernImportBlock {
+		return errors.New("verify plans the import blocks of the resources, it can't be used with --modern-import-block=false")
+	}
+
+	output := genImportConfig(cmd)
+	if output == nil {
+		return nil
+	}
+
+	workingDir := outputDir
+	if workingDir == "" {
+		tmpDir, err := os.MkdirTemp("", "tfverify")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tmpDir)
+		workingDir = tmpDir
+	}
+	if err := output.writeDir(workingDir); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	plan, err := planWorkingDir(cmd.Context(), workingDir)
+	if err != nil {
+		return err
+	}
+
+	drifts := planDrift(plan)
+	if len(drifts) > 0 {
+		printDrift(cmd.OutOrStdout(), drifts)
+		return fmt.Errorf("the plan is not import-only: %d resources would change", len(drifts))
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "the plan only imports resources, %d to import\n", countImports(plan))
+	return nil
+}
+
+// planWorkingDir initializes workingDir and returns its plan.
+func planWorkingDir(ctx context.Context, workingDir string) (*tfjson.Plan, error) {
+	tf, cleanup, err := newTerraform(ctx, workingDir)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	log.Debugf("initializing Terraform in %s", workingDir)
+	if err := initWorkingDir(ctx, tf); err != nil {
+		return nil, err
+	}
+	return planConfig(ctx, tf, workingDir)
+}
+
+// planConfig returns the plan of the config in the initialized workingDir.
+// Without a terraform.tfvars file, the variables of the fields we couldn't
+// retrieve are set to empty values.
+func planConfig(ctx context.Context, tf *tfexec.Terraform, workingDir string) (*tfjson.Plan, error) {
+	planFile := filepath.Join(workingDir, "dbtcloud-terraforming.tfplan")
+	defer os.Remove(planFile)
+
+	options := []tfexec.PlanOption{tfexec.Out(planFile)}
+	if _, err := os.Stat(filepath.Join(workingDir, "terraform.tfvars")); os.IsNotExist(err) {
+		for _, tfVar := range AllTFVars {
+			options = append(options, tfexec.Var(tfVar.varName+"="))
+		}
+	}
+
+	log.Debug("planning the generated config")
+	if _, err := tf.Plan(ctx, options...); err != nil {
+		return nil, fmt.Errorf("failed to plan the generated config: %w", err)
+	}
+
+	plan, err := tf.ShowPlanFile(ctx, planFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the plan: %w", err)
+	}
+	return plan, nil
+}
+
+// planDrift returns the resources of the plan that would be created, updated
+// or deleted, instead of only being imported, sorted by address.
+func planDrift(plan *tfjson.Plan) []resourceDrift {
+	drifts := []resourceDrift{}
+	for _, rc := range plan.ResourceChanges {
+		if rc.Change == nil || rc.Mode != tfjson.ManagedResourceMode {
+			continue
+		}
+		actions := rc.Change.Actions
+		if actions.NoOp() || actions.Read() {
+			continue
+		}
+
+		drift := resourceDrift{address: rc.Address}
+		switch {
+		case actions.Replace():
+			drift.action = "replace"
+			drift.attributes = changedAttributes(rc.Change)
+		case actions.Update():
+			drift.action = "update"
+			drift.attributes = changedAttributes(rc.Change)
+		case actions.Create():
+			drift.action = "create"
+		case actions.Delete():
+			drift.action = "delete"
+		default:
+			drift.action = strings.Join(lo.Map(actions, func(action tfjson.Action, _ int) string { return string(action) }), ",")
+		}
+		drifts = append(drifts, drift)
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].address < drifts[j].address
+	})
+	return drifts
+}
+
+// changedAttributes returns the top-level attributes whose value would
+// change, sorted.
+func changedAttributes(change *tfjson.Change) []string {
+	before, _ := change.Before.(map[string]any)
+	after, _ := change.After.(map[string]any)
+	afterUnknown, _ := change.AfterUnknown.(map[string]any)
+
+	attributes := []string{}
+	for _, attribute := range lo.Uniq(append(lo.Keys(before), lo.Keys(after)...)) {
+		if unknown, _ := afterUnknown[attribute].(bool); unknown || !reflect.DeepEqual(before[attribute], after[attribute]) {
+			attributes = append(attributes, attribute)
+		}
+	}
+	sort.Strings(attributes)
+	return attributes
+}
+
+// countImports returns the number of resources the plan imports.
+func countImports(plan *tfjson.Plan) int {
+	return lo.CountBy(plan.ResourceChanges, func(rc *tfjson.ResourceChange) bool {
+		return rc.Change != nil && rc.Change.Importing != nil
+	})
+}
+
+// printDrift prints a table of the resources that would change.
+func printDrift(w io.Writer, drifts []resourceDrift) {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "RESOURCE\tACTION\tATTRIBUTES")
+	for _, drift := range drifts {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", drift.address, drift.action, strings.Join(drift.attributes, ", "))
+	}
+	table.Flush()
+}
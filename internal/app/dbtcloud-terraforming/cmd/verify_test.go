@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tfjson "github.com/hashicorp/terraform-json"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const verifyTestPlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "dbtcloud_project.terraform_managed_resource_1",
+      "mode": "managed",
+      "type": "dbtcloud_project",
+      "name": "terraform_managed_resource_1",
+      "change": {
+        "actions": ["no-op"],
+        "before": {"id": 1, "name": "Analytics"},
+        "after": {"id": 1, "name": "Analytics"},
+        "importing": {"id": "1"}
+      }
+    },
+    {
+      "address": "dbtcloud_job.terraform_managed_resource_2",
+      "mode": "managed",
+      "type": "dbtcloud_job",
+      "name": "terraform_managed_resource_2",
+      "change": {
+        "actions": ["update"],
+        "before": {"id": 2, "name": "Daily", "triggers": {"schedule": true}, "job_id": 2},
+        "after": {"id": 2, "name": "Daily run", "triggers": {"schedule": false}},
+        "after_unknown": {"job_id": true},
+        "importing": {"id": "2"}
+      }
+    },
+    {
+      "address": "dbtcloud_environment.terraform_managed_resource_3",
+      "mode": "managed",
+      "type": "dbtcloud_environment",
+      "name": "terraform_managed_resource_3",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"name": "Prod"}
+      }
+    },
+    {
+      "address": "data.dbtcloud_users.all",
+      "mode": "data",
+      "type": "dbtcloud_users",
+      "name": "all",
+      "change": {
+        "actions": ["read"]
+      }
+    }
+  ]
+}`
+
+func TestVerify_PlanDrift(t *testing.T) {
+	var plan tfjson.Plan
+	require.NoError(t, json.Unmarshal([]byte(verifyTestPlan), &plan))
+
+	assert.Equal(t, []resourceDrift{
+		{address: "dbtcloud_environment.terraform_managed_resource_3", action: "create"},
+		{address: "dbtcloud_job.terraform_managed_resource_2", action: "update", attributes: []string{"job_id", "name", "triggers"}},
+	}, planDrift(&plan))
+	assert.Equal(t, 2, countImports(&plan))
+}
+
+func TestVerify_PlanDriftImportOnly(t *testing.T) {
+	plan := tfjson.Plan{
+		ResourceChanges: []*tfjson.ResourceChange{
+			{
+				Address: "dbtcloud_project.terraform_managed_resource_1",
+				Mode:    tfjson.ManagedResourceMode,
+				Change: &tfjson.Change{
+					Actions:   tfjson.Actions{tfjson.ActionNoop},
+					Importing: &tfjson.Importing{ID: "1"},
+				},
+			},
+		},
+	}
+
+	assert.Empty(t, planDrift(&plan))
+}
+
+func TestVerify_PrintDrift(t *testing.T) {
+	var out bytes.Buffer
+	printDrift(&out, []resourceDrift{
+		{address: "dbtcloud_job.terraform_managed_resource_2", action: "update", attributes: []string{"name", "triggers"}},
+		{address: "dbtcloud_environment.terraform_managed_resource_3", action: "create"},
+	})
+
+	assert.Equal(t, `RESOURCE                                           ACTION  ATTRIBUTES
+dbtcloud_job.terraform_managed_resource_2          update  name, triggers
+dbtcloud_environment.terraform_managed_resource_3  create  
+`, out.String())
+}
+
+// fakeTerraform writes a script standing in for the Terraform binary: init
+// does nothing, plan checks that the import blocks were written and show
+// prints plan.
+func fakeTerraform(t *testing.T, plan string) string {
+	dir := t.TempDir()
+	planFile := filepath.Join(dir, "plan.json")
+	require.NoError(t, os.WriteFile(planFile, []byte(plan), 0644))
+
+	script := filepath.Join(dir, "terraform")
+	require.NoError(t, os.WriteFile(script, []byte(fmt.Sprintf(`#!/bin/sh
+case "$1" in
+version) echo '{"terraform_version": "1.9.0", "platform": "linux_amd64", "provider_selections": {}, "terraform_outdated": false}' ;;
+init) ;;
+plan) grep -q 'to = dbtcloud_project.terraform_managed_resource_10' imports.tf || exit 1 ;;
+show) cat %q ;;
+*) exit 1 ;;
+esac
+`, planFile)), 0755))
+	return script
+}
+
+// TestVerify_Verify runs verify on a project of the fake API with a fake
+// Terraform binary.
+func TestVerify_Verify(t *testing.T) {
+	fakeAPI(t, map[string]string{
+		"/v2/accounts/9999/projects/": listResponse(`{"id": 10, "name": "Analytics"}`),
+	})
+	defer func(types []string, dir, schemaFile, cacheDir string, modern bool) {
+		resourceTypes = types
+		outputDir = dir
+		providerSchemaFile = schemaFile
+		pluginCacheDir = cacheDir
+		useModernImportBlock = modern
+		viper.Set("terraform-binary-path", "")
+	}(resourceTypes, outputDir, providerSchemaFile, pluginCacheDir, useModernImportBlock)
+
+	resourceTypes = []string{"dbtcloud_project"}
+	outputDir = t.TempDir()
+	pluginCacheDir = t.TempDir()
+	providerSchemaFile = filepath.Join(t.TempDir(), "schema.json")
+	require.NoError(t, os.WriteFile(providerSchemaFile, []byte(schemaTestProvidersSchema), 0644))
+	useModernImportBlock = true
+
+	var out bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetContext(context.Background())
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+
+	viper.Set("terraform-binary-path", fakeTerraform(t, `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "dbtcloud_project.terraform_managed_resource_10",
+      "mode": "managed",
+      "type": "dbtcloud_project",
+      "name": "terraform_managed_resource_10",
+      "change": {"actions": ["no-op"], "before": {"name": "Analytics"}, "after": {"name": "Analytics"}, "importing": {"id": "10"}}
+    }
+  ]
+}`))
+	require.NoError(t, verify(cmd))
+	assert.Equal(t, "the plan only imports resources, 1 to import\n", out.String())
+	assert.FileExists(t, filepath.Join(outputDir, "project.tf"))
+
+	out.Reset()
+	viper.Set("terraform-binary-path", fakeTerraform(t, `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {
+      "address": "dbtcloud_project.terraform_managed_resource_10",
+      "mode": "managed",
+      "type": "dbtcloud_project",
+      "name": "terraform_managed_resource_10",
+      "change": {"actions": ["update"], "before": {"name": "Analytics"}, "after": {"name": "analytics"}, "importing": {"id": "10"}}
+    }
+  ]
+}`))
+	assert.EqualError(t, verify(cmd), "the plan is not import-only: 1 resources would change")
+	assert.Contains(t, out.String(), "dbtcloud_project.terraform_managed_resource_10  update  name")
+
+	// the commands of --modern-import-block=false can't be planned
+	useModernImportBlock = false
+	assert.ErrorContains(t, verify(cmd), "--modern-import-block=false")
+}
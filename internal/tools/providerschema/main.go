@@ -0,0 +1,93 @@
+// providerschema writes the schema of the dbt Cloud Terraform provider
+// embedded in dbtcloud-terraforming, for generate to work without a
+// Terraform binary. It is run by go generate.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/hc-install/product"
+	"github.com/hashicorp/hc-install/releases"
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+const providerSource = "registry.terraform.io/dbt-labs/dbtcloud"
+
+func main() {
+	providerVersion := flag.String("provider-version", "~> 1.0", "Version constraint of the dbt Cloud provider")
+	outputDir := flag.String("output-dir", "schemas", "Directory to write dbtcloud.json and dbtcloud.version to")
+	flag.Parse()
+
+	if err := run(context.Background(), *providerVersion, *outputDir); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, providerVersion, outputDir string) error {
+	installer := &releases.LatestVersion{
+		Product:     product.Terraform,
+		Constraints: version.MustConstraints(version.NewConstraint("~> 1.0")),
+	}
+	execPath, err := installer.Install(ctx)
+	if err != nil {
+		return fmt.Errorf("error installing Terraform: %w", err)
+	}
+	defer installer.Remove(ctx)
+
+	workingDir, err := os.MkdirTemp("", "providerschema")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workingDir)
+
+	config := fmt.Sprintf(`terraform {
+  required_providers {
+    dbtcloud = {
+      source  = "dbt-labs/dbtcloud"
+      version = %q
+    }
+  }
+}
+`, providerVersion)
+	if err := os.WriteFile(filepath.Join(workingDir, "main.tf"), []byte(config), 0644); err != nil {
+		return err
+	}
+
+	tf, err := tfexec.NewTerraform(workingDir, execPath)
+	if err != nil {
+		return err
+	}
+	if err := tf.Init(ctx); err != nil {
+		return fmt.Errorf("failed to install the provider: %w", err)
+	}
+
+	schemas, err := tf.ProvidersSchema(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read the provider schema: %w", err)
+	}
+	schemaJSON, err := json.Marshal(schemas)
+	if err != nil {
+		return err
+	}
+
+	_, providerVersions, err := tf.Version(ctx, true)
+	if err != nil {
+		return err
+	}
+	installedVersion, ok := providerVersions[providerSource]
+	if !ok {
+		return fmt.Errorf("%s was not installed", providerSource)
+	}
+
+	if err := os.WriteFile(filepath.Join(outputDir, "dbtcloud.json"), schemaJSON, 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(outputDir, "dbtcloud.version"), []byte(installedVersion.String()+"\n"), 0644)
+}